@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daylight55/yg/internal/config"
+)
+
+// answerFlag collects --answer values. It accepts the key=value pairs of a string to
+// string flag, and keeps every raw value so that a keyvalue question can take its
+// pairs whole, as in --answer labels=a=1,b=2.
+type answerFlag struct {
+	values []string
+}
+
+func (f *answerFlag) String() string {
+	return "[" + strings.Join(f.values, ",") + "]"
+}
+
+func (f *answerFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%s must be formatted as key=value", value)
+	}
+	f.values = append(f.values, value)
+	return nil
+}
+
+func (f *answerFlag) Type() string {
+	return "stringToString"
+}
+
+// answers returns the answer of each key. Values of keyvalue questions are kept whole;
+// others are split on commas into more key=value pairs when they hold more than one =.
+func (f *answerFlag) answers(questions map[string]config.Question) map[string]string {
+	result := make(map[string]string)
+	for _, value := range f.values {
+		key, answer, _ := strings.Cut(value, "=")
+		if question, exists := questions[key]; exists && question.IsKeyValue() {
+			result[key] = answer
+			continue
+		}
+		if strings.Count(value, "=") == 1 {
+			result[key] = strings.Trim(answer, `"`)
+			continue
+		}
+		for _, pair := range strings.Split(value, ",") {
+			if key, answer, found := strings.Cut(pair, "="); found {
+				result[key] = answer
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/daylight55/yg/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var checkConfigCmd = &cobra.Command{
+	Use:   "check-config",
+	Short: "Validate the config without loading templates",
+	Long: `Load the config and check its semantics (order and definitions, template_question,
+question dependencies and profiles) without requiring any template files.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.LoadConfigWithOptions(configPath, loadOptions())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid config:\n%w", err)
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), "Config is valid.")
+		return nil
+	},
+}
+
+func init() {
+	checkConfigCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file (default: ./.yg/config.yaml, ./.yg/config.yml or ./.yg/config.json)")
+	checkConfigCmd.Flags().BoolVar(&lax, "lax", false, "Ignore unknown keys in the config file")
+	rootCmd.AddCommand(checkConfigCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/daylight55/yg/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the config",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective config as YAML",
+	Long: `Load the config and print it as yg acts on it: legacy question maps are moved
+under definitions, the generated question order is filled in and data file paths are resolved.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.LoadConfigWithOptions(configPath, loadOptions())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		encoder := yaml.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent(2)
+		if err := encoder.Encode(cfg); err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		return encoder.Close()
+	},
+}
+
+func init() {
+	configShowCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file (default: ./.yg/config.yaml, ./.yg/config.yml or ./.yg/config.json)")
+	configShowCmd.Flags().BoolVar(&lax, "lax", false, "Ignore unknown keys in the config file")
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
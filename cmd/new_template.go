@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daylight55/yg/internal/config"
+	"github.com/daylight55/yg/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var newTemplateType string
+
+var newTemplateCmd = &cobra.Command{
+	Use:   "new-template <name>",
+	Short: "Scaffold a new template and register it in the config",
+	Long: `Create a file or directory template under .yg/_templates with placeholders for the
+configured questions, and add it to the templates section of the YAML config file.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		cfg, err := config.LoadConfigWithOptions("", loadOptions())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Register in the config file that was loaded, which must be YAML to keep its comments
+		configFile := cfg.Path
+		if strings.EqualFold(filepath.Ext(configFile), ".json") {
+			return fmt.Errorf("cannot register templates in %s: only YAML config files are supported", configFile)
+		}
+		if _, registered := cfg.Templates[name]; registered && !force {
+			return fmt.Errorf("%w: %s (use --force to replace it)", config.ErrTemplateRegistered, name)
+		}
+
+		var questions []string
+		for _, key := range cfg.Questions.GetOrder() {
+			if key != cfg.Questions.GetTemplateQuestion() {
+				questions = append(questions, key)
+			}
+		}
+
+		templateType := template.Type(newTemplateType)
+		templatesDir := filepath.Join(cfg.Root, ".yg", "_templates")
+		created := name
+		if templateType == template.TypeFile {
+			created += ".yaml"
+		}
+		_, statErr := os.Stat(filepath.Join(templatesDir, created))
+
+		path, err := template.Scaffold(cfg.Root, name, templateType, questions, force)
+		if err != nil {
+			return err
+		}
+
+		entry := config.TemplateConfig{Type: string(templateType), Path: path}
+		if err := config.RegisterTemplate(configFile, name, entry, force); err != nil {
+			// Do not leave a new template behind that the config does not know about
+			if errors.Is(statErr, fs.ErrNotExist) {
+				err = errors.Join(err, os.RemoveAll(filepath.Join(templatesDir, path)))
+			}
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Created %s template %s and registered it in %s\n",
+			templateType, filepath.Join(templatesDir, path), configFile)
+		return nil
+	},
+}
+
+func init() {
+	newTemplateCmd.Flags().StringVar(&newTemplateType, "type", string(template.TypeFile), "Template type: file or directory")
+	newTemplateCmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing template with the same name")
+	newTemplateCmd.Flags().BoolVar(&lax, "lax", false, "Ignore unknown keys in the config file")
+	rootCmd.AddCommand(newTemplateCmd)
+}
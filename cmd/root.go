@@ -1,80 +1,259 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/daylight55/yg/internal/config"
 	"github.com/daylight55/yg/internal/generator"
+	"github.com/daylight55/yg/internal/prompt"
 	"github.com/spf13/cobra"
 )
 
 var (
-	answers    map[string]string
-	skipPrompt bool
-	configPath string
-	noPreview  bool
+	answerFlags  answerFlag
+	skipPrompt   bool
+	configPath   string
+	noPreview    bool
+	noCLIExample bool
+	explain      bool
+	count        bool
+	lax          bool
+	noColor      bool
+	allTemplates bool
+	templates    []string
+	keepGoing    bool
+	confirmDef   bool
+	filters      []string
+	answersFile  string
+	answersFmt   string
+	outputLayout string
+	profile      string
+	maxCombos    int
+	traceTmpl    bool
+	verbose      bool
+	skipGen      bool
+	force        bool
+	strict       bool
+	namespace    bool
+	reportPath   string
+	retryFrom    string
+	noTmplConfig bool
+	dumpAnswers  bool
+	fromAnswers  string
+	promptMiss   bool
+	colorMode    string
+	templateFile string
+	printTree    bool
+	showDiff     bool
+	review       bool
+	listCombos   bool
+	echoAnswers  bool
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "yg",
 	Short: "YAML template generator",
 	Long:  `A CLI tool to generate YAML files from templates based on interactive prompts.`,
-	RunE: func(_ *cobra.Command, _ []string) error {
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		// Load config to get available questions for validation
-		cfg, err := config.LoadConfig(configPath)
+		cfg, err := config.LoadConfigWithOptions(configPath, loadOptions())
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
 		// Convert CLI answers to the format expected by generator
-		generatorAnswers := make(map[string]interface{})
-		questions := cfg.Questions.GetQuestions()
-
-		for questionKey, question := range questions {
-			if answerStr, exists := answers[questionKey]; exists {
-				if question.IsMultiple() {
-					// Split comma-separated values for multi-select questions
-					generatorAnswers[questionKey] = strings.Split(answerStr, ",")
-				} else {
-					generatorAnswers[questionKey] = answerStr
-				}
-			}
+		generatorAnswers, err := buildAnswers(cfg)
+		if err != nil {
+			return err
 		}
 
 		options := &generator.Options{
-			Answers:    generatorAnswers,
-			SkipPrompt: skipPrompt,
-			NoPreview:  noPreview,
+			Answers:             generatorAnswers,
+			SkipPrompt:          skipPrompt,
+			NoPreview:           noPreview,
+			Explain:             explain,
+			Count:               count,
+			NoColor:             noColor,
+			Color:               colorMode,
+			AllTemplates:        allTemplates,
+			Templates:           templates,
+			KeepGoing:           keepGoing,
+			Filters:             filters,
+			OutputLayout:        outputLayout,
+			Profile:             profile,
+			MaxCombinations:     maxCombos,
+			TraceTemplate:       traceTmpl,
+			Verbose:             verbose,
+			SkipGenerated:       skipGen,
+			Force:               force,
+			StrictChoices:       strict,
+			NamespaceByTemplate: namespace,
+			Report:              reportPath,
+			RetryFrom:           retryFrom,
+			NoTemplateConfig:    noTmplConfig,
+			DumpAnswersJSON:     dumpAnswers,
+			FromAnswersOf:       fromAnswers,
+			PromptMissing:       promptMiss,
+			TemplateFile:        templateFile,
+			PrintTree:           printTree,
+			Diff:                showDiff,
+			ListCombinations:    listCombos,
+		}
+		if echoAnswers {
+			options.Echo = os.Stderr
+		}
+		if cmd.Flags().Changed("confirm-default") {
+			options.ConfirmDefault = &confirmDef
+		}
+		if cmd.Flags().Changed("no-preview") {
+			preview := !noPreview
+			options.Preview = &preview
+		}
+		if cmd.Flags().Changed("no-cli-example") {
+			example := !noCLIExample
+			options.CLIExample = &example
+		}
+		if cmd.Flags().Changed("answers-interactive-review") {
+			options.Review = &review
 		}
 		return runGenerator(options)
 	},
 }
 
 func init() {
-	answers = make(map[string]string)
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	})
 
 	// Dynamic flag creation based on config
-	// For now, use StringToString flag to accept arbitrary key-value pairs
-	rootCmd.Flags().StringToStringVar(&answers, "answer", map[string]string{}, "Answers for questions in format key=value")
+	// answerFlag accepts arbitrary key=value pairs, like a string to string flag
+	rootCmd.Flags().Var(&answerFlags, "answer", "Answers for questions in format key=value")
+	rootCmd.Flags().StringVar(&answersFile, "answers-file", "", "Path to a YAML or JSON answers file (- for stdin)")
+	rootCmd.Flags().StringVar(&profile, "profile", "", "Pre-fill answers from a named profile in the config")
+	rootCmd.Flags().StringVar(&answersFmt, "answers-format", "", "Format of the answers file: yaml or json (default: detected from extension)")
 	rootCmd.Flags().BoolVar(&skipPrompt, "yes", false, "Skip prompts and use provided values")
-	rootCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file (default: ./.yg/config.yaml or ./.yg/config.yml)")
-	rootCmd.Flags().BoolVar(&noPreview, "no-preview", false, "Disable output preview")
+	rootCmd.Flags().BoolVar(&promptMiss, "answer-prompt-missing", false, "Prompt only for questions without a provided answer and skip the confirmation")
+	rootCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file (default: ./.yg/config.yaml, ./.yg/config.yml or ./.yg/config.json)")
+	rootCmd.Flags().BoolVar(&noPreview, "no-preview", false, "Disable output preview (--no-preview=false shows it even when disabled in the config)")
+	rootCmd.Flags().BoolVar(&noCLIExample, "no-cli-example", false, "Do not print the equivalent CLI command after interactive generation (overrides config)")
+	rootCmd.Flags().BoolVar(&echoAnswers, "echo-selections", false, "Write each answer given at a prompt to stderr as a plain line, for session logs")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored prompt output")
+	rootCmd.Flags().StringVar(&colorMode, "color", generator.ColorAuto, "Colored prompt output: auto (when stdout is a terminal), always or never")
+	rootCmd.Flags().BoolVar(&lax, "lax", false, "Ignore unknown keys in the config file")
+	rootCmd.Flags().BoolVar(&allTemplates, "all-templates", false, "Generate every template in the templates config section")
+	rootCmd.Flags().StringSliceVar(&templates, "templates", nil, "Generate only the named templates (comma-separated)")
+	rootCmd.Flags().BoolVar(&keepGoing, "keep-going", false, "Continue past per-combination errors and report failures at the end")
+	rootCmd.Flags().BoolVar(&confirmDef, "confirm-default", false, "Default answer of the generation confirmation (overrides config)")
+	rootCmd.Flags().StringArrayVar(&filters, "filter", nil, "Only generate combinations matching key=glob or key~=regex (repeatable)")
+	rootCmd.Flags().BoolVar(&review, "answers-interactive-review", false, "Review the answers and edit any of them before the preview (overrides config)")
+	rootCmd.Flags().BoolVar(&printTree, "print-tree", false, "Print a tree of the directories and files to generate before confirming")
+	rootCmd.Flags().BoolVar(&showDiff, "diff", false, "Print the diff against existing files and a summary of the changes before confirming")
+	rootCmd.Flags().BoolVar(&explain, "explain", false, "Explain the template and combinations chosen without generating")
+	rootCmd.Flags().StringVar(&outputLayout, "output-layout", generator.OutputLayoutNested, "Output layout: nested (rendered paths) or flat (all files in one directory)")
+	rootCmd.Flags().IntVar(&maxCombos, "max-combinations", 0, "Abort when more combinations would be generated (overrides max_combinations config)")
+	rootCmd.Flags().BoolVar(&traceTmpl, "trace-template", false, "Print the template data of each combination to stderr before rendering it")
+	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "Report diagnostics, such as auto-selected answers, to stderr")
+	rootCmd.Flags().BoolVar(&skipGen, "skip-generated", false, "Skip combinations recorded as generated in .yg/.generated.lock")
+	rootCmd.Flags().BoolVar(&force, "force", false, "Generate recorded combinations anyway and update .yg/.generated.lock")
+	rootCmd.Flags().BoolVar(&strict, "strict-choices", false, "Ask search questions again when the answer is not one of the choices")
+	rootCmd.Flags().BoolVar(&namespace, "namespace-by-template", false, "Write each template's output under a directory named after the template")
+	rootCmd.Flags().StringVar(&templateFile, "template-file", "", "Render the single file template at this path instead of the configured templates")
+	rootCmd.Flags().BoolVar(&noTmplConfig, "no-template-config", false, "Load single file templates from .yg/_templates without the templates config section")
+	rootCmd.Flags().BoolVar(&dumpAnswers, "dump-answers-json", false, "Print only the resolved answers to stdout as a JSON object, with other output on stderr")
+	rootCmd.Flags().StringVar(&fromAnswers, "from-answers-of", "", "Experimental: recover answers from an earlier generated directory by matching it against the template output path")
+	rootCmd.Flags().StringVar(&reportPath, "report", "", "Write the outcome and answers of each combination to a JSON report")
+	rootCmd.Flags().StringVar(&retryFrom, "retry-from", "", "Generate only the combinations that failed in a JSON report, with their recorded answers")
+	rootCmd.Flags().BoolVar(&listCombos, "list-combinations", false, "Print the answers of each combination to generate, one per line, without generating")
+	rootCmd.Flags().BoolVar(&count, "count", false, "Report the number of combinations and files without generating")
 }
 
+// Exit codes returned by the yg command.
+const (
+	ExitCodeOK             = 0
+	ExitCodeError          = 1
+	ExitCodeUsage          = 2
+	ExitCodeConfigNotFound = 3
+	ExitCodeInterrupted    = generator.ExitCodeInterrupted
+)
+
+// errUsage marks command-line usage errors such as unknown flags.
+var errUsage = errors.New("usage error")
+
+// Execute runs the root command and exits with a code describing the outcome.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode maps an error returned by the command to its exit code.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitCodeOK
+	case errors.Is(err, generator.ErrCanceled), errors.Is(err, prompt.ErrInterrupted):
+		return ExitCodeInterrupted
+	case errors.Is(err, config.ErrConfigNotFound):
+		return ExitCodeConfigNotFound
+	case errors.Is(err, errUsage), errors.Is(err, generator.ErrInvalidOptions):
+		return ExitCodeUsage
+	default:
+		return ExitCodeError
 	}
 }
 
 func runGenerator(options *generator.Options) error {
-	gen, err := generator.NewWithConfig(configPath)
+	gen, err := generator.NewWithConfigOptions(configPath, loadOptions())
 	if err != nil {
 		return fmt.Errorf("failed to initialize generator: %w", err)
 	}
 
 	return gen.RunWithOptions(options)
 }
+
+func loadOptions() config.LoadOptions {
+	return config.LoadOptions{Lax: lax}
+}
+
+// buildAnswers merges answers from the answers file and --answer flags, with flags taking precedence.
+func buildAnswers(cfg *config.Config) (map[string]interface{}, error) {
+	generatorAnswers := make(map[string]interface{})
+	questions := cfg.Questions.GetQuestions()
+
+	if answersFile != "" {
+		fileAnswers, err := config.LoadAnswersFile(answersFile, answersFmt)
+		if err != nil {
+			return nil, err
+		}
+
+		for questionKey, value := range fileAnswers {
+			question, exists := questions[questionKey]
+			if !exists {
+				continue
+			}
+			if str, ok := value.(string); ok && question.IsMultiple() {
+				// Split comma-separated values for multi-select questions
+				value = strings.Split(str, ",")
+			}
+			generatorAnswers[questionKey] = value
+		}
+	}
+
+	answers := answerFlags.answers(questions)
+	for questionKey, question := range questions {
+		if answerStr, exists := answers[questionKey]; exists {
+			if question.IsMultiple() {
+				// Split comma-separated values for multi-select questions
+				generatorAnswers[questionKey] = strings.Split(answerStr, ",")
+			} else {
+				generatorAnswers[questionKey] = answerStr
+			}
+		}
+	}
+
+	return generatorAnswers, nil
+}
@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/daylight55/yg/internal/config"
+	"github.com/daylight55/yg/internal/generator"
+	"github.com/daylight55/yg/internal/prompt"
+	"github.com/daylight55/yg/internal/template"
+	"gopkg.in/yaml.v3"
 )
 
 func TestInit(t *testing.T) {
@@ -58,3 +68,330 @@ func TestRunGeneratorFunctionExists(t *testing.T) {
 	// We can't easily test the actual generator execution without extensive mocking,
 	// but we verify the structure is correct
 }
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, ExitCodeOK},
+		{"generic error", errors.New("boom"), ExitCodeError},
+		{"usage error", fmt.Errorf("%w: unknown flag: --bogus", errUsage), ExitCodeUsage},
+		{"invalid options", fmt.Errorf("wrapped: %w", generator.ErrInvalidOptions), ExitCodeUsage},
+		{"config not found", fmt.Errorf("failed to load config: %w", config.ErrConfigNotFound), ExitCodeConfigNotFound},
+		{"canceled", fmt.Errorf("wrapped: %w", generator.ErrCanceled), ExitCodeInterrupted},
+		{"prompt interrupted", fmt.Errorf("failed to ask question app: %w", prompt.ErrInterrupted), ExitCodeInterrupted},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := exitCode(tc.err); code != tc.expected {
+				t.Errorf("Expected exit code %d, got %d", tc.expected, code)
+			}
+		})
+	}
+}
+
+func TestExitCodeConfigNotFound(t *testing.T) {
+	_, err := config.LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if code := exitCode(err); code != ExitCodeConfigNotFound {
+		t.Errorf("Expected exit code %d for missing config, got %d (err: %v)", ExitCodeConfigNotFound, code, err)
+	}
+}
+
+func TestExitCodeUnknownFlag(t *testing.T) {
+	rootCmd.SetArgs([]string{"--bogus"})
+	defer rootCmd.SetArgs(nil)
+
+	err := rootCmd.Execute()
+	if code := exitCode(err); code != ExitCodeUsage {
+		t.Errorf("Expected exit code %d for unknown flag, got %d (err: %v)", ExitCodeUsage, code, err)
+	}
+}
+
+func TestCheckConfigWithoutTemplates(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".yg"), 0o755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	testCases := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			config: `questions:
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      choices: ["dev"]`,
+		},
+		{
+			name: "invalid",
+			config: `questions:
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]`,
+			wantErr: "order lists undefined question 'env'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(tempDir, ".yg", "config.yaml"), []byte(tc.config), 0o600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			var out strings.Builder
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs([]string{"check-config"})
+			defer func() {
+				rootCmd.SetOut(nil)
+				rootCmd.SetArgs(nil)
+			}()
+
+			err := rootCmd.Execute()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected config to be valid without templates, got %v", err)
+				}
+				if !strings.Contains(out.String(), "Config is valid.") {
+					t.Errorf("Expected success message, got %q", out.String())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigShowPrintsEffectiveConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".yg"), 0o755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	// Legacy question map without order, which is normalized on load
+	configContent := `questions:
+  env:
+    prompt: "Env?"
+    choices: ["dev"]
+  cluster:
+    prompt: "Cluster?"
+    type:
+      dynamic:
+        dependency_questions: ["env"]
+    choices:
+      dev: ["dev-1"]
+data_files:
+  regions: regions.yaml`
+	if err := os.WriteFile(filepath.Join(tempDir, ".yg", "config.yaml"), []byte(configContent), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config", "show"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Failed to show config: %v", err)
+	}
+
+	var shown struct {
+		Questions struct {
+			Order       []string                  `yaml:"order"`
+			Definitions map[string]map[string]any `yaml:"definitions"`
+		} `yaml:"questions"`
+		DataFiles map[string]string `yaml:"data_files"`
+	}
+	if err := yaml.Unmarshal([]byte(out.String()), &shown); err != nil {
+		t.Fatalf("Failed to parse shown config: %v\n%s", err, out.String())
+	}
+
+	if strings.Join(shown.Questions.Order, ",") != "env,cluster" {
+		t.Errorf("Expected generated order env,cluster, got %v", shown.Questions.Order)
+	}
+	if len(shown.Questions.Definitions) != 2 {
+		t.Errorf("Expected legacy questions under definitions, got %v", shown.Questions.Definitions)
+	}
+	if expected := filepath.Join(".yg", "regions.yaml"); shown.DataFiles["regions"] != expected {
+		t.Errorf("Expected data file resolved to %s, got %s", expected, shown.DataFiles["regions"])
+	}
+}
+
+func TestNewTemplateScaffoldsAndRegisters(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".yg"), 0o755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	configContent := `questions:
+  template_question: app
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["web"]
+    env:
+      prompt: "Env?"
+      choices: ["dev"]`
+	if err := os.WriteFile(filepath.Join(tempDir, ".yg", "config.yaml"), []byte(configContent), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	run := func(args ...string) error {
+		var out strings.Builder
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(args)
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetArgs(nil)
+			force = false
+		}()
+		return rootCmd.Execute()
+	}
+
+	if err := run("new-template", "web", "--type", "directory"); err != nil {
+		t.Fatalf("Failed to scaffold template: %v", err)
+	}
+
+	tmpl, err := template.LoadTemplate("web")
+	if err != nil {
+		t.Fatalf("Failed to load scaffolded template: %v", err)
+	}
+	if tmpl.Type != template.TypeDirectory || tmpl.BasePath != "{{.Questions.env}}" {
+		t.Errorf("Expected directory template with a path from env, got %s with base path %q", tmpl.Type, tmpl.BasePath)
+	}
+
+	// Scaffolding the same name again needs --force
+	if err := run("new-template", "web", "--type", "directory"); !errors.Is(err, config.ErrTemplateRegistered) {
+		t.Errorf("Expected ErrTemplateRegistered, got %v", err)
+	}
+	if err := run("new-template", "web", "--type", "file", "--force"); err != nil {
+		t.Fatalf("Failed to replace template with --force: %v", err)
+	}
+	if tmpl, err := template.LoadTemplate("web"); err != nil || tmpl.Type != template.TypeFile {
+		t.Errorf("Expected the replaced file template to load, got %v", err)
+	}
+}
+
+func TestNewTemplateRegistersInLoadedConfig(t *testing.T) {
+	configContent := `questions:
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev"]`
+
+	testCases := map[string]struct {
+		configFile string
+		content    string
+		expectErr  bool
+	}{
+		"yml":  {configFile: "config.yml", content: configContent},
+		"json": {configFile: "config.json", content: `{"questions": {"definitions": {"env": {"prompt": "Env?", "choices": ["dev"]}}}}`, expectErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			configPath := filepath.Join(tempDir, ".yg", tc.configFile)
+			if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+				t.Fatalf("Failed to create config directory: %v", err)
+			}
+			if err := os.WriteFile(configPath, []byte(tc.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			rootCmd.SetOut(&strings.Builder{})
+			rootCmd.SetArgs([]string{"new-template", "web"})
+			defer func() {
+				rootCmd.SetOut(nil)
+				rootCmd.SetArgs(nil)
+			}()
+			err := rootCmd.Execute()
+
+			_, statErr := os.Stat(filepath.Join(".yg", "_templates", "web.yaml"))
+			if tc.expectErr {
+				if err == nil {
+					t.Error("Expected registering in a JSON config to fail")
+				}
+				if !os.IsNotExist(statErr) {
+					t.Errorf("Expected no template to be scaffolded, got %v", statErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Failed to scaffold template: %v", err)
+			}
+			cfg, err := config.LoadConfig("")
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if entry, exists := cfg.Templates["web"]; !exists || entry.Path != "web.yaml" {
+				t.Errorf("Expected web to be registered in %s, got %v", tc.configFile, cfg.Templates)
+			}
+			if statErr != nil {
+				t.Errorf("Expected the template to be scaffolded, got %v", statErr)
+			}
+		})
+	}
+}
+
+func TestAnswerFlagKeepsKeyValuePairs(t *testing.T) {
+	var flag answerFlag
+	for _, value := range []string{"labels=a=1,b=2", "app=x,env=dev", `name="web"`} {
+		if err := flag.Set(value); err != nil {
+			t.Fatalf("Failed to set %s: %v", value, err)
+		}
+	}
+	if err := flag.Set("labels"); err == nil {
+		t.Error("Expected a value without = to be rejected")
+	}
+
+	questions := map[string]config.Question{
+		"labels": {Type: &config.QuestionType{KeyValue: true}},
+		"app":    {},
+	}
+	answers := flag.answers(questions)
+
+	expected := map[string]string{"labels": "a=1,b=2", "app": "x", "env": "dev", "name": "web"}
+	if len(answers) != len(expected) {
+		t.Errorf("Expected answers %v, got %v", expected, answers)
+	}
+	for key, value := range expected {
+		if answers[key] != value {
+			t.Errorf("Expected %s=%s, got %q", key, value, answers[key])
+		}
+	}
+}
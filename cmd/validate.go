@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/daylight55/yg/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the config and templates",
+	Long:  `Load the config and every template, and report questions that are never referenced by any template.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		gen, err := generator.NewWithConfigOptions(configPath, loadOptions())
+		if err != nil {
+			return fmt.Errorf("failed to initialize generator: %w", err)
+		}
+
+		warnings, err := gen.Lint()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "warning: %s\n", warning)
+		}
+		if len(warnings) == 0 {
+			fmt.Fprintln(out, "Config is valid.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	validateCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file (default: ./.yg/config.yaml, ./.yg/config.yml or ./.yg/config.json)")
+	validateCmd.Flags().BoolVar(&lax, "lax", false, "Ignore unknown keys in the config file")
+	rootCmd.AddCommand(validateCmd)
+}
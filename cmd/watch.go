@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/daylight55/yg/internal/config"
+	"github.com/daylight55/yg/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Regenerate files whenever a template changes",
+	Long: `Generate files with the given answers, then watch .yg/_templates and regenerate
+the output on every change, printing a diff of the updated files. Answers are taken from
+--answer, --answers-file and --profile without prompting. Press Ctrl+C to stop.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.LoadConfigWithOptions(configPath, loadOptions())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		generatorAnswers, err := buildAnswers(cfg)
+		if err != nil {
+			return err
+		}
+
+		gen, err := generator.NewWithConfigOptions(configPath, loadOptions())
+		if err != nil {
+			return fmt.Errorf("failed to initialize generator: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		options := &generator.Options{
+			Answers:             generatorAnswers,
+			SkipPrompt:          true,
+			Filters:             filters,
+			OutputLayout:        outputLayout,
+			Profile:             profile,
+			NamespaceByTemplate: namespace,
+		}
+		return gen.Watch(ctx, options, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	watchCmd.Flags().Var(&answerFlags, "answer", "Answers for questions in format key=value")
+	watchCmd.Flags().StringVar(&answersFile, "answers-file", "", "Path to a YAML or JSON answers file")
+	watchCmd.Flags().StringVar(&answersFmt, "answers-format", "", "Format of the answers file: yaml or json (default: detected from extension)")
+	watchCmd.Flags().StringVar(&profile, "profile", "", "Use answers from a named profile in the config")
+	watchCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file (default: ./.yg/config.yaml, ./.yg/config.yml or ./.yg/config.json)")
+	watchCmd.Flags().BoolVar(&lax, "lax", false, "Ignore unknown keys in the config file")
+	watchCmd.Flags().StringArrayVar(&filters, "filter", nil, "Only generate combinations matching key=glob or key~=regex (repeatable)")
+	watchCmd.Flags().StringVar(&outputLayout, "output-layout", generator.OutputLayoutNested, "Output layout: nested (rendered paths) or flat (all files in one directory)")
+	watchCmd.Flags().BoolVar(&namespace, "namespace-by-template", false, "Write each template's output under a directory named after the template")
+	rootCmd.AddCommand(watchCmd)
+}
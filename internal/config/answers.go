@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Answers file formats.
+const (
+	AnswersFormatYAML = "yaml"
+	AnswersFormatJSON = "json"
+)
+
+// DetectAnswersFormat detects the answers file format from the file extension.
+// Files without a known extension (including stdin) are treated as YAML.
+func DetectAnswersFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return AnswersFormatJSON
+	}
+	return AnswersFormatYAML
+}
+
+// LoadAnswersFile loads answers from the file at path, or from stdin if path is "-".
+// If format is empty, it is detected from the file extension.
+func LoadAnswersFile(path, format string) (map[string]interface{}, error) {
+	if format == "" {
+		format = DetectAnswersFormat(path)
+	}
+
+	if path == "-" {
+		answers, err := LoadAnswers(os.Stdin, format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load answers from stdin: %w", err)
+		}
+		return answers, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open answers file %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	answers, err := LoadAnswers(file, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load answers file %s: %w", path, err)
+	}
+	return answers, nil
+}
+
+// LoadAnswers decodes answers in the given format. List values are returned as
+// []string and all other values as string.
+func LoadAnswers(r io.Reader, format string) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers: %w", err)
+	}
+
+	raw := make(map[string]interface{})
+	switch format {
+	case AnswersFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON answers: %w", err)
+		}
+	case AnswersFormatYAML:
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML answers: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported answers format: %s", format)
+	}
+
+	return normalizeAnswers(raw), nil
+}
+
+// normalizeAnswers converts decoded answer values: lists become []string, maps
+// become map[string]string and all other values become string.
+func normalizeAnswers(raw map[string]interface{}) map[string]interface{} {
+	answers := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		if list, ok := value.([]interface{}); ok {
+			values := make([]string, len(list))
+			for i, item := range list {
+				values[i] = fmt.Sprintf("%v", item)
+			}
+			answers[key] = values
+			continue
+		}
+		// Maps answer key-value questions
+		if pairs, ok := value.(map[string]interface{}); ok {
+			values := make(map[string]string, len(pairs))
+			for pairKey, pairValue := range pairs {
+				values[pairKey] = fmt.Sprintf("%v", pairValue)
+			}
+			answers[key] = values
+			continue
+		}
+		answers[key] = fmt.Sprintf("%v", value)
+	}
+	return answers
+}
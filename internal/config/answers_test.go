@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testAnswersYAML = `app: deployment
+env:
+  - dev
+  - staging
+`
+	testAnswersJSON = `{"app": "deployment", "env": ["dev", "staging"]}`
+)
+
+func assertTestAnswers(t *testing.T, answers map[string]interface{}) {
+	t.Helper()
+
+	if answers["app"] != "deployment" {
+		t.Errorf("Expected app 'deployment', got %v", answers["app"])
+	}
+
+	env, ok := answers["env"].([]string)
+	if !ok || len(env) != 2 || env[0] != "dev" || env[1] != "staging" {
+		t.Errorf("Expected env [dev staging], got %#v", answers["env"])
+	}
+}
+
+func TestDetectAnswersFormat(t *testing.T) {
+	testCases := map[string]string{
+		"answers.json": AnswersFormatJSON,
+		"ANSWERS.JSON": AnswersFormatJSON,
+		"answers.yaml": AnswersFormatYAML,
+		"answers.yml":  AnswersFormatYAML,
+		"answers":      AnswersFormatYAML,
+		"-":            AnswersFormatYAML,
+	}
+
+	for path, expected := range testCases {
+		if format := DetectAnswersFormat(path); format != expected {
+			t.Errorf("DetectAnswersFormat(%q): expected %s, got %s", path, expected, format)
+		}
+	}
+}
+
+func TestLoadAnswersFile(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"answers.yaml": testAnswersYAML,
+		"answers.json": testAnswersJSON,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+
+		answers, err := LoadAnswersFile(path, "")
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", name, err)
+		}
+		assertTestAnswers(t, answers)
+	}
+}
+
+func TestLoadAnswersFileStdin(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+
+	originalStdin := os.Stdin
+	defer func() { os.Stdin = originalStdin }()
+	os.Stdin = reader
+
+	if _, err := writer.WriteString(testAnswersJSON); err != nil {
+		t.Fatalf("Failed to write to pipe: %v", err)
+	}
+	_ = writer.Close()
+
+	answers, err := LoadAnswersFile("-", AnswersFormatJSON)
+	if err != nil {
+		t.Fatalf("Failed to load answers from stdin: %v", err)
+	}
+	assertTestAnswers(t, answers)
+}
+
+func TestLoadAnswersStrictJSON(t *testing.T) {
+	// Valid YAML, but not valid JSON
+	_, err := LoadAnswers(strings.NewReader(testAnswersYAML), AnswersFormatJSON)
+	if err == nil {
+		t.Fatal("Expected JSON parse error for YAML input with explicit JSON format")
+	}
+
+	if !strings.Contains(err.Error(), "JSON") {
+		t.Errorf("Expected JSON parse error, got: %v", err)
+	}
+
+	if _, err := LoadAnswers(strings.NewReader(testAnswersJSON), "toml"); err == nil {
+		t.Error("Expected error for unsupported answers format")
+	}
+}
+
+func TestLoadAnswersFileNotFound(t *testing.T) {
+	if _, err := LoadAnswersFile("nonexistent.yaml", ""); err == nil {
+		t.Error("Expected error for missing answers file")
+	}
+}
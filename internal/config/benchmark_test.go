@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchmarkQuestions is the number of question pairs in the large benchmark config.
+const benchmarkQuestions = 100
+
+// writeLargeConfig writes a config with a plain and a dynamic question per pair, each
+// with tens of choices, and returns its path.
+func writeLargeConfig(tb testing.TB) string {
+	tb.Helper()
+
+	var b strings.Builder
+	b.WriteString("questions:\n  order:\n")
+	for i := 0; i < benchmarkQuestions; i++ {
+		fmt.Fprintf(&b, "    - env%d\n    - cluster%d\n", i, i)
+	}
+	b.WriteString("  definitions:\n")
+	for i := 0; i < benchmarkQuestions; i++ {
+		fmt.Fprintf(&b, "    env%d:\n      prompt: \"Environment %d?\"\n      type:\n        multiple: true\n      choices:\n", i, i)
+		for e := 0; e < 10; e++ {
+			fmt.Fprintf(&b, "        - value: env-%d\n          description: \"Environment %d\"\n", e, e)
+		}
+		fmt.Fprintf(&b, "    cluster%d:\n      prompt: \"Cluster %d?\"\n      type:\n        multiple: true\n        dynamic:\n          dependency_questions: [\"env%d\"]\n      choices:\n", i, i, i)
+		for e := 0; e < 10; e++ {
+			fmt.Fprintf(&b, "        env-%d: [", e)
+			for c := 0; c < 5; c++ {
+				if c > 0 {
+					b.WriteString(", ")
+				}
+				fmt.Fprintf(&b, "env-%d-cluster-%d", e, c)
+			}
+			b.WriteString("]\n")
+		}
+	}
+
+	path := filepath.Join(tb.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		tb.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLargeConfigLoads(t *testing.T) {
+	cfg, err := LoadConfig(writeLargeConfig(t))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected the benchmark config to be valid: %v", err)
+	}
+	if count := len(cfg.Questions.GetQuestions()); count != 2*benchmarkQuestions {
+		t.Errorf("Expected %d questions, got %d", 2*benchmarkQuestions, count)
+	}
+}
+
+func BenchmarkLoadConfig(b *testing.B) {
+	path := writeLargeConfig(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadConfig(path); err != nil {
+			b.Fatalf("Failed to load config: %v", err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Clone returns a deep copy of the config, so that changes to the copy, including to
+// its questions, templates, profiles and output settings, leave c unchanged.
+func (c *Config) Clone() *Config {
+	return deepCopy(reflect.ValueOf(c)).Interface().(*Config)
+}
+
+// deepCopy returns a copy of v that shares no maps, slices or pointers with it.
+// Unexported struct fields are copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Elem().Type())
+		copied.Elem().Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				copied.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		if question, ok := copied.Addr().Interface().(*Question); ok {
+			question.copyUnexported()
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// copyUnexported replaces the unexported fields of q with copies of them.
+func (q *Question) copyUnexported() {
+	if q.choiceOrder != nil {
+		choiceOrder := make(map[string][]string, len(q.choiceOrder))
+		for path, keys := range q.choiceOrder {
+			choiceOrder[path] = slices.Clone(keys)
+		}
+		q.choiceOrder = choiceOrder
+	}
+	q.templateNames = slices.Clone(q.templateNames)
+}
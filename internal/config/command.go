@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultChoicesTimeout is how long a choices command may run when the question sets
+// no choices_timeout.
+const DefaultChoicesTimeout = 5 * time.Second
+
+// choicesTimeout returns the configured choices command timeout, or the default.
+func (q *Question) choicesTimeout() (time.Duration, error) {
+	if q.ChoicesTimeout == "" {
+		return DefaultChoicesTimeout, nil
+	}
+	timeout, err := time.ParseDuration(q.ChoicesTimeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid choices_timeout %q (expected a positive duration such as 10s)", q.ChoicesTimeout)
+	}
+	return timeout, nil
+}
+
+// commandChoices runs the choices command with sh and returns each non-empty line of
+// its output as a choice. The command is stopped when it exceeds the timeout.
+func (q *Question) commandChoices() ([]string, error) {
+	timeout, err := q.choicesTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", q.ChoicesCommand)
+	// Do not wait for processes started by the command that still hold its output
+	cmd.WaitDelay = 200 * time.Millisecond
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("choices command %q timed out after %s (raise choices_timeout if it needs longer)", q.ChoicesCommand, timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("choices command %q failed: %w: %s", q.ChoicesCommand, err, message)
+		}
+		return nil, fmt.Errorf("choices command %q failed: %w", q.ChoicesCommand, err)
+	}
+
+	var choices []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			choices = append(choices, line)
+		}
+	}
+	return choices, nil
+}
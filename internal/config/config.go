@@ -2,18 +2,77 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// ErrConfigNotFound is returned when no config file exists at the searched paths.
+var ErrConfigNotFound = errors.New("config file not found")
+
 // Config represents the main configuration structure.
 type Config struct {
-	Questions Questions                 `yaml:"questions"`
-	Templates map[string]TemplateConfig `yaml:"templates,omitempty"`
-	Preview   *PreviewConfig            `yaml:"preview,omitempty"`
+	Questions Questions                         `yaml:"questions"`
+	Templates map[string]TemplateConfig         `yaml:"templates,omitempty"`
+	Preview   *PreviewConfig                    `yaml:"preview,omitempty"`
+	Review    *ReviewConfig                     `yaml:"review,omitempty"`
+	Theme     *ThemeConfig                      `yaml:"theme,omitempty"`
+	Confirm   *ConfirmConfig                    `yaml:"confirm,omitempty"`
+	Profiles  map[string]map[string]interface{} `yaml:"profiles,omitempty"`
+	Output    *OutputConfig                     `yaml:"output,omitempty"`
+	// SkipWhen is a condition template evaluated per combination; combinations
+	// for which it renders "true" produce no output.
+	SkipWhen string `yaml:"skip_when,omitempty"`
+	// MaxCombinations aborts generation when more combinations would be rendered (0 means no limit).
+	MaxCombinations int `yaml:"max_combinations,omitempty"`
+	// ShowCLIExample prints the equivalent yg command after an interactive generation (default true).
+	ShowCLIExample *bool `yaml:"show_cli_example,omitempty"`
+	// DataFiles maps names to YAML or JSON files whose content is available to templates
+	// as .Data.<name>. Relative paths are resolved against the config file's directory on load.
+	DataFiles map[string]string `yaml:"data_files,omitempty"`
+	// Transforms are shell commands each rendered file is piped through, in order, before it is written.
+	Transforms []string `yaml:"transforms,omitempty"`
+	// Validations are rules checked against all answers before generating.
+	Validations []ValidationRule `yaml:"validations,omitempty"`
+
+	// Root is the directory containing the .yg directory templates are loaded from,
+	// relative to the working directory. It is empty for the working directory itself
+	// and for an explicit config path.
+	Root string `yaml:"-"`
+	// Path is the config file the config was loaded from.
+	Path string `yaml:"-"`
+}
+
+// Profile returns the answers preset by the named profile.
+// List values are returned as []string, maps as map[string]string and all other values as string.
+func (c *Config) Profile(name string) (map[string]interface{}, error) {
+	profile, exists := c.Profiles[name]
+	if !exists {
+		names := make([]string, 0, len(c.Profiles))
+		for profileName := range c.Profiles {
+			names = append(names, profileName)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("profile '%s' not found (available: %s)", name, strings.Join(names, ", "))
+	}
+	return normalizeAnswers(profile), nil
+}
+
+// ValidationRule is a condition template that must render "true" for the answers to be accepted.
+type ValidationRule struct {
+	Rule    string `yaml:"rule"`
+	Message string `yaml:"message"` // reported when the rule does not hold
 }
 
 // PreviewConfig represents preview configuration.
@@ -21,33 +80,143 @@ type PreviewConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+// ReviewConfig represents the configuration of the answer review before the confirmation.
+type ReviewConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// ConfirmConfig represents generation confirmation configuration.
+type ConfirmConfig struct {
+	Default   bool   `yaml:"default"`
+	Message   string `yaml:"message,omitempty"`
+	WarnAbove int    `yaml:"warn_above,omitempty"` // warn when more files would be generated
+}
+
+// OutputConfig represents generated file output configuration.
+type OutputConfig struct {
+	// Normalize ends every generated file with exactly one trailing newline.
+	Normalize bool `yaml:"normalize,omitempty"`
+	// TrimTrailingWhitespace removes trailing spaces and tabs from every line.
+	TrimTrailingWhitespace bool `yaml:"trim_trailing_whitespace,omitempty"`
+	// TrimBlocks removes template lines holding only control-flow actions, such as
+	// {{ if }} and {{ end }}, so that they do not leave empty lines behind.
+	TrimBlocks bool `yaml:"trim_blocks,omitempty"`
+	// DisallowEmpty fails generation when a file renders to whitespace-only content.
+	DisallowEmpty bool `yaml:"disallow_empty,omitempty"`
+	// Merge sets how a rendered file is combined with an existing file: overwritten
+	// when empty, or deep-merged into it with MergeDeep.
+	Merge string `yaml:"merge,omitempty"`
+	// Index writes a file listing every generated file after generation.
+	Index *IndexConfig `yaml:"index,omitempty"`
+}
+
+// IndexConfig configures the index file listing the generated files, such as a
+// kustomization.yaml aggregating the generated manifests.
+type IndexConfig struct {
+	Path     string `yaml:"path"`               // file to write, relative to the working directory
+	Template string `yaml:"template,omitempty"` // renders the file from .Files, the sorted paths relative to it
+}
+
+// MergeDeep merges rendered YAML into existing files, preferring rendered values.
+const MergeDeep = "deep"
+
+// ThemeConfig represents prompt appearance configuration.
+type ThemeConfig struct {
+	NoColor       bool   `yaml:"no_color,omitempty"`
+	QuestionIcon  string `yaml:"question_icon,omitempty"`
+	QuestionColor string `yaml:"question_color,omitempty"`
+	SelectIcon    string `yaml:"select_icon,omitempty"`
+	SelectColor   string `yaml:"select_color,omitempty"`
+}
+
 // TemplateConfig represents template configuration.
 type TemplateConfig struct {
-	Type string `yaml:"type"` // "file" or "directory"
-	Path string `yaml:"path"` // path to template file or directory
+	Type       string `yaml:"type"`                  // "file" or "directory"
+	Path       string `yaml:"path"`                  // path to template file or directory, or output path for inline content
+	OutputPath string `yaml:"output_path,omitempty"` // output path template overriding the file template metadata
+	Filename   string `yaml:"filename,omitempty"`    // output filename template for inline content or file templates
+	Content    string `yaml:"content,omitempty"`     // inline template content
 }
 
 // Questions represents the questions configuration with order and definitions.
 type Questions struct {
-	Order            []string            `yaml:"order,omitempty"`
-	TemplateQuestion string              `yaml:"template_question,omitempty"`
-	Definitions      map[string]Question `yaml:"definitions,omitempty"`
+	Order            []string `yaml:"order,omitempty"`
+	TemplateQuestion string   `yaml:"template_question,omitempty"`
+	// TemplateVariant names the question whose answer selects template variants, such
+	// as deployment.prod.yaml over deployment.yaml when the answer is "prod".
+	TemplateVariant string              `yaml:"template_variant,omitempty"`
+	TemplateName    string              `yaml:"template_name,omitempty"`
+	TemplateWhen    []TemplateRule      `yaml:"template_when,omitempty"`
+	Definitions     map[string]Question `yaml:"definitions,omitempty"`
+	// Include lists files (glob patterns relative to the config file) whose question
+	// definitions are merged into Definitions on load.
+	Include []string `yaml:"include,omitempty"`
 	// For backward compatibility, support the old direct map format
 	DirectMap map[string]Question `yaml:",inline"`
 }
 
+// TemplateRule selects Template when the When condition template renders "true".
+type TemplateRule struct {
+	When     string `yaml:"when"`
+	Template string `yaml:"template"`
+}
+
 // Question represents a single question configuration.
 type Question struct {
-	Prompt  string        `yaml:"prompt"`
-	Type    *QuestionType `yaml:"type,omitempty"`
-	Choices interface{}   `yaml:"choices"`
+	Prompt      string        `yaml:"prompt"`
+	Type        *QuestionType `yaml:"type,omitempty"`
+	Choices     interface{}   `yaml:"choices"`
+	ChoiceSort  string        `yaml:"choice_sort,omitempty"`  // "alpha" or "none" (default)
+	Default     []string      `yaml:"default,omitempty"`      // Pre-selected options of multi-select questions
+	DefaultFrom string        `yaml:"default_from,omitempty"` // Question whose answer is the default
+	MinSelect   int           `yaml:"min_select,omitempty"`   // Minimum number of selections of multi-select questions
+	MaxSelect   int           `yaml:"max_select,omitempty"`   // Maximum number of selections, unlimited when 0
+	// ChoicesCommand is a shell command printing one choice per line, used instead of Choices.
+	ChoicesCommand string `yaml:"choices_command,omitempty"`
+	// ChoicesTimeout limits how long ChoicesCommand may run, such as "10s" (DefaultChoicesTimeout when empty).
+	ChoicesTimeout string `yaml:"choices_timeout,omitempty"`
+	// AutoSelectSingle selects the only choice without prompting when the choices resolve to one.
+	AutoSelectSingle bool `yaml:"auto_select_single,omitempty"`
+	// FilterBy keeps the object-form choices whose field matches an earlier answer.
+	FilterBy *FilterBy `yaml:"filter_by,omitempty"`
+	// DefaultFromFile reads the default from a value in a file, such as an earlier output.
+	DefaultFromFile *FileDefault `yaml:"default_from_file,omitempty"`
+
+	// choiceOrder records the authored key order of each map in Choices, keyed by its path
+	choiceOrder map[string][]string
+	// templateNames lists the available templates, offered as choices when Type.Templates is set
+	templateNames []string
 }
 
+// FilterBy selects choices by a field of their object form, such as a region tag,
+// that must equal the answer to Question (or one of its answers).
+type FilterBy struct {
+	Question string `yaml:"question"`
+	Field    string `yaml:"field"`
+}
+
+// Choice sort modes.
+const (
+	ChoiceSortNone  = "none"
+	ChoiceSortAlpha = "alpha"
+)
+
 // QuestionType defines the type of question.
 type QuestionType struct {
 	Dynamic     *DynamicType `yaml:"dynamic,omitempty"`
 	Interactive bool         `yaml:"interactive,omitempty"`
 	Multiple    bool         `yaml:"multiple,omitempty"`
+	Number      *NumberType  `yaml:"number,omitempty"`
+	Secret      bool         `yaml:"secret,omitempty"`
+	Bool        bool         `yaml:"bool,omitempty"`
+	Templates   bool         `yaml:"templates,omitempty"` // offer the available templates as choices
+	KeyValue    bool         `yaml:"keyvalue,omitempty"`  // collect key=value pairs, such as labels
+}
+
+// NumberType defines the accepted range of a numeric question. Unset bounds are open.
+type NumberType struct {
+	Min *int `yaml:"min,omitempty"`
+	Max *int `yaml:"max,omitempty"`
 }
 
 // DynamicType defines dynamic question dependencies.
@@ -60,22 +229,168 @@ func (q *Question) IsMultiple() bool {
 	return q.Type != nil && q.Type.Multiple
 }
 
+// IsSecret returns whether the question asks for a secret that must not be echoed or shown.
+func (q *Question) IsSecret() bool {
+	return q.Type != nil && q.Type.Secret
+}
+
+// IsNumber returns whether the question accepts an integer instead of a choice.
+func (q *Question) IsNumber() bool {
+	return q.Type != nil && q.Type.Number != nil
+}
+
+// IsBool returns whether the question accepts a yes or no answer instead of a choice.
+func (q *Question) IsBool() bool {
+	return q.Type != nil && q.Type.Bool
+}
+
+// ParseBool parses an answer to a boolean question, accepting y, yes, true, on and 1
+// as true and n, no, false, off and 0 as false, in any case.
+func (q *Question) ParseBool(answer interface{}) (bool, error) {
+	switch v := answer.(type) {
+	case bool:
+		return v, nil
+	case int:
+		if v == 0 || v == 1 {
+			return v == 1, nil
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "y", "yes", "true", "on", "1":
+			return true, nil
+		case "n", "no", "false", "off", "0":
+			return false, nil
+		}
+		return false, fmt.Errorf("%q is not a boolean (expected yes or no)", v)
+	}
+	return false, fmt.Errorf("%v is not a boolean (expected yes or no)", answer)
+}
+
+// CheckSelectionCount checks the number of selections of a multi-select question
+// against min_select and max_select.
+func (q *Question) CheckSelectionCount(count int) error {
+	if count < q.MinSelect {
+		return fmt.Errorf("expected at least %d selections, got %d", q.MinSelect, count)
+	}
+	if q.MaxSelect > 0 && count > q.MaxSelect {
+		return fmt.Errorf("expected at most %d selections, got %d", q.MaxSelect, count)
+	}
+	return nil
+}
+
+// ParseNumber parses an answer to a numeric question and checks that it is in range.
+func (q *Question) ParseNumber(answer interface{}) (int, error) {
+	var value int
+	switch v := answer.(type) {
+	case int:
+		value = v
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an integer", v)
+		}
+		value = parsed
+	default:
+		return 0, fmt.Errorf("%v is not an integer", answer)
+	}
+
+	if number := q.Type.Number; number != nil {
+		if number.Min != nil && value < *number.Min {
+			return 0, fmt.Errorf("%d is less than the minimum %d", value, *number.Min)
+		}
+		if number.Max != nil && value > *number.Max {
+			return 0, fmt.Errorf("%d is greater than the maximum %d", value, *number.Max)
+		}
+	}
+	return value, nil
+}
+
+// LoadOptions controls how the configuration file is decoded.
+type LoadOptions struct {
+	// Lax disables strict decoding so that unknown keys are ignored.
+	Lax bool
+}
+
+// RootEnvVar names the environment variable bounding the search for the project root.
+const RootEnvVar = "YG_ROOT"
+
+// defaultConfigPaths returns the config file locations searched in dir, in order.
+func defaultConfigPaths(dir string) []string {
+	return []string{
+		filepath.Join(dir, ".yg", "config.yaml"),
+		filepath.Join(dir, ".yg", "config.yml"),
+		filepath.Join(dir, ".yg", "config.json"),
+		// Keep backward compatibility with old path
+		filepath.Join(dir, ".yg", "_templates", ".yg-config.yaml"),
+	}
+}
+
+// FindRoot returns the nearest directory, starting at start (the working directory
+// when empty) and walking up its parents, that contains a config file in a default
+// location. The search stops at the filesystem root, or at the directory named by
+// YG_ROOT when start is inside it.
+func FindRoot(start string) (string, error) {
+	if start == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		start = cwd
+	}
+
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", start, err)
+	}
+
+	boundary := ""
+	if root := os.Getenv(RootEnvVar); root != "" {
+		if boundary, err = filepath.Abs(root); err != nil {
+			return "", fmt.Errorf("failed to resolve %s %s: %w", RootEnvVar, root, err)
+		}
+	}
+
+	for {
+		for _, path := range defaultConfigPaths(dir) {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return dir, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if dir == boundary || parent == dir {
+			return "", fmt.Errorf("%w: no .yg directory in %s or its parents", ErrConfigNotFound, start)
+		}
+		dir = parent
+	}
+}
+
 // LoadConfig loads the configuration from the specified path or default locations.
-// If configPath is empty, it tries default paths: ./.yg/config.yaml and ./.yg/config.yml
+// If configPath is empty, it tries default paths in order: ./.yg/config.yaml, ./.yg/config.yml and ./.yg/config.json,
+// in the working directory or its nearest parent that has one (see FindRoot).
+// Unknown keys are rejected; use LoadConfigWithOptions to load leniently.
 func LoadConfig(configPath string) (*Config, error) {
+	return LoadConfigWithOptions(configPath, LoadOptions{})
+}
+
+// LoadConfigWithOptions loads the configuration like LoadConfig using the given options.
+func LoadConfigWithOptions(configPath string, options LoadOptions) (*Config, error) {
 	var paths []string
+	var root string
 
 	if configPath != "" {
 		// Use specified config path
 		paths = []string{configPath}
 	} else {
-		// Try default paths in order
-		paths = []string{
-			filepath.Join(".yg", "config.yaml"),
-			filepath.Join(".yg", "config.yml"),
-			// Keep backward compatibility with old path
-			filepath.Join(".yg", "_templates", ".yg-config.yaml"),
+		// Try default paths in order, in the nearest directory that has a config
+		if dir, err := FindRoot(""); err == nil {
+			if cwd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(cwd, dir); err == nil && rel != "." {
+					root = rel
+				}
+			}
 		}
+		paths = defaultConfigPaths(root)
 	}
 
 	var lastErr error
@@ -86,21 +401,171 @@ func LoadConfig(configPath string) (*Config, error) {
 			continue
 		}
 
-		var config Config
-		if err := yaml.Unmarshal(data, &config); err != nil {
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			// Validate JSON syntax first for clearer error messages
+			if err := validateJSON(data); err != nil {
+				return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+			}
+		}
+
+		config, err := decodeConfig(data, options)
+		if err != nil {
 			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
 		}
 
+		if err := config.Questions.includeDefinitions(filepath.Dir(path), options); err != nil {
+			return nil, fmt.Errorf("failed to load config file %s: %w", path, err)
+		}
+
 		// Normalize the config to handle both new and old formats
 		config.Questions.normalize()
 
-		return &config, nil
+		if err := errors.Join(config.Questions.dependencyProblems()...); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+
+		config.Root = root
+		config.Path = path
+		config.resolveDataFiles(filepath.Dir(path))
+		config.resolveTemplateChoices()
+
+		return config, nil
 	}
 
+	if errors.Is(lastErr, fs.ErrNotExist) {
+		lastErr = fmt.Errorf("%w: %w", ErrConfigNotFound, lastErr)
+	}
 	if configPath != "" {
 		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, lastErr)
 	}
-	return nil, fmt.Errorf("no config file found in default locations (./.yg/config.yaml, ./.yg/config.yml): %w", lastErr)
+	return nil, fmt.Errorf(
+		"no config file found in default locations (./.yg/config.yaml, ./.yg/config.yml, ./.yg/config.json): %w",
+		lastErr,
+	)
+}
+
+// validateJSON checks that data is well-formed JSON, reporting the offset of syntax errors.
+// Valid JSON is then decoded like YAML, of which JSON is a subset.
+func validateJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("invalid JSON at offset %d: %w", syntaxErr.Offset, err)
+		}
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// decodeConfig decodes config data, rejecting unknown keys unless options.Lax is set.
+func decodeConfig(data []byte, options LoadOptions) (*Config, error) {
+	var config Config
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(!options.Lax)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		if !options.Lax && strings.Contains(err.Error(), "not found in type") {
+			return nil, fmt.Errorf("%w (use --lax to ignore unknown keys)", err)
+		}
+		return nil, err
+	}
+
+	// Capture the authored order of dynamic choice maps, which Go maps do not preserve
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err == nil {
+		config.Questions.recordChoiceOrder(&root)
+	}
+
+	return &config, nil
+}
+
+// recordChoiceOrder stores the authored key order of each question's choice maps.
+func (q *Questions) recordChoiceOrder(root *yaml.Node) {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return
+	}
+
+	questionsNode := mappingValue(root.Content[0], "questions")
+	if questionsNode == nil {
+		return
+	}
+
+	definitionsNode := mappingValue(questionsNode, "definitions")
+	definitions := q.Definitions
+	if definitionsNode == nil {
+		definitionsNode = questionsNode
+		definitions = q.DirectMap
+	}
+
+	for key, question := range definitions {
+		questionNode := mappingValue(definitionsNode, key)
+		if questionNode == nil {
+			continue
+		}
+		choicesNode := mappingValue(questionNode, "choices")
+		if choicesNode == nil || choicesNode.Kind != yaml.MappingNode {
+			continue
+		}
+
+		question.choiceOrder = make(map[string][]string)
+		collectKeyOrder(choicesNode, nil, question.choiceOrder)
+		definitions[key] = question
+	}
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// collectKeyOrder records the key order of node and of all nested mappings under their paths.
+func collectKeyOrder(node *yaml.Node, path []string, order map[string][]string) {
+	keys := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		keys = append(keys, key)
+		if value := node.Content[i+1]; value.Kind == yaml.MappingNode {
+			collectKeyOrder(value, append(path, key), order)
+		}
+	}
+	order[choicePath(path)] = keys
+}
+
+// choicePath joins the keys leading to a nested choice map.
+func choicePath(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// orderedKeys returns the keys of the choice map at path in authored order.
+// Keys without a recorded order (e.g. configs built in code) follow in sorted order.
+func (q *Question) orderedKeys(path []string, choices map[string]interface{}) []string {
+	keys := make([]string, 0, len(choices))
+	seen := make(map[string]bool, len(choices))
+	for _, key := range q.choiceOrder[choicePath(path)] {
+		if _, exists := choices[key]; exists && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var rest []string
+	for key := range choices {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
 }
 
 // GetQuestions returns the questions map, handling both new and old formats.
@@ -118,12 +583,7 @@ func (q *Questions) GetOrder() []string {
 	}
 
 	// Generate order from available questions
-	questions := q.GetQuestions()
-	order := make([]string, 0, len(questions))
-	for key := range questions {
-		order = append(order, key)
-	}
-	return order
+	return dependencyOrder(q.GetQuestions())
 }
 
 // GetTemplateQuestion returns the question key that provides the template name.
@@ -132,6 +592,12 @@ func (q *Questions) GetTemplateQuestion() string {
 	return q.TemplateQuestion
 }
 
+// GetTemplateName returns the template string used to compose the template name from answers.
+// If not specified, returns empty string and the caller should use the template question.
+func (q *Questions) GetTemplateName() string {
+	return q.TemplateName
+}
+
 // normalize handles backward compatibility by moving direct map to definitions if needed.
 func (q *Questions) normalize() {
 	// If using old format (direct map), convert to new format
@@ -142,35 +608,205 @@ func (q *Questions) normalize() {
 
 	// If using new format but no order specified, generate from keys
 	if q.Definitions != nil && len(q.Order) == 0 {
-		q.Order = make([]string, 0, len(q.Definitions))
-		for key := range q.Definitions {
-			q.Order = append(q.Order, key)
+		q.Order = dependencyOrder(q.Definitions)
+	}
+}
+
+// dependencyOrder returns the question keys sorted alphabetically, except that
+// each question comes after the questions it depends on.
+func dependencyOrder(questions map[string]Question) []string {
+	keys := make([]string, 0, len(questions))
+	for key := range questions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	order := make([]string, 0, len(keys))
+	visited := make(map[string]bool)
+	var visit func(key string)
+	visit = func(key string) {
+		question, exists := questions[key]
+		if !exists || visited[key] {
+			return
+		}
+		visited[key] = true
+		for _, dep := range question.dependencies() {
+			visit(dep)
 		}
+		order = append(order, key)
+	}
+	for _, key := range keys {
+		visit(key)
 	}
+	return order
 }
 
 // GetChoices resolves choices for a question based on dependencies.
+// Duplicate choices are removed and the result is sorted according to ChoiceSort.
 func (q *Question) GetChoices(answers map[string]interface{}) ([]string, error) {
+	choices, err := q.resolveChoices(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	choices = dedupChoices(choices)
+
+	if q.FilterBy != nil {
+		if answer, answered := answers[q.FilterBy.Question]; answered {
+			choices = q.filterByAnswer(choices, answer)
+		}
+	}
+
+	switch q.ChoiceSort {
+	case "", ChoiceSortNone:
+	case ChoiceSortAlpha:
+		sortChoices(choices)
+	default:
+		return nil, fmt.Errorf("invalid choice_sort: %s", q.ChoiceSort)
+	}
+
+	return choices, nil
+}
+
+// GetDefaults returns the options pre-selected for a multi-select question.
+// Every default must be one of the given choices.
+func (q *Question) GetDefaults(choices []string) ([]string, error) {
+	if len(q.Default) == 0 {
+		return nil, nil
+	}
+	if !q.IsMultiple() {
+		return nil, fmt.Errorf("default is only supported for multiple questions")
+	}
+
+	available := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		available[choice] = true
+	}
+	for _, value := range q.Default {
+		if !available[value] {
+			return nil, fmt.Errorf("default %q is not one of the available choices %v", value, choices)
+		}
+	}
+
+	return q.Default, nil
+}
+
+// InheritedDefault returns the default derived from the answer to the DefaultFrom question.
+// Only values that are among the given choices are returned.
+func (q *Question) InheritedDefault(answers map[string]interface{}, choices []string) []string {
+	if q.DefaultFrom == "" {
+		return nil
+	}
+	return FilterChoices(answers[q.DefaultFrom], choices)
+}
+
+// FilterChoices returns the values of a string or []string answer that are among choices.
+// Without choices, as for passthrough questions, all values are kept.
+func FilterChoices(answer interface{}, choices []string) []string {
+	var values []string
+	switch value := answer.(type) {
+	case string:
+		values = []string{value}
+	case []string:
+		values = value
+	}
+
+	if len(choices) == 0 {
+		return values
+	}
+
+	available := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		available[choice] = true
+	}
+
+	var result []string
+	for _, value := range values {
+		if available[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// dedupChoices removes duplicate choices while preserving order.
+func dedupChoices(choices []string) []string {
+	seen := make(map[string]bool, len(choices))
+	result := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		if seen[choice] {
+			continue
+		}
+		seen[choice] = true
+		result = append(result, choice)
+	}
+	return result
+}
+
+// sortChoices sorts choices alphabetically. Hierarchical choices ("parent: child")
+// are ordered by parent first, then by child.
+func sortChoices(choices []string) {
+	sort.SliceStable(choices, func(i, j int) bool {
+		parentI, childI := splitHierarchicalChoice(choices[i])
+		parentJ, childJ := splitHierarchicalChoice(choices[j])
+		if parentI != parentJ {
+			return parentI < parentJ
+		}
+		return childI < childJ
+	})
+}
+
+// splitHierarchicalChoice splits a "parent: child" choice into its parts.
+// Plain choices have an empty parent.
+func splitHierarchicalChoice(choice string) (string, string) {
+	if parts := strings.SplitN(choice, ": ", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", choice
+}
+
+func (q *Question) resolveChoices(answers map[string]interface{}) ([]string, error) {
+	if q.ChoicesCommand != "" {
+		return q.commandChoices()
+	}
+	if q.Type != nil && q.Type.Templates {
+		return slices.Clone(q.templateNames), nil
+	}
+
 	switch choices := q.Choices.(type) {
 	case []interface{}:
 		result := make([]string, len(choices))
 		for i, choice := range choices {
-			result[i] = fmt.Sprintf("%v", choice)
+			result[i] = choiceValue(choice)
 		}
 		return result, nil
 	case map[string]interface{}:
 		return q.resolveDynamicChoices(choices, answers)
+	case nil:
+		// Input and passthrough questions carry a value without choices
+		if q.takesInput() {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("no choices defined")
 	default:
 		return nil, fmt.Errorf("invalid choices type: %T", choices)
 	}
 }
 
+// takesInput reports whether the question takes its value from input or from another
+// answer rather than from choices, so that it needs no choices.
+func (q *Question) takesInput() bool {
+	return q.IsNumber() || q.IsSecret() || q.IsBool() || q.IsKeyValue() ||
+		q.DefaultFrom != "" || q.DefaultFromFile != nil
+}
+
 func (q *Question) resolveDynamicChoices(choices, answers map[string]interface{}) ([]string, error) {
 	if q.Type == nil || q.Type.Dynamic == nil {
 		return nil, fmt.Errorf("dynamic type configuration missing")
 	}
 
 	var current interface{} = choices
+	var path []string
 
 	// Process each dependency question in order
 	for _, dep := range q.Type.Dynamic.DependencyQuestions {
@@ -200,30 +836,36 @@ func (q *Question) resolveDynamicChoices(choices, answers map[string]interface{}
 			// by creating grouped choices that maintain the parent-child relationship
 			groupedChoices := make(map[string][]string)
 
+			currentMap, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected map for dependency lookup, got %T", current)
+			}
+
+			// Answers resolved through the wildcard branch follow the authored parents
+			var wildcardParents []string
 			for _, answerStr := range answerValues {
-				currentMap, ok := current.(map[string]interface{})
-				if !ok {
-					return nil, fmt.Errorf("expected map for dependency lookup, got %T", current)
-				}
-				next, exists := currentMap[answerStr]
+				key, next, exists := lookupChoices(currentMap, answerStr)
 				if !exists {
 					continue // Skip missing choices
 				}
+				if key == DynamicChoiceWildcard && answerStr != DynamicChoiceWildcard {
+					wildcardParents = append(wildcardParents, answerStr)
+				}
 
 				switch nextValue := next.(type) {
 				case []interface{}:
 					// Direct choice list - add with parent prefix
 					for _, choice := range nextValue {
-						choiceStr := fmt.Sprintf("%v", choice)
+						choiceStr := choiceValue(choice)
 						// Group choices by their parent dependency value
 						groupedChoices[answerStr] = append(groupedChoices[answerStr], choiceStr)
 					}
 				case map[string]interface{}:
 					// Nested structure - collect all choices from nested maps
-					for _, subChoices := range nextValue {
-						if choiceList, ok := subChoices.([]interface{}); ok {
+					for _, subKey := range q.orderedKeys(append(path, key), nextValue) {
+						if choiceList, ok := nextValue[subKey].([]interface{}); ok {
 							for _, choice := range choiceList {
-								choiceStr := fmt.Sprintf("%v", choice)
+								choiceStr := choiceValue(choice)
 								groupedChoices[answerStr] = append(groupedChoices[answerStr], choiceStr)
 							}
 						}
@@ -231,10 +873,10 @@ func (q *Question) resolveDynamicChoices(choices, answers map[string]interface{}
 				}
 			}
 
-			// Create formatted choices that show the hierarchy
+			// Create formatted choices that show the hierarchy, in authored parent order
 			var result []string
-			for parent, childChoices := range groupedChoices {
-				for _, choice := range childChoices {
+			for _, parent := range append(q.orderedKeys(path, currentMap), wildcardParents...) {
+				for _, choice := range groupedChoices[parent] {
 					// Format: "parent: choice" to show the relationship
 					formattedChoice := fmt.Sprintf("%s: %s", parent, choice)
 					result = append(result, formattedChoice)
@@ -250,7 +892,7 @@ func (q *Question) resolveDynamicChoices(choices, answers map[string]interface{}
 		if !ok {
 			return nil, fmt.Errorf("expected map for dependency lookup, got %T", current)
 		}
-		next, exists := currentMap[answerStr]
+		key, next, exists := lookupChoices(currentMap, answerStr)
 		if !exists {
 			return nil, fmt.Errorf("no choices found for %s = %s", dep, answerStr)
 		}
@@ -258,12 +900,16 @@ func (q *Question) resolveDynamicChoices(choices, answers map[string]interface{}
 		switch nextValue := next.(type) {
 		case map[string]interface{}:
 			current = nextValue
+			path = append(path, key)
 		case []interface{}:
 			result := make([]string, len(nextValue))
 			for i, choice := range nextValue {
-				result[i] = fmt.Sprintf("%v", choice)
+				result[i] = choiceValue(choice)
 			}
 			return result, nil
+		case nil:
+			// A dependency value without children offers no choices
+			return []string{}, nil
 		default:
 			return nil, fmt.Errorf("invalid next value type: %T", nextValue)
 		}
@@ -274,7 +920,7 @@ func (q *Question) resolveDynamicChoices(choices, answers map[string]interface{}
 	case []interface{}:
 		result := make([]string, len(finalChoices))
 		for i, choice := range finalChoices {
-			result[i] = fmt.Sprintf("%v", choice)
+			result[i] = choiceValue(choice)
 		}
 		return result, nil
 	case map[string]interface{}:
@@ -284,3 +930,101 @@ func (q *Question) resolveDynamicChoices(choices, answers map[string]interface{}
 		return nil, fmt.Errorf("final choices must be an array, got %T", finalChoices)
 	}
 }
+
+// choiceValue returns the value of a choice list entry, given either as a scalar or in
+// the object form with value and description keys.
+func choiceValue(choice interface{}) string {
+	if object, ok := choice.(map[string]interface{}); ok {
+		return fmt.Sprintf("%v", object["value"])
+	}
+	return fmt.Sprintf("%v", choice)
+}
+
+// ChoiceDescriptions returns the descriptions of the choices given in the object form,
+// keyed by choice value.
+func (q *Question) ChoiceDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+	collectDescriptions(q.Choices, descriptions)
+	return descriptions
+}
+
+// collectDescriptions adds the descriptions of the choice lists in choices, including
+// those nested in dynamic choice maps.
+func collectDescriptions(choices interface{}, descriptions map[string]string) {
+	switch value := choices.(type) {
+	case []interface{}:
+		for _, choice := range value {
+			object, ok := choice.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if description, ok := object["description"].(string); ok && description != "" {
+				descriptions[choiceValue(choice)] = description
+			}
+		}
+	case map[string]interface{}:
+		for _, branch := range value {
+			collectDescriptions(branch, descriptions)
+		}
+	}
+}
+
+// filterByAnswer returns the choices whose FilterBy field equals the answer, or one of
+// the answers of a multiple selection. Choices without the field are dropped.
+func (q *Question) filterByAnswer(choices []string, answer interface{}) []string {
+	wanted := make(map[string]bool)
+	switch value := answer.(type) {
+	case []string:
+		for _, v := range value {
+			wanted[v] = true
+		}
+	default:
+		wanted[fmt.Sprintf("%v", value)] = true
+	}
+
+	matching := make(map[string]bool)
+	collectMatching(q.Choices, q.FilterBy.Field, wanted, matching)
+
+	result := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		if matching[choice] {
+			result = append(result, choice)
+		}
+	}
+	return result
+}
+
+// collectMatching adds the values of the object-form choices in choices whose field
+// is one of the wanted values, including those nested in dynamic choice maps.
+func collectMatching(choices interface{}, field string, wanted, matching map[string]bool) {
+	switch value := choices.(type) {
+	case []interface{}:
+		for _, choice := range value {
+			object, ok := choice.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if tag, exists := object[field]; exists && wanted[fmt.Sprintf("%v", tag)] {
+				matching[choiceValue(choice)] = true
+			}
+		}
+	case map[string]interface{}:
+		for _, branch := range value {
+			collectMatching(branch, field, wanted, matching)
+		}
+	}
+}
+
+// DynamicChoiceWildcard is the key of the dynamic choices branch used for answers
+// that match no other key, such as free-form answers.
+const DynamicChoiceWildcard = "*"
+
+// lookupChoices returns the key and branch of the choices map for an answer,
+// falling back to the wildcard branch.
+func lookupChoices(choices map[string]interface{}, answer string) (string, interface{}, bool) {
+	if next, exists := choices[answer]; exists {
+		return answer, next, true
+	}
+	next, exists := choices[DynamicChoiceWildcard]
+	return DynamicChoiceWildcard, next, exists
+}
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -366,6 +369,55 @@ func TestQuestionGetChoicesHierarchicalMultipleSelection(t *testing.T) {
 	}
 }
 
+func TestQuestionGetChoicesWildcardBranch(t *testing.T) {
+	question := Question{
+		Type: &QuestionType{
+			Dynamic: &DynamicType{
+				DependencyQuestions: []string{"region"},
+			},
+		},
+		Choices: map[string]interface{}{
+			"us-east": []interface{}{"use-cluster-1"},
+			"*":       []interface{}{"default-cluster-1", "default-cluster-2"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		region   interface{}
+		expected []string
+	}{
+		{name: "known key", region: "us-east", expected: []string{"use-cluster-1"}},
+		{name: "free-form value", region: "ap-south-7", expected: []string{"default-cluster-1", "default-cluster-2"}},
+		{
+			name:   "multiple values",
+			region: []string{"ap-south-7", "us-east"},
+			expected: []string{
+				"us-east: use-cluster-1",
+				"ap-south-7: default-cluster-1", "ap-south-7: default-cluster-2",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			choices, err := question.GetChoices(map[string]interface{}{"region": tc.region})
+			if err != nil {
+				t.Fatalf("Failed to get choices: %v", err)
+			}
+			if strings.Join(choices, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected choices %v, got %v", tc.expected, choices)
+			}
+		})
+	}
+
+	// Without a wildcard branch, unknown values are still an error
+	delete(question.Choices.(map[string]interface{}), "*")
+	if _, err := question.GetChoices(map[string]interface{}{"region": "ap-south-7"}); err == nil {
+		t.Error("Expected error for unknown region without a wildcard branch")
+	}
+}
+
 func TestLoadConfigWithPreview(t *testing.T) {
 	// Test loading config with preview configuration
 	tempDir := t.TempDir()
@@ -479,3 +531,952 @@ func TestLoadConfigPreviewEnabled(t *testing.T) {
 		t.Error("Expected preview to be enabled")
 	}
 }
+
+func TestLoadConfigStrictUnknownKey(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.yaml")
+	configContent := `questions:
+  order:
+    - app
+  definitons:
+    app:
+      prompt: "What type of template do you want to use?"
+      choices:
+        - deployment
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+
+	_, err := LoadConfig(configFile)
+	if err == nil {
+		t.Fatal("Expected error for misspelled config key in strict mode")
+	}
+
+	if !strings.Contains(err.Error(), "line 5") || !strings.Contains(err.Error(), "--lax") {
+		t.Errorf("Expected error to point at the offending field and mention --lax, got: %v", err)
+	}
+}
+
+func TestLoadConfigStrictUnknownTopLevelKey(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.yaml")
+
+	if err := os.WriteFile(configFile, []byte("questins:\n  order: []\n"), 0600); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+
+	_, err := LoadConfig(configFile)
+	if err == nil {
+		t.Fatal("Expected error for unknown top-level key in strict mode")
+	}
+
+	if !strings.Contains(err.Error(), "questins") {
+		t.Errorf("Expected error to name the unknown key, got: %v", err)
+	}
+
+	cfg, err := LoadConfigWithOptions(configFile, LoadOptions{Lax: true})
+	if err != nil {
+		t.Fatalf("Expected lax mode to ignore unknown keys, got: %v", err)
+	}
+
+	if len(cfg.Questions.GetQuestions()) != 0 {
+		t.Errorf("Expected no questions, got %d", len(cfg.Questions.GetQuestions()))
+	}
+}
+
+func TestQuestionGetChoicesAlphaSort(t *testing.T) {
+	question := Question{
+		Type: &QuestionType{
+			Dynamic: &DynamicType{
+				DependencyQuestions: []string{"env"},
+			},
+		},
+		ChoiceSort: ChoiceSortAlpha,
+		Choices: map[string]interface{}{
+			"stg":   []interface{}{"stg-cluster-2", "stg-cluster-1"},
+			"dev":   []interface{}{"dev-cluster-2", "dev-cluster-1", "dev-cluster-2"},
+			"dev-2": []interface{}{"dev-2-cluster-1"},
+		},
+	}
+
+	answers := map[string]interface{}{
+		"env": []string{"stg", "dev-2", "dev"},
+	}
+
+	expected := []string{
+		"dev: dev-cluster-1", "dev: dev-cluster-2",
+		"dev-2: dev-2-cluster-1",
+		"stg: stg-cluster-1", "stg: stg-cluster-2",
+	}
+
+	// Run several times since dynamic choices are collected from maps
+	for i := 0; i < 5; i++ {
+		choices, err := question.GetChoices(answers)
+		if err != nil {
+			t.Fatalf("Failed to get choices: %v", err)
+		}
+
+		if strings.Join(choices, ",") != strings.Join(expected, ",") {
+			t.Fatalf("Expected sorted choices %v, got %v", expected, choices)
+		}
+	}
+}
+
+func TestQuestionGetChoicesDedup(t *testing.T) {
+	question := Question{
+		Choices: []interface{}{"b", "a", "b", "c", "a"},
+	}
+
+	choices, err := question.GetChoices(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed to get choices: %v", err)
+	}
+
+	if strings.Join(choices, ",") != "b,a,c" {
+		t.Errorf("Expected deduplicated choices in original order, got %v", choices)
+	}
+}
+
+func TestQuestionGetChoicesInvalidSort(t *testing.T) {
+	question := Question{
+		Choices:    []interface{}{"a"},
+		ChoiceSort: "random",
+	}
+
+	if _, err := question.GetChoices(map[string]interface{}{}); err == nil {
+		t.Error("Expected error for invalid choice_sort")
+	}
+}
+
+func TestLoadConfigJSONPath(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".yg")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create temp config directory: %v", err)
+	}
+
+	jsonContent := `{
+  "questions": {
+    "definitions": {
+      "app": {
+        "prompt": "What type of template do you want to use?",
+        "choices": ["deployment", "job"]
+      },
+      "env": {
+        "prompt": "Which environment do you want to target?",
+        "type": {"multiple": true},
+        "choices": ["dev", "staging"]
+      }
+    },
+    "order": ["app", "env"]
+  }
+}`
+
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(jsonContent), 0600); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+
+	yamlFile := filepath.Join(tempDir, "equivalent.yaml")
+	if err := os.WriteFile(yamlFile, []byte(testConfigContent), 0600); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	jsonConfig, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Failed to load JSON config: %v", err)
+	}
+
+	yamlConfig, err := LoadConfig(yamlFile)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+
+	if jsonConfig.Path != filepath.Join(".yg", "config.json") {
+		t.Errorf("Expected the JSON config path to be recorded, got %q", jsonConfig.Path)
+	}
+	yamlConfig.Path = jsonConfig.Path
+	if !reflect.DeepEqual(jsonConfig, yamlConfig) {
+		t.Errorf("Expected JSON config to equal YAML config:\nJSON: %+v\nYAML: %+v", jsonConfig, yamlConfig)
+	}
+}
+
+func TestLoadConfigJSONSyntaxError(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"questions": {"order": ["app",]}}`), 0600); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+
+	_, err := LoadConfig(configFile)
+	if err == nil {
+		t.Fatal("Expected error for invalid JSON config")
+	}
+
+	if !strings.Contains(err.Error(), "invalid JSON at offset") {
+		t.Errorf("Expected JSON syntax error with offset, got: %v", err)
+	}
+}
+
+func TestLoadConfigYAMLTakesPrecedenceOverJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".yg")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create temp config directory: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(simpleAppConfig), 0600); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"questions": {}}`), 0600); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	config, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Questions.GetQuestions()) != 1 {
+		t.Error("Expected config.yaml to take precedence over config.json")
+	}
+}
+
+func TestQuestionGetDefaults(t *testing.T) {
+	question := Question{
+		Type:    &QuestionType{Multiple: true},
+		Choices: []interface{}{"dev", "staging", "production"},
+		Default: []string{"dev", "staging"},
+	}
+
+	defaults, err := question.GetDefaults([]string{"dev", "staging", "production"})
+	if err != nil {
+		t.Fatalf("Failed to get defaults: %v", err)
+	}
+
+	if strings.Join(defaults, ",") != "dev,staging" {
+		t.Errorf("Expected defaults dev,staging, got %v", defaults)
+	}
+}
+
+func TestQuestionGetDefaultsNotInChoices(t *testing.T) {
+	question := Question{
+		Type:    &QuestionType{Multiple: true},
+		Default: []string{"dev", "qa"},
+	}
+
+	_, err := question.GetDefaults([]string{"dev", "staging"})
+	if err == nil || !strings.Contains(err.Error(), `"qa"`) {
+		t.Errorf("Expected error naming the invalid default, got: %v", err)
+	}
+}
+
+func TestQuestionGetDefaultsSingleValue(t *testing.T) {
+	question := Question{Default: []string{"dev"}}
+
+	if _, err := question.GetDefaults([]string{"dev"}); err == nil {
+		t.Error("Expected error for default on a single-value question")
+	}
+}
+
+func TestQuestionGetChoicesPreservesAuthoredOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := `questions:
+  definitions:
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["staging", "dev", "prod"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        multiple: true
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        staging: ["stg-b", "stg-a"]
+        prod: ["prod-1"]
+        dev: ["dev-z", "dev-a"]
+    region:
+      prompt: "Region?"
+      type:
+        dynamic:
+          dependency_questions: ["app"]
+      choices:
+        web:
+          west: ["w-2", "w-1"]
+          east: ["e-1"]
+          central: ["c-1"]`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	questions := config.Questions.GetQuestions()
+
+	// Map iteration order is random, so check repeatedly
+	for i := 0; i < 20; i++ {
+		cluster := questions["cluster"]
+		choices, err := cluster.GetChoices(map[string]interface{}{"env": []string{"dev", "prod", "staging"}})
+		if err != nil {
+			t.Fatalf("Failed to get choices: %v", err)
+		}
+		expected := "staging: stg-b,staging: stg-a,prod: prod-1,dev: dev-z,dev: dev-a"
+		if strings.Join(choices, ",") != expected {
+			t.Fatalf("Expected choices in config file order %s, got %v", expected, choices)
+		}
+
+		region := questions["region"]
+		choices, err = region.GetChoices(map[string]interface{}{"app": []string{"web", "mobile"}})
+		if err != nil {
+			t.Fatalf("Failed to get choices: %v", err)
+		}
+		expected = "web: w-2,web: w-1,web: e-1,web: c-1"
+		if strings.Join(choices, ",") != expected {
+			t.Fatalf("Expected nested choices in config file order %s, got %v", expected, choices)
+		}
+	}
+}
+
+func TestQuestionInheritedDefault(t *testing.T) {
+	question := Question{DefaultFrom: "env"}
+	choices := []string{"dev", "staging", "shared"}
+
+	inherited := question.InheritedDefault(map[string]interface{}{"env": "staging"}, choices)
+	if strings.Join(inherited, ",") != "staging" {
+		t.Errorf("Expected inherited default staging, got %v", inherited)
+	}
+
+	inherited = question.InheritedDefault(map[string]interface{}{"env": []string{"dev", "prod"}}, choices)
+	if strings.Join(inherited, ",") != "dev" {
+		t.Errorf("Expected only available values to be inherited, got %v", inherited)
+	}
+
+	if inherited := question.InheritedDefault(map[string]interface{}{}, choices); len(inherited) != 0 {
+		t.Errorf("Expected no default without a source answer, got %v", inherited)
+	}
+}
+
+func TestQuestionGetChoicesWithoutChoices(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := `questions:
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev"]
+    namespace:
+      prompt: "Namespace"
+      default_from: env`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	namespace := config.Questions.GetQuestions()["namespace"]
+	choices, err := namespace.GetChoices(map[string]interface{}{"env": "dev"})
+	if err != nil {
+		t.Fatalf("Expected no error for a question without choices, got: %v", err)
+	}
+	if len(choices) != 0 {
+		t.Errorf("Expected no choices, got %v", choices)
+	}
+
+	// Without choices, the inherited value is passed through as-is
+	inherited := namespace.InheritedDefault(map[string]interface{}{"env": "dev"}, choices)
+	if strings.Join(inherited, ",") != "dev" {
+		t.Errorf("Expected inherited value dev, got %v", inherited)
+	}
+
+	// A select question still needs choices
+	selection := Question{Prompt: "Region?"}
+	if _, err := selection.GetChoices(nil); err == nil {
+		t.Error("Expected an error for a select question without choices")
+	}
+}
+
+func TestQuestionParseNumber(t *testing.T) {
+	minValue, maxValue := 1, 10
+	question := Question{Type: &QuestionType{Number: &NumberType{Min: &minValue, Max: &maxValue}}}
+
+	if !question.IsNumber() {
+		t.Fatal("Expected question to be numeric")
+	}
+
+	for _, answer := range []interface{}{"1", " 10 ", 5} {
+		if _, err := question.ParseNumber(answer); err != nil {
+			t.Errorf("Expected %v to be accepted, got: %v", answer, err)
+		}
+	}
+
+	for _, answer := range []interface{}{"0", "11", "three", "2.5", []string{"1"}} {
+		if _, err := question.ParseNumber(answer); err == nil {
+			t.Errorf("Expected %v to be rejected", answer)
+		}
+	}
+}
+
+func TestQuestionParseBool(t *testing.T) {
+	question := Question{Type: &QuestionType{Bool: true}}
+
+	if !question.IsBool() {
+		t.Fatal("Expected question to be boolean")
+	}
+
+	testCases := map[interface{}]bool{
+		"y": true, "yes": true, "1": true, "true": true, " YES ": true, "on": true, true: true, 1: true,
+		"n": false, "no": false, "0": false, "false": false, "Off": false, false: false, 0: false,
+	}
+	for answer, expected := range testCases {
+		value, err := question.ParseBool(answer)
+		if err != nil {
+			t.Errorf("Expected %v to be accepted, got: %v", answer, err)
+		} else if value != expected {
+			t.Errorf("Expected %v to be %v, got %v", answer, expected, value)
+		}
+	}
+
+	for _, answer := range []interface{}{"maybe", "", 2, []string{"yes"}} {
+		if _, err := question.ParseBool(answer); err == nil {
+			t.Errorf("Expected %v to be rejected", answer)
+		}
+	}
+}
+
+func TestQuestionParseKeyValue(t *testing.T) {
+	question := Question{Type: &QuestionType{KeyValue: true}}
+
+	if !question.IsKeyValue() {
+		t.Fatal("Expected question to be key-value")
+	}
+
+	expected := map[string]string{"team": "web", "tier": "frontend"}
+	for _, answer := range []interface{}{
+		"team=web,tier=frontend",
+		" team = web , tier=frontend",
+		[]string{"team=web", "tier=frontend"},
+		map[string]interface{}{"team": "web", "tier": "frontend"},
+	} {
+		value, err := question.ParseKeyValue(answer)
+		if err != nil {
+			t.Errorf("Expected %v to be accepted, got: %v", answer, err)
+		} else if !reflect.DeepEqual(value, expected) {
+			t.Errorf("Expected %v to be %v, got %v", answer, expected, value)
+		}
+	}
+
+	if value, err := question.ParseKeyValue(""); err != nil || len(value) != 0 {
+		t.Errorf("Expected an empty answer to have no pairs, got %v, %v", value, err)
+	}
+
+	for _, answer := range []interface{}{"team", "=web", "team=web,", 3} {
+		if _, err := question.ParseKeyValue(answer); err == nil {
+			t.Errorf("Expected %v to be rejected", answer)
+		}
+	}
+
+	if formatted := FormatKeyValue(expected); formatted != "team=web,tier=frontend" {
+		t.Errorf("Expected pairs to be formatted sorted by key, got %q", formatted)
+	}
+}
+
+func TestLoadConfigFromNestedDirectory(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".yg"), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".yg", "config.yaml"), []byte(simpleAppConfig), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	nestedDir := filepath.Join(tempDir, "services", "api", "overlays")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		t.Fatalf("Failed to create nested directory: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(nestedDir)
+
+	root, err := FindRoot("")
+	if err != nil {
+		t.Fatalf("Failed to find root: %v", err)
+	}
+	if root != tempDir {
+		t.Errorf("Expected root %s, got %s", tempDir, root)
+	}
+
+	config, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Failed to load ancestor config: %v", err)
+	}
+	if _, exists := config.Questions.GetQuestions()["app"]; !exists {
+		t.Error("Expected app question from the ancestor config")
+	}
+
+	// YG_ROOT bounds the search below the directory holding the config
+	t.Setenv(RootEnvVar, filepath.Join(tempDir, "services"))
+	if _, err := LoadConfig(""); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("Expected config not found beyond YG_ROOT, got %v", err)
+	}
+}
+
+func TestLoadDataFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".yg")
+	if err := os.MkdirAll(filepath.Join(configDir, "data"), 0755); err != nil {
+		t.Fatalf("Failed to create data directory: %v", err)
+	}
+
+	configContent := simpleAppConfig + `
+data_files:
+  presets: data/presets.yaml
+  ports: ports.json`
+	files := map[string]string{
+		"config.yaml":       configContent,
+		"data/presets.yaml": "small:\n  cpu: 100m\nlarge:\n  cpu: \"2\"\n",
+		"ports.json":        `{"http": 8080}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(configDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// Data files are relative to the config file, not the working directory
+	config, err := LoadConfig(filepath.Join(configDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	data, err := config.LoadDataFiles()
+	if err != nil {
+		t.Fatalf("Failed to load data files: %v", err)
+	}
+
+	presets, ok := data["presets"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected presets to be a map, got %T", data["presets"])
+	}
+	if large, ok := presets["large"].(map[string]interface{}); !ok || large["cpu"] != "2" {
+		t.Errorf("Expected large preset cpu 2, got %v", presets["large"])
+	}
+	if ports, ok := data["ports"].(map[string]interface{}); !ok || ports["http"] != 8080 {
+		t.Errorf("Expected http port 8080, got %v", data["ports"])
+	}
+
+	config.DataFiles["missing"] = filepath.Join(configDir, "missing.yaml")
+	if _, err := config.LoadDataFiles(); err == nil {
+		t.Error("Expected error for a missing data file")
+	}
+}
+
+func TestLoadConfigIncludesQuestions(t *testing.T) {
+	writeConfigFiles := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+		configDir := filepath.Join(t.TempDir(), ".yg")
+		for name, content := range files {
+			path := filepath.Join(configDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("Failed to create directory for %s: %v", name, err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", name, err)
+			}
+		}
+		return filepath.Join(configDir, "config.yaml")
+	}
+
+	mainConfig := `questions:
+  include: ["questions/*.yaml"]
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev", "prod"]`
+
+	t.Run("merge", func(t *testing.T) {
+		path := writeConfigFiles(t, map[string]string{
+			"config.yaml": mainConfig,
+			"questions/apps.yaml": `app:
+  prompt: "App?"
+  choices: ["api", "web"]`,
+			"questions/clusters.yaml": `cluster:
+  prompt: "Cluster?"
+  type:
+    dynamic:
+      dependency_questions: ["env"]
+  choices:
+    prod: ["prod-1"]
+    dev: ["dev-1"]`,
+		})
+
+		config, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+
+		questions := config.Questions.GetQuestions()
+		for _, key := range []string{"env", "app", "cluster"} {
+			if _, exists := questions[key]; !exists {
+				t.Errorf("Expected question %s to be defined", key)
+			}
+		}
+
+		// Included questions take part in the generated order
+		if order := strings.Join(config.Questions.GetOrder(), ","); order != "app,env,cluster" {
+			t.Errorf("Expected order app,env,cluster, got %s", order)
+		}
+
+		cluster := questions["cluster"]
+		choices, err := cluster.GetChoices(map[string]interface{}{"env": []string{"dev", "prod"}})
+		if err != nil {
+			t.Fatalf("Failed to get choices: %v", err)
+		}
+		if strings.Join(choices, ",") != "prod: prod-1,dev: dev-1" {
+			t.Errorf("Expected authored choice order of the included file, got %v", choices)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		path := writeConfigFiles(t, map[string]string{
+			"config.yaml": mainConfig,
+			"questions/apps.yaml": `env:
+  prompt: "Environment?"
+  choices: ["staging"]`,
+		})
+
+		_, err := LoadConfig(path)
+		if err == nil || !strings.Contains(err.Error(), "question 'env' is defined in both the config file and") {
+			t.Errorf("Expected conflict error, got %v", err)
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		path := writeConfigFiles(t, map[string]string{
+			"config.yaml": mainConfig,
+			"questions/apps.yaml": `app:
+  promt: "App?"`,
+		})
+
+		if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "questions/apps.yaml") {
+			t.Errorf("Expected error naming the included file, got %v", err)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		path := writeConfigFiles(t, map[string]string{"config.yaml": mainConfig})
+
+		if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "matches no files") {
+			t.Errorf("Expected error for an include pattern without files, got %v", err)
+		}
+	})
+}
+
+func TestQuestionFilterBy(t *testing.T) {
+	cfg := loadTestConfig(t, `questions:
+  order: ["region", "cluster"]
+  definitions:
+    region:
+      prompt: "Region?"
+      choices: ["eu", "us"]
+    cluster:
+      prompt: "Cluster?"
+      filter_by:
+        question: region
+        field: region
+      choices:
+        - value: eu-1
+          region: eu
+        - value: us-1
+          region: us
+        - value: eu-2
+          region: eu
+        - untagged`)
+
+	cluster := cfg.Questions.GetQuestions()["cluster"]
+	testCases := []struct {
+		name     string
+		answers  map[string]interface{}
+		expected string
+	}{
+		{name: "single answer", answers: map[string]interface{}{"region": "eu"}, expected: "eu-1,eu-2"},
+		{name: "multiple answers", answers: map[string]interface{}{"region": []string{"us", "eu"}}, expected: "eu-1,us-1,eu-2"},
+		{name: "no match", answers: map[string]interface{}{"region": "ap"}, expected: ""},
+		{name: "unanswered", answers: map[string]interface{}{}, expected: "eu-1,us-1,eu-2,untagged"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			choices, err := cluster.GetChoices(tc.answers)
+			if err != nil {
+				t.Fatalf("Failed to get choices: %v", err)
+			}
+			if strings.Join(choices, ",") != tc.expected {
+				t.Errorf("Expected choices %q, got %v", tc.expected, choices)
+			}
+		})
+	}
+}
+
+func TestQuestionChoiceDescriptions(t *testing.T) {
+	cfg := loadTestConfig(t, `questions:
+  order: ["env", "cluster"]
+  definitions:
+    env:
+      prompt: "Env?"
+      choices:
+        - value: dev
+          description: "Shared development environment"
+        - staging
+    cluster:
+      prompt: "Cluster?"
+      type:
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev:
+          - value: dev-1
+            description: "Primary dev cluster"
+          - dev-2`)
+
+	questions := cfg.Questions.GetQuestions()
+	env := questions["env"]
+	choices, err := env.GetChoices(nil)
+	if err != nil {
+		t.Fatalf("Failed to get choices: %v", err)
+	}
+	if strings.Join(choices, ",") != "dev,staging" {
+		t.Errorf("Expected choice values dev,staging, got %v", choices)
+	}
+	if descriptions := env.ChoiceDescriptions(); len(descriptions) != 1 || descriptions["dev"] != "Shared development environment" {
+		t.Errorf("Expected a description for dev only, got %v", descriptions)
+	}
+
+	cluster := questions["cluster"]
+	choices, err = cluster.GetChoices(map[string]interface{}{"env": "dev"})
+	if err != nil {
+		t.Fatalf("Failed to get dynamic choices: %v", err)
+	}
+	if strings.Join(choices, ",") != "dev-1,dev-2" {
+		t.Errorf("Expected choice values dev-1,dev-2, got %v", choices)
+	}
+	if descriptions := cluster.ChoiceDescriptions(); len(descriptions) != 1 || descriptions["dev-1"] != "Primary dev cluster" {
+		t.Errorf("Expected a description for dev-1 only, got %v", descriptions)
+	}
+}
+
+func TestQuestionChoicesCommand(t *testing.T) {
+	question := Question{ChoicesCommand: "printf 'dev\\n\\nprod\\n'"}
+	choices, err := question.GetChoices(nil)
+	if err != nil {
+		t.Fatalf("Failed to get command choices: %v", err)
+	}
+	if strings.Join(choices, ",") != "dev,prod" {
+		t.Errorf("Expected a choice per non-empty line, got %v", choices)
+	}
+
+	failing := Question{ChoicesCommand: "echo 'no credentials' >&2; exit 1"}
+	if _, err := failing.GetChoices(nil); err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("Expected the command error with its stderr, got %v", err)
+	}
+}
+
+func TestQuestionChoicesCommandTimeout(t *testing.T) {
+	question := Question{ChoicesCommand: "sleep 10", ChoicesTimeout: "100ms"}
+
+	start := time.Now()
+	_, err := question.GetChoices(nil)
+	if err == nil || !strings.Contains(err.Error(), `choices command "sleep 10" timed out after 100ms`) {
+		t.Fatalf("Expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the command to be stopped at the timeout, took %s", elapsed)
+	}
+}
+
+func TestQuestionTemplateChoices(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "templates section",
+			files: map[string]string{
+				".yg/config.yaml": `templates:
+  web: {type: file, path: web-app.yaml}
+  api: {type: directory, path: api}
+questions:
+  definitions:
+    app:
+      prompt: "App?"
+      type:
+        templates: true`,
+				".yg/_templates/web-app.yaml": "path: .\nfilename: web.yaml\n---\nweb",
+			},
+			expected: "api,web",
+		},
+		{
+			name: "templates directory",
+			files: map[string]string{
+				".yg/config.yaml": `questions:
+  template_variant: env
+  definitions:
+    app:
+      prompt: "App?"
+      type:
+        templates: true
+    env:
+      prompt: "Env?"
+      choices: ["dev", "prod"]`,
+				".yg/_templates/job.yaml":             "path: .\nfilename: job.yaml\n---\njob",
+				".yg/_templates/deployment.yaml":      "path: .\nfilename: deployment.yaml\n---\ndeployment",
+				".yg/_templates/deployment.prod.yaml": "path: .\nfilename: deployment.yaml\n---\nprod",
+				".yg/_templates/.draft.yaml":          "path: .\nfilename: draft.yaml\n---\ndraft",
+				".yg/_templates/README.md":            "notes",
+			},
+			expected: "deployment,job",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(dir)
+
+			cfg, err := LoadConfig("")
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			question := cfg.Questions.GetQuestions()["app"]
+			choices, err := question.GetChoices(nil)
+			if err != nil {
+				t.Fatalf("Failed to get choices: %v", err)
+			}
+			if strings.Join(choices, ",") != tc.expected {
+				t.Errorf("Expected the templates %s, got %v", tc.expected, choices)
+			}
+		})
+	}
+}
+
+func TestQuestionFileDefault(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "release.yaml")
+	if err := os.WriteFile(path, []byte("release:\n  id: r-42\n  count: 3\nitems:\n  - name: first\n  - name: second\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		source   FileDefault
+		expected string
+		wantErr  bool
+	}{
+		{name: "nested key", source: FileDefault{Path: path, YAMLPath: ".release.id"}, expected: "r-42"},
+		{name: "without leading dot", source: FileDefault{Path: path, YAMLPath: "release.count"}, expected: "3"},
+		{name: "list index", source: FileDefault{Path: path, YAMLPath: ".items.1.name"}, expected: "second"},
+		{name: "missing file", source: FileDefault{Path: filepath.Join(dir, "missing.yaml"), YAMLPath: ".release.id"}, expected: ""},
+		{name: "missing key", source: FileDefault{Path: path, YAMLPath: ".release.name"}, wantErr: true},
+		{name: "not a scalar", source: FileDefault{Path: path, YAMLPath: ".release"}, wantErr: true},
+		{name: "index out of range", source: FileDefault{Path: path, YAMLPath: ".items.2"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			question := Question{DefaultFromFile: &tc.source}
+			value, err := question.FileDefault()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if value != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, value)
+			}
+		})
+	}
+}
+
+func TestConfigClone(t *testing.T) {
+	cfg := loadTestConfig(t, `output:
+  normalize: true
+profiles:
+  dev:
+    env: dev
+templates:
+  deployment:
+    type: file
+    path: deployment.yaml
+questions:
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev", "prod"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-1"]
+        prod: ["prod-1"]
+`)
+	env := cfg.Questions.Definitions["env"]
+	env.templateNames = []string{"deployment"}
+	cfg.Questions.Definitions["env"] = env
+	original := cfg.Clone()
+
+	clone := cfg.Clone()
+	clone.Questions.Definitions["env"].templateNames[0] = "other"
+	for _, keys := range clone.Questions.Definitions["cluster"].choiceOrder {
+		keys[0] = "other"
+	}
+	clone.Output.Normalize = false
+	clone.Profiles["dev"]["env"] = "prod"
+	clone.Templates["deployment"] = TemplateConfig{Type: "directory"}
+	clone.Questions.Definitions["cluster"].Choices.(map[string]interface{})["dev"] = []interface{}{"other"}
+	clone.Questions.Definitions["cluster"].Type.Dynamic.DependencyQuestions[0] = "region"
+	delete(clone.Questions.Definitions, "env")
+
+	if !reflect.DeepEqual(cfg, original) {
+		t.Errorf("Expected changes to the clone to leave the config unchanged, got %+v", cfg)
+	}
+	if names := cfg.Questions.Definitions["env"].templateNames; !reflect.DeepEqual(names, []string{"deployment"}) {
+		t.Errorf("Expected the template names to be unchanged, got %v", names)
+	}
+	if order := cfg.Questions.Definitions["cluster"].choiceOrder; !reflect.DeepEqual(order, map[string][]string{"": {"dev", "prod"}}) {
+		t.Errorf("Expected the choice order to be unchanged, got %q", order)
+	}
+}
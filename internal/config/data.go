@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveDataFiles makes the relative data file paths relative to dir.
+func (c *Config) resolveDataFiles(dir string) {
+	for name, path := range c.DataFiles {
+		if !filepath.IsAbs(path) {
+			c.DataFiles[name] = filepath.Join(dir, path)
+		}
+	}
+}
+
+// LoadDataFiles parses the files listed in data_files and returns their content
+// keyed by name. JSON files are parsed as YAML.
+func (c *Config) LoadDataFiles() (map[string]interface{}, error) {
+	data := make(map[string]interface{}, len(c.DataFiles))
+	for name, path := range c.DataFiles {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data file %s: %w", path, err)
+		}
+
+		var value interface{}
+		if err := yaml.Unmarshal(content, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse data file %s: %w", path, err)
+		}
+		data[name] = value
+	}
+	return data, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileDefault reads the default of a question from a value in a YAML or JSON file,
+// such as one written by an earlier generation.
+type FileDefault struct {
+	Path     string `yaml:"path"`      // file to read, relative to the working directory
+	YAMLPath string `yaml:"yaml_path"` // dotted path to the value, e.g. .release.id or .items.0.name
+}
+
+// FileDefault returns the value at the default_from_file YAML path, or an empty string
+// when the question has none or the file does not exist yet.
+func (q *Question) FileDefault() (string, error) {
+	source := q.DefaultFromFile
+	if source == nil {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(source.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read default file %s: %w", source.Path, err)
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return "", fmt.Errorf("failed to parse default file %s: %w", source.Path, err)
+	}
+
+	value, err := lookupYAMLPath(document, source.YAMLPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from %s: %w", source.YAMLPath, source.Path, err)
+	}
+	return value, nil
+}
+
+// lookupYAMLPath returns the scalar at a dotted path in a decoded YAML document. Each
+// segment is a mapping key or a list index.
+func lookupYAMLPath(document interface{}, path string) (string, error) {
+	current := document
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, exists := node[segment]
+			if !exists {
+				return "", fmt.Errorf("key %q not found", segment)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", fmt.Errorf("index %q out of range for a list of %d", segment, len(node))
+			}
+			current = node[index]
+		default:
+			return "", fmt.Errorf("cannot look up %q in a scalar", segment)
+		}
+	}
+
+	switch current.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return "", fmt.Errorf("value is not a scalar")
+	}
+	return fmt.Sprintf("%v", current), nil
+}
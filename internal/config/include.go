@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeDefinitions merges the question definitions of the files matching the include
+// patterns, resolved against dir, into the definitions. A question defined twice is an error.
+func (q *Questions) includeDefinitions(dir string, options LoadOptions) error {
+	if len(q.Include) == 0 {
+		return nil
+	}
+
+	// Questions written in the direct map format are moved to Definitions by normalize
+	definitions := q.Definitions
+	if definitions == nil {
+		definitions = q.DirectMap
+	}
+	if definitions == nil {
+		q.Definitions = make(map[string]Question)
+		definitions = q.Definitions
+	}
+
+	// source records where each question is defined, for conflict errors
+	source := make(map[string]string, len(definitions))
+	for key := range definitions {
+		source[key] = "the config file"
+	}
+
+	for _, pattern := range q.Include {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include pattern %s: %w", pattern, err)
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("include pattern %s matches no files", pattern)
+		}
+
+		for _, path := range paths {
+			included, err := decodeDefinitions(path, options)
+			if err != nil {
+				return err
+			}
+
+			keys := make([]string, 0, len(included))
+			for key := range included {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if previous, exists := source[key]; exists {
+					return fmt.Errorf("question '%s' is defined in both %s and %s", key, previous, path)
+				}
+				definitions[key] = included[key]
+				source[key] = path
+			}
+		}
+	}
+	return nil
+}
+
+// decodeDefinitions decodes a file mapping question keys to definitions,
+// rejecting unknown keys unless options.Lax is set.
+func decodeDefinitions(path string, options LoadOptions) (map[string]Question, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read included questions %s: %w", path, err)
+	}
+
+	var definitions map[string]Question
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(!options.Lax)
+	if err := decoder.Decode(&definitions); err != nil && !errors.Is(err, io.EOF) {
+		if !options.Lax && strings.Contains(err.Error(), "not found in type") {
+			err = fmt.Errorf("%w (use --lax to ignore unknown keys)", err)
+		}
+		return nil, fmt.Errorf("failed to parse included questions %s: %w", path, err)
+	}
+
+	// Capture the authored order of dynamic choice maps, as for the config file
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err == nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		for key, question := range definitions {
+			choicesNode := mappingValue(mappingValue(root.Content[0], key), "choices")
+			if choicesNode == nil || choicesNode.Kind != yaml.MappingNode {
+				continue
+			}
+			question.choiceOrder = make(map[string][]string)
+			collectKeyOrder(choicesNode, nil, question.choiceOrder)
+			definitions[key] = question
+		}
+	}
+
+	return definitions, nil
+}
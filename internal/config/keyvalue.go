@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IsKeyValue returns whether the question collects key=value pairs, such as labels,
+// instead of a choice.
+func (q *Question) IsKeyValue() bool {
+	return q.Type != nil && q.Type.KeyValue
+}
+
+// ParseKeyValue parses an answer to a key-value question: comma-separated key=value
+// pairs such as "team=web,tier=frontend", a list of pairs, or a map.
+func (q *Question) ParseKeyValue(answer interface{}) (map[string]string, error) {
+	var pairs []string
+	switch v := answer.(type) {
+	case map[string]string:
+		return v, nil
+	case map[string]interface{}:
+		result := make(map[string]string, len(v))
+		for key, value := range v {
+			result[key] = fmt.Sprintf("%v", value)
+		}
+		return result, nil
+	case []string:
+		pairs = v
+	case string:
+		if strings.TrimSpace(v) != "" {
+			pairs = strings.Split(v, ",")
+		}
+	default:
+		return nil, fmt.Errorf("%v is not a list of key=value pairs", answer)
+	}
+
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		result[key] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
+
+// FormatKeyValue formats key-value pairs as comma-separated key=value pairs sorted by
+// key, the form accepted by ParseKeyValue.
+func FormatKeyValue(pairs map[string]string) string {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + pairs[key]
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrTemplateRegistered is returned by RegisterTemplate when the name is already in use.
+var ErrTemplateRegistered = errors.New("template already registered")
+
+// RegisterTemplate adds the template to the templates section of the YAML config file
+// at path. The other settings and comments are kept, but the file is re-encoded with
+// two-space indentation, so its layout may change. An existing entry with the same
+// name is only replaced with force.
+func RegisterTemplate(path, name string, entry TemplateConfig, force bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to register template in %s: config is not a mapping", path)
+	}
+
+	var entryNode yaml.Node
+	if err := entryNode.Encode(entry); err != nil {
+		return fmt.Errorf("failed to encode template %s: %w", name, err)
+	}
+
+	templates := mappingValue(root, "templates")
+	switch {
+	case templates == nil:
+		templates = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "templates"}, templates)
+	case templates.Kind == yaml.ScalarNode && templates.Tag == "!!null":
+		*templates = yaml.Node{Kind: yaml.MappingNode}
+	case templates.Kind != yaml.MappingNode:
+		return fmt.Errorf("failed to register template in %s: templates is not a mapping", path)
+	}
+
+	if existing := mappingValue(templates, name); existing != nil {
+		if !force {
+			return fmt.Errorf("%w: %s (use --force to replace it)", ErrTemplateRegistered, name)
+		}
+		*existing = entryNode
+	} else {
+		templates.Content = append(templates.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &entryNode)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file %s: %w", path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	return nil
+}
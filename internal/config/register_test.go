@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegisterTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# Project questions
+questions:
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev"]
+`
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	if err := RegisterTemplate(path, "web", TemplateConfig{Type: "directory", Path: "web"}, false); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if !strings.Contains(string(content), "# Project questions") {
+		t.Errorf("Expected comments to be kept, got:\n%s", content)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load updated config: %v", err)
+	}
+	if entry := cfg.Templates["web"]; entry.Type != "directory" || entry.Path != "web" {
+		t.Errorf("Expected registered directory template, got %+v", entry)
+	}
+	if _, exists := cfg.Questions.GetQuestions()["env"]; !exists {
+		t.Error("Expected existing questions to be kept")
+	}
+
+	// The name is not reused without force
+	err = RegisterTemplate(path, "web", TemplateConfig{Type: "file", Path: "web.yaml"}, false)
+	if !errors.Is(err, ErrTemplateRegistered) {
+		t.Errorf("Expected ErrTemplateRegistered, got %v", err)
+	}
+
+	if err := RegisterTemplate(path, "web", TemplateConfig{Type: "file", Path: "web.yaml"}, true); err != nil {
+		t.Fatalf("Failed to replace template: %v", err)
+	}
+	cfg, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load updated config: %v", err)
+	}
+	if entry := cfg.Templates["web"]; entry.Type != "file" || entry.Path != "web.yaml" || len(cfg.Templates) != 1 {
+		t.Errorf("Expected the entry to be replaced, got %+v", cfg.Templates)
+	}
+}
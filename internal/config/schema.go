@@ -0,0 +1,97 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaID identifies the JSON Schema returned by Schema.
+const SchemaID = "https://github.com/daylight55/yg/config.schema.json"
+
+// schemaEnums lists the accepted values of string fields, keyed by type and field name.
+var schemaEnums = map[string][]interface{}{
+	"Question.choice_sort": {ChoiceSortNone, ChoiceSortAlpha},
+	"TemplateConfig.type":  {"file", "directory"},
+}
+
+// Schema returns a JSON Schema describing the config file, generated from the
+// Config struct so that it stays in sync with the accepted keys. Unknown keys are
+// rejected, matching the default strict decoding.
+func Schema() map[string]interface{} {
+	defs := make(map[string]interface{})
+	schema := structSchema(reflect.TypeOf(Config{}), defs)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID
+	schema["title"] = "yg config"
+	schema["$defs"] = defs
+	return schema
+}
+
+// typeSchema returns the schema of a Go type. Named struct types are added to defs
+// once and referenced.
+func typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), defs)
+	case reflect.Struct:
+		if _, exists := defs[t.Name()]; !exists {
+			defs[t.Name()] = nil // reserve the name while the struct is being described
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), defs),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		// interface{} values such as choices accept any value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns the object schema of a struct from its yaml tags.
+func structSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var additional interface{} = false
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		// An inline map accepts its entries as additional keys, such as legacy questions
+		if options == "inline" {
+			additional = typeSchema(field.Type.Elem(), defs)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		property := typeSchema(field.Type, defs)
+		if enum, exists := schemaEnums[t.Name()+"."+name]; exists {
+			property["enum"] = enum
+		}
+		properties[name] = property
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": additional,
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateSchema checks value against the subset of JSON Schema emitted by Schema
+// and returns the paths of the violations.
+func validateSchema(schema map[string]interface{}, defs map[string]interface{}, value interface{}, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return validateSchema(defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{}), defs, value, path)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+			}
+		}
+		if !found {
+			return []string{fmt.Sprintf("%s: %v is not one of %v", path, value, enum)}
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %T", path, value)}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var problems []string
+		for _, key := range keys {
+			property, exists := properties[key]
+			switch {
+			case exists:
+				problems = append(problems, validateSchema(property.(map[string]interface{}), defs, object[key], path+"."+key)...)
+			case schema["additionalProperties"] == false:
+				problems = append(problems, fmt.Sprintf("%s: unknown key %s", path, key))
+			default:
+				additional := schema["additionalProperties"].(map[string]interface{})
+				problems = append(problems, validateSchema(additional, defs, object[key], path+"."+key)...)
+			}
+		}
+		return problems
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array, got %T", path, value)}
+		}
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, validateSchema(schema["items"].(map[string]interface{}), defs, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected string, got %T", path, value)}
+		}
+	case "integer":
+		if _, ok := value.(int); !ok {
+			return []string{fmt.Sprintf("%s: expected integer, got %T", path, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected boolean, got %T", path, value)}
+		}
+	}
+	return nil
+}
+
+func TestSchemaValidatesConfig(t *testing.T) {
+	// Round-trip through JSON as an editor would read the emitted schema
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("Failed to encode schema: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to decode schema: %v", err)
+	}
+	defs := schema["$defs"].(map[string]interface{})
+
+	testCases := []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{
+			name: "valid",
+			config: `templates:
+  web:
+    type: directory
+    path: web
+preview:
+  enabled: false
+max_combinations: 10
+questions:
+  template_question: app
+  order: ["app", "env", "replicas"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["web"]
+    env:
+      prompt: "Env?"
+      choice_sort: alpha
+      type:
+        multiple: true
+      choices:
+        dev: ["dev-1"]
+    replicas:
+      prompt: "Replicas?"
+      type:
+        number:
+          min: 1`,
+		},
+		{
+			name: "legacy questions",
+			config: `questions:
+  app:
+    prompt: "App?"
+    choices: ["web"]`,
+		},
+		{
+			name: "invalid",
+			config: `templates:
+  web:
+    type: inline
+questions:
+  order: app
+  definitons:
+    app:
+      prompt: "App?"
+preview:
+  enabled: "no"`,
+			// definitons is read as a legacy question, which has no app key
+			expected: []string{
+				"$.preview.enabled: expected boolean, got string",
+				"$.questions.definitons: unknown key app",
+				"$.questions.order: expected array, got string",
+				"$.templates.web.type: inline is not one of [file directory]",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var value interface{}
+			if err := yaml.Unmarshal([]byte(tc.config), &value); err != nil {
+				t.Fatalf("Failed to parse config: %v", err)
+			}
+
+			problems := validateSchema(schema, defs, value, "$")
+			if strings.Join(problems, "\n") != strings.Join(tc.expected, "\n") {
+				t.Errorf("Expected problems:\n%s\ngot:\n%s", strings.Join(tc.expected, "\n"), strings.Join(problems, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// TemplateNames returns the names of the available templates: those in the templates
+// section, or every single file template in .yg/_templates when the section is absent.
+// With template_variant set, variants such as deployment.prod of an available template
+// are left out.
+func (c *Config) TemplateNames() []string {
+	var names []string
+	if len(c.Templates) > 0 {
+		for name := range c.Templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	entries, err := os.ReadDir(filepath.Join(c.Root, ".yg", "_templates"))
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(name, ".yaml"))
+	}
+
+	if c.Questions.TemplateVariant == "" {
+		return names
+	}
+	return slices.DeleteFunc(names, func(name string) bool {
+		base, _, found := strings.Cut(name, ".")
+		return found && slices.Contains(names, base)
+	})
+}
+
+// resolveTemplateChoices offers the available templates as the choices of questions
+// whose type sets templates.
+func (c *Config) resolveTemplateChoices() {
+	var names []string
+	for key, question := range c.Questions.Definitions {
+		if question.Type == nil || !question.Type.Templates {
+			continue
+		}
+		if names == nil {
+			names = c.TemplateNames()
+		}
+		question.templateNames = names
+		c.Questions.Definitions[key] = question
+	}
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate checks the semantics of the question configuration without touching
+// templates: order and definitions consistency, the template question, the
+// dependency graph of dynamic and inherited questions, validation rules and profile answers.
+// All problems found are reported together.
+func (c *Config) Validate() error {
+	var problems []error
+
+	questions := c.Questions.GetQuestions()
+	keys := make([]string, 0, len(questions))
+	for key := range questions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[string]bool)
+	for _, key := range c.Questions.Order {
+		if _, exists := questions[key]; !exists {
+			problems = append(problems, fmt.Errorf("order lists undefined question '%s'", key))
+		}
+		if seen[key] {
+			problems = append(problems, fmt.Errorf("order lists question '%s' more than once", key))
+		}
+		seen[key] = true
+	}
+
+	if key := c.Questions.GetTemplateQuestion(); key != "" {
+		if question, exists := questions[key]; !exists {
+			problems = append(problems, fmt.Errorf("template_question '%s' is not defined", key))
+		} else if question.IsMultiple() {
+			problems = append(problems, fmt.Errorf("template_question '%s' must not be a multiple selection", key))
+		}
+	}
+
+	if key := c.Questions.TemplateVariant; key != "" {
+		if _, exists := questions[key]; !exists {
+			problems = append(problems, fmt.Errorf("template_variant '%s' is not defined", key))
+		}
+	}
+
+	for _, key := range keys {
+		question := questions[key]
+		for _, dep := range question.dependencies() {
+			if _, exists := questions[dep]; !exists {
+				problems = append(problems, fmt.Errorf("question '%s' depends on undefined question '%s'", key, dep))
+			}
+		}
+
+		if err := question.validateType(); err != nil {
+			problems = append(problems, fmt.Errorf("question '%s': %w", key, err))
+		}
+	}
+
+	problems = append(problems, c.Questions.dependencyProblems()...)
+
+	for i, rule := range c.Questions.TemplateWhen {
+		if strings.TrimSpace(rule.When) == "" || strings.TrimSpace(rule.Template) == "" {
+			problems = append(problems, fmt.Errorf("template_when rule %d needs both when and template", i+1))
+		}
+	}
+
+	for i, validation := range c.Validations {
+		if strings.TrimSpace(validation.Rule) == "" {
+			problems = append(problems, fmt.Errorf("validation %d has no rule", i+1))
+		}
+		if strings.TrimSpace(validation.Message) == "" {
+			problems = append(problems, fmt.Errorf("validation %d has no message", i+1))
+		}
+	}
+
+	if c.Output != nil && c.Output.Merge != "" && c.Output.Merge != MergeDeep {
+		problems = append(problems, fmt.Errorf("invalid output.merge: %s (expected %s)", c.Output.Merge, MergeDeep))
+	}
+	if c.Output != nil && c.Output.Index != nil && c.Output.Index.Path == "" {
+		problems = append(problems, fmt.Errorf("output.index needs a path"))
+	}
+
+	profileNames := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		profileNames = append(profileNames, name)
+	}
+	sort.Strings(profileNames)
+	for _, name := range profileNames {
+		answerKeys := make([]string, 0, len(c.Profiles[name]))
+		for key := range c.Profiles[name] {
+			answerKeys = append(answerKeys, key)
+		}
+		sort.Strings(answerKeys)
+		for _, key := range answerKeys {
+			if _, exists := questions[key]; !exists {
+				problems = append(problems, fmt.Errorf("profile '%s' answers undefined question '%s'", name, key))
+			}
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// dependencyProblems reports dependency cycles and dependencies that are asked
+// after their dependents in order. Undefined dependencies are ignored.
+func (q *Questions) dependencyProblems() []error {
+	var problems []error
+
+	questions := q.GetQuestions()
+	keys := make([]string, 0, len(questions))
+	for key := range questions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// position records where each question is asked when the order is explicit
+	position := make(map[string]int)
+	for i, key := range q.Order {
+		if _, seen := position[key]; !seen {
+			position[key] = i
+		}
+	}
+
+	for _, key := range keys {
+		question := questions[key]
+		keyPosition, ordered := position[key]
+		for _, dep := range question.dependencies() {
+			if depPosition, depOrdered := position[dep]; ordered && depOrdered && depPosition > keyPosition {
+				problems = append(problems, fmt.Errorf("question '%s' depends on '%s', which is asked after it in order", key, dep))
+			}
+		}
+	}
+
+	if cycle := dependencyCycle(questions, keys); cycle != nil {
+		problems = append(problems, fmt.Errorf("circular dependency between questions: %s", strings.Join(cycle, " -> ")))
+	}
+
+	return problems
+}
+
+// dependencies returns the questions whose answers this question needs.
+func (q *Question) dependencies() []string {
+	var deps []string
+	if q.Type != nil && q.Type.Dynamic != nil {
+		deps = append(deps, q.Type.Dynamic.DependencyQuestions...)
+	}
+	if q.DefaultFrom != "" {
+		deps = append(deps, q.DefaultFrom)
+	}
+	if q.FilterBy != nil && q.FilterBy.Question != "" {
+		deps = append(deps, q.FilterBy.Question)
+	}
+	return deps
+}
+
+// validateType checks the settings that do not depend on other questions.
+func (q *Question) validateType() error {
+	switch q.ChoiceSort {
+	case "", ChoiceSortNone, ChoiceSortAlpha:
+	default:
+		return fmt.Errorf("invalid choice_sort: %s", q.ChoiceSort)
+	}
+
+	if q.IsNumber() {
+		if number := q.Type.Number; number.Min != nil && number.Max != nil && *number.Min > *number.Max {
+			return fmt.Errorf("number min %d is greater than max %d", *number.Min, *number.Max)
+		}
+	}
+
+	if q.IsBool() {
+		if q.IsNumber() || q.IsMultiple() || q.IsSecret() {
+			return fmt.Errorf("bool cannot be combined with number, multiple or secret")
+		}
+		if q.Choices != nil || q.ChoicesCommand != "" {
+			return fmt.Errorf("bool questions cannot have choices")
+		}
+		for _, value := range q.Default {
+			if _, err := q.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid default: %w", err)
+			}
+		}
+	}
+
+	if q.Type != nil && q.Type.Templates {
+		if q.IsNumber() || q.IsBool() || q.IsSecret() || q.Type.Dynamic != nil {
+			return fmt.Errorf("templates cannot be combined with number, bool, secret or dynamic")
+		}
+		if q.Choices != nil || q.ChoicesCommand != "" {
+			return fmt.Errorf("templates questions cannot have choices")
+		}
+	}
+
+	if q.IsKeyValue() {
+		if q.IsNumber() || q.IsBool() || q.IsSecret() || q.IsMultiple() || q.Type.Templates || q.Type.Dynamic != nil {
+			return fmt.Errorf("keyvalue cannot be combined with number, bool, secret, multiple, templates or dynamic")
+		}
+		if q.Choices != nil || q.ChoicesCommand != "" {
+			return fmt.Errorf("keyvalue questions cannot have choices")
+		}
+	}
+
+	if q.DefaultFromFile != nil && (q.DefaultFromFile.Path == "" || q.DefaultFromFile.YAMLPath == "") {
+		return fmt.Errorf("default_from_file needs both path and yaml_path")
+	}
+
+	if q.FilterBy != nil && (q.FilterBy.Question == "" || q.FilterBy.Field == "") {
+		return fmt.Errorf("filter_by needs both question and field")
+	}
+
+	if q.ChoicesCommand != "" && q.Choices != nil {
+		return fmt.Errorf("choices and choices_command cannot both be set")
+	}
+	if q.Choices == nil && q.ChoicesCommand == "" && !q.takesInput() && (q.Type == nil || !q.Type.Templates) {
+		return fmt.Errorf("no choices defined")
+	}
+	if _, err := q.choicesTimeout(); err != nil {
+		return err
+	}
+
+	if choice := choiceWithoutValue(q.Choices); choice != nil {
+		return fmt.Errorf("choice %v has no value", choice)
+	}
+
+	switch {
+	case (q.MinSelect != 0 || q.MaxSelect != 0) && !q.IsMultiple():
+		return fmt.Errorf("min_select and max_select require a multiple selection")
+	case q.MinSelect < 0 || q.MaxSelect < 0:
+		return fmt.Errorf("min_select and max_select must not be negative")
+	case q.MaxSelect > 0 && q.MinSelect > q.MaxSelect:
+		return fmt.Errorf("min_select %d is greater than max_select %d", q.MinSelect, q.MaxSelect)
+	}
+	return nil
+}
+
+// choiceWithoutValue returns the first choice in the object form without a value key,
+// or nil if there is none.
+func choiceWithoutValue(choices interface{}) interface{} {
+	switch value := choices.(type) {
+	case []interface{}:
+		for _, choice := range value {
+			if object, ok := choice.(map[string]interface{}); ok && object["value"] == nil {
+				return choice
+			}
+		}
+	case map[string]interface{}:
+		for _, branch := range value {
+			if choice := choiceWithoutValue(branch); choice != nil {
+				return choice
+			}
+		}
+	}
+	return nil
+}
+
+// dependencyCycle returns the questions forming a dependency cycle, or nil if there is none.
+func dependencyCycle(questions map[string]Question, keys []string) []string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(key string) []string
+	visit = func(key string) []string {
+		state[key] = visiting
+		stack = append(stack, key)
+		question := questions[key]
+		for _, dep := range question.dependencies() {
+			if _, exists := questions[dep]; !exists {
+				continue
+			}
+			switch state[dep] {
+			case visiting:
+				for i, key := range stack {
+					if key == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case 0:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[key] = done
+		return nil
+	}
+
+	for _, key := range keys {
+		if state[key] == 0 {
+			if cycle := visit(key); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
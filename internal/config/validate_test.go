@@ -0,0 +1,279 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadTestConfig(t *testing.T, content string) *Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func TestValidateValidConfig(t *testing.T) {
+	cfg := loadTestConfig(t, `output:
+  merge: deep
+profiles:
+  dev:
+    app: deployment
+questions:
+  template_question: app
+  order: ["app", "env", "cluster", "namespace"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-cluster"]
+        staging: ["staging-cluster"]
+    namespace:
+      prompt: "Namespace?"
+      default_from: env`)
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected config to be valid, got %v", err)
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	cfg := loadTestConfig(t, `output:
+  merge: shallow
+  index:
+    template: "{{ .Files }}"
+profiles:
+  dev:
+    region: eu
+questions:
+  template_question: env
+  template_variant: stage
+  order: ["app", "env", "cluster", "missing"]
+  definitions:
+    app:
+      prompt: "App?"
+      choice_sort: random
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        dynamic:
+          dependency_questions: ["env", "zone"]
+      choices:
+        dev: ["dev-cluster"]
+    replicas:
+      prompt: "Replicas?"
+      type:
+        number:
+          min: 5
+          max: 1
+    regions:
+      prompt: "Regions?"
+      type:
+        multiple: true
+      min_select: 3
+      max_select: 2
+      choices: ["eu"]
+    namespace:
+      prompt: "Namespace?"
+      choices_command: "kubectl get namespaces -o name"
+      choices_timeout: soon
+    tier:
+      prompt: "Tier?"
+      choices:
+        - description: "Missing its value"
+    tls:
+      prompt: "TLS?"
+      type:
+        bool: true
+      choices: ["yes", "no"]
+    rack:
+      prompt: "Rack?"
+      filter_by:
+        question: regions
+      choices: ["r1"]
+    build:
+      prompt: "Build?"
+      default_from_file:
+        path: out/build.yaml
+    stack:
+      prompt: "Stack?"
+      type:
+        templates: true
+      choices: ["web"]
+    labels:
+      prompt: "Labels?"
+      type:
+        keyvalue: true
+      choices: ["team=web"]
+    site:
+      prompt: "Site?"
+validations:
+  - rule: '{{ ne .Questions.env "prod" }}'
+`)
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected config to be invalid")
+	}
+
+	expected := []string{
+		"order lists undefined question 'missing'",
+		"template_question 'env' must not be a multiple selection",
+		"template_variant 'stage' is not defined",
+		"question 'cluster' depends on undefined question 'zone'",
+		"question 'app': invalid choice_sort: random",
+		"question 'replicas': number min 5 is greater than max 1",
+		"question 'regions': min_select 3 is greater than max_select 2",
+		"question 'tier': choice map[description:Missing its value] has no value",
+		"question 'namespace': invalid choices_timeout \"soon\"",
+		"question 'tls': bool questions cannot have choices",
+		"question 'rack': filter_by needs both question and field",
+		"question 'build': default_from_file needs both path and yaml_path",
+		"question 'stack': templates questions cannot have choices",
+		"question 'labels': keyvalue questions cannot have choices",
+		"question 'site': no choices defined",
+		"validation 1 has no message",
+		"invalid output.merge: shallow (expected deep)",
+		"output.index needs a path",
+		"profile 'dev' answers undefined question 'region'",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(err.Error(), fragment) {
+			t.Errorf("Expected error to contain %q, got:\n%v", fragment, err)
+		}
+	}
+}
+
+func TestLoadConfigDependencyProblems(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name: "direct cycle",
+			config: `questions:
+  definitions:
+    a:
+      prompt: "A?"
+      type:
+        dynamic:
+          dependency_questions: ["b"]
+      choices: {}
+    b:
+      prompt: "B?"
+      type:
+        dynamic:
+          dependency_questions: ["a"]
+      choices: {}`,
+			expected: "circular dependency between questions: a -> b -> a",
+		},
+		{
+			name: "indirect cycle",
+			config: `questions:
+  definitions:
+    a:
+      prompt: "A?"
+      type:
+        dynamic:
+          dependency_questions: ["b"]
+      choices: {}
+    b:
+      prompt: "B?"
+      type:
+        dynamic:
+          dependency_questions: ["c"]
+      choices: {}
+    c:
+      prompt: "C?"
+      default_from: a`,
+			expected: "circular dependency between questions: a -> b -> c -> a",
+		},
+		{
+			name: "out of order",
+			config: `questions:
+  order: ["cluster", "env"]
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-cluster"]`,
+			expected: "question 'cluster' depends on 'env', which is asked after it in order",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tc.config), 0o600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestGeneratedOrderRespectsDependencies(t *testing.T) {
+	cfg := loadTestConfig(t, `questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-cluster"]
+    env:
+      prompt: "Env?"
+      choices: ["dev"]
+    appName:
+      prompt: "Name?"
+      default_from: app`)
+
+	expected := "app,appName,env,cluster"
+	if order := strings.Join(cfg.Questions.GetOrder(), ","); order != expected {
+		t.Errorf("Expected order %s, got %s", expected, order)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected generated order to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBenchmarkGenerator returns a generator and the multi-value answers selecting 4
+// environments with 5 clusters each, 5 regions and 4 tiers: 400 combinations.
+func newBenchmarkGenerator(tb testing.TB) (*Generator, map[string][]string) {
+	tb.Helper()
+
+	var envs, clusters, clusterChoices []string
+	for e := 0; e < 4; e++ {
+		env := fmt.Sprintf("env-%d", e)
+		envs = append(envs, env)
+		var envClusters []string
+		for c := 0; c < 5; c++ {
+			cluster := fmt.Sprintf("%s-cluster-%d", env, c)
+			envClusters = append(envClusters, cluster)
+			clusters = append(clusters, env+": "+cluster)
+		}
+		clusterChoices = append(clusterChoices, fmt.Sprintf("        %s: [%s]\n", env, strings.Join(envClusters, ", ")))
+	}
+
+	content := fmt.Sprintf(`questions:
+  order: ["env", "cluster", "region", "tier"]
+  definitions:
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: [%s]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        multiple: true
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+%s    region:
+      prompt: "Region?"
+      type:
+        multiple: true
+      choices: [r1, r2, r3, r4, r5]
+    tier:
+      prompt: "Tier?"
+      type:
+        multiple: true
+      choices: [t1, t2, t3, t4]
+`, strings.Join(envs, ", "), strings.Join(clusterChoices, ""))
+
+	configPath := filepath.Join(tb.TempDir(), ".yg", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		tb.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		tb.Fatalf("Failed to write config file: %v", err)
+	}
+
+	generator, err := NewWithConfig(configPath)
+	if err != nil {
+		tb.Fatalf("Failed to create generator: %v", err)
+	}
+	return generator, map[string][]string{
+		"env":     envs,
+		"cluster": clusters,
+		"region":  {"r1", "r2", "r3", "r4", "r5"},
+		"tier":    {"t1", "t2", "t3", "t4"},
+	}
+}
+
+func TestBenchmarkCombinations(t *testing.T) {
+	generator, multiValues := newBenchmarkGenerator(t)
+
+	combinations := generator.generateCombinations(multiValues)
+	if len(combinations) != 400 {
+		t.Fatalf("Expected 400 combinations, got %d", len(combinations))
+	}
+	for _, combination := range combinations {
+		if !strings.HasPrefix(combination["cluster"].(string), combination["env"].(string)+"-") {
+			t.Fatalf("Cluster %v paired with environment %v", combination["cluster"], combination["env"])
+		}
+	}
+}
+
+func BenchmarkGenerateCombinations(b *testing.B) {
+	generator, multiValues := newBenchmarkGenerator(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generator.generateCombinations(multiValues)
+	}
+}
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// diffSummary counts the files and lines that a generation would change.
+type diffSummary struct {
+	changed, created, unchanged int
+	added, removed              int
+}
+
+func (s diffSummary) String() string {
+	return fmt.Sprintf("%d files changed, %d new, %d unchanged, +%d/-%d lines",
+		s.changed, s.created, s.unchanged, s.added, s.removed)
+}
+
+// printDiff writes the diff of every file that would be generated against the file
+// already at its output path, followed by a summary of the changes, without writing
+// them. With keep-going, targets that fail to render are reported to w and skipped.
+func (g *Generator) printDiff(w io.Writer) error {
+	targets, _, err := g.renderTargets()
+	if err != nil {
+		return err
+	}
+
+	var summary diffSummary
+	fmt.Fprintln(w, "\nDiff:")
+	for _, target := range targets {
+		if target.err != nil {
+			if !g.keepGoing {
+				return target.err
+			}
+			fmt.Fprintf(w, "! %s: %v\n", target.label, target.err)
+			continue
+		}
+
+		for _, file := range target.files {
+			file, err := g.mergeExisting(file)
+			if err != nil {
+				return err
+			}
+			fullPath := filepath.Join(file.Path, file.Filename)
+
+			previous, err := os.ReadFile(fullPath)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				summary.created++
+			case err != nil:
+				return fmt.Errorf("failed to read %s: %w", fullPath, err)
+			case string(previous) == file.Content:
+				summary.unchanged++
+				continue
+			default:
+				summary.changed++
+			}
+
+			fmt.Fprintf(w, "--- %s\n+++ %s\n", fullPath, fullPath)
+			for _, line := range lineDiff(string(previous), file.Content) {
+				if strings.HasPrefix(line, "+") {
+					summary.added++
+				} else {
+					summary.removed++
+				}
+				fmt.Fprintln(w, line)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", summary)
+	return nil
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrintDiffSummary(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "stg", "prod"]`,
+		".yg/_templates/deployment.yaml": "path: out\nfilename: {{ .Questions.env }}.yaml\n---\nkind: Deployment\nenv: {{ .Questions.env }}\nreplicas: 1",
+		// dev is unchanged, stg has a different kind and prod is new
+		"out/dev.yaml": "kind: Deployment\nenv: dev\nreplicas: 1",
+		"out/stg.yaml": "kind: Service\nenv: stg\nreplicas: 1",
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{
+		"app": testAppTypeDeployment,
+		"env": []string{"dev", "stg", "prod"},
+	}
+
+	var buf bytes.Buffer
+	if err := generator.printDiff(&buf); err != nil {
+		t.Fatalf("Failed to print diff: %v", err)
+	}
+	output := buf.String()
+
+	if !strings.HasSuffix(output, "\n1 files changed, 1 new, 1 unchanged, +4/-1 lines\n") {
+		t.Errorf("Expected a summary of the changes, got:\n%s", output)
+	}
+	for _, fragment := range []string{"-kind: Service\n+kind: Deployment\n", "+++ out/prod.yaml\n+kind: Deployment\n"} {
+		if !strings.Contains(output, fragment) {
+			t.Errorf("Expected the diff to contain %q, got:\n%s", fragment, output)
+		}
+	}
+	if strings.Contains(output, "out/dev.yaml") {
+		t.Errorf("Expected no diff for the unchanged file, got:\n%s", output)
+	}
+
+	// Nothing is written
+	if _, err := os.Stat(filepath.Join("out", "prod.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected no files to be written, got err %v", err)
+	}
+}
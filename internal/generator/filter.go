@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// combinationFilter keeps only combinations whose answer for key matches a pattern.
+type combinationFilter struct {
+	key   string
+	glob  string
+	regex *regexp.Regexp
+}
+
+// parseFilters parses filter expressions of the form key=glob or key~=regex.
+func parseFilters(expressions []string) ([]combinationFilter, error) {
+	filters := make([]combinationFilter, 0, len(expressions))
+	for _, expression := range expressions {
+		filter, err := parseFilter(expression)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func parseFilter(expression string) (combinationFilter, error) {
+	if key, pattern, ok := strings.Cut(expression, "~="); ok && key != "" {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return combinationFilter{}, fmt.Errorf("invalid regex in filter '%s': %w", expression, err)
+		}
+		return combinationFilter{key: key, regex: regex}, nil
+	}
+
+	key, pattern, ok := strings.Cut(expression, "=")
+	if !ok || key == "" {
+		return combinationFilter{}, fmt.Errorf("invalid filter '%s': expected key=glob or key~=regex", expression)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return combinationFilter{}, fmt.Errorf("invalid glob in filter '%s': %w", expression, err)
+	}
+	return combinationFilter{key: key, glob: pattern}, nil
+}
+
+// matches reports whether the combination's answer for the filter key matches the pattern.
+func (f combinationFilter) matches(combination map[string]interface{}) bool {
+	value, exists := combination[f.key]
+	if !exists {
+		return false
+	}
+
+	str := fmt.Sprintf("%v", value)
+	if f.regex != nil {
+		return f.regex.MatchString(str)
+	}
+
+	matched, _ := path.Match(f.glob, str)
+	return matched
+}
+
+// filterCombinations keeps only the combinations matching all filters.
+func filterCombinations(
+	combinations []map[string]interface{}, filters []combinationFilter,
+) []map[string]interface{} {
+	if len(filters) == 0 {
+		return combinations
+	}
+
+	result := make([]map[string]interface{}, 0, len(combinations))
+	for _, combination := range combinations {
+		matched := true
+		for _, filter := range filters {
+			if !filter.matches(combination) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, combination)
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFilters(t *testing.T) {
+	filters, err := parseFilters([]string{"cluster=dev-*", "env~=^(dev|staging)$"})
+	if err != nil {
+		t.Fatalf("Failed to parse filters: %v", err)
+	}
+
+	if len(filters) != 2 {
+		t.Fatalf("Expected 2 filters, got %d", len(filters))
+	}
+
+	if filters[0].key != "cluster" || filters[0].glob != "dev-*" {
+		t.Errorf("Expected glob filter on cluster, got %+v", filters[0])
+	}
+
+	if filters[1].key != "env" || filters[1].regex == nil {
+		t.Errorf("Expected regex filter on env, got %+v", filters[1])
+	}
+}
+
+func TestParseFiltersInvalid(t *testing.T) {
+	invalid := []string{"cluster", "=dev-*", "cluster=[", "env~=("}
+	for _, expression := range invalid {
+		if _, err := parseFilters([]string{expression}); err == nil {
+			t.Errorf("Expected error for invalid filter %q", expression)
+		}
+	}
+}
+
+func TestFilterCombinations(t *testing.T) {
+	combinations := []map[string]interface{}{
+		{"env": "dev", "cluster": "dev-cluster-1"},
+		{"env": "dev", "cluster": "dev-cluster-2"},
+		{"env": "staging", "cluster": "staging-cluster-1"},
+	}
+
+	filters, err := parseFilters([]string{"cluster=*-1", "env~=^dev$"})
+	if err != nil {
+		t.Fatalf("Failed to parse filters: %v", err)
+	}
+
+	result := filterCombinations(combinations, filters)
+	if len(result) != 1 || result[0]["cluster"] != "dev-cluster-1" {
+		t.Errorf("Expected only dev-cluster-1 to match all filters, got %v", result)
+	}
+
+	if len(filterCombinations(combinations, nil)) != 3 {
+		t.Error("Expected all combinations without filters")
+	}
+}
+
+func TestRunWithOptionsFilters(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app":     testAppTypeDeployment,
+			"appName": "test-app",
+			"env":     []string{"dev"},
+			"cluster": []string{"dev-cluster-1", "dev-cluster-2", "dev-cluster-3"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+		Filters:    []string{"cluster=*-[12]"},
+	}
+
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator with filters: %v", err)
+	}
+
+	for _, cluster := range []string{"dev-cluster-1", "dev-cluster-2"} {
+		expectedFile := filepath.Join(tempDir, "dev", cluster, "deployment", "test-app-deployment.yaml")
+		if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
+			t.Errorf("Expected file %s was not generated", expectedFile)
+		}
+	}
+
+	filteredFile := filepath.Join(tempDir, "dev", "dev-cluster-3", "deployment", "test-app-deployment.yaml")
+	if _, err := os.Stat(filteredFile); !os.IsNotExist(err) {
+		t.Errorf("Filtered combination should not generate %s", filteredFile)
+	}
+}
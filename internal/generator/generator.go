@@ -3,30 +3,107 @@ package generator
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
+	gotemplate "text/template"
+	"time"
 
 	"github.com/daylight55/yg/internal/config"
 	"github.com/daylight55/yg/internal/prompt"
 	"github.com/daylight55/yg/internal/template"
 )
 
+var (
+	// ErrInvalidOptions is returned when the provided options or answers are invalid.
+	ErrInvalidOptions = errors.New("invalid options")
+	// ErrCanceled is returned when the operation is canceled by the user.
+	ErrCanceled = errors.New("operation canceled")
+)
+
 // Options holds CLI options for the generator.
 type Options struct {
-	Answers    map[string]interface{}
-	SkipPrompt bool
-	NoPreview  bool
+	Answers             map[string]interface{}
+	SkipPrompt          bool
+	NoPreview           bool  // forces the preview off, like Preview set to false
+	Preview             *bool // overrides the configured preview setting when set
+	Explain             bool
+	Count               bool
+	NoColor             bool
+	Color               string // ColorAuto (default), ColorAlways or ColorNever
+	AllTemplates        bool
+	Templates           []string
+	KeepGoing           bool
+	ConfirmDefault      *bool // overrides the configured confirmation default when set
+	Filters             []string
+	OutputLayout        string
+	Profile             string // named answer preset from the profiles config section
+	MaxCombinations     int    // overrides the configured combination limit when positive
+	TraceTemplate       bool   // print the data of each combination before rendering it
+	Verbose             bool   // report diagnostics, such as auto-selected answers, to stderr
+	SkipGenerated       bool   // skip combinations recorded in the lockfile
+	Force               bool   // generate recorded combinations anyway, updating the lockfile
+	StrictChoices       bool   // re-ask search prompts that return a value outside the choices
+	NamespaceByTemplate bool   // write each template's output under a directory named after it
+	Report              string // path to write the outcome of each combination to
+	RetryFrom           string // path of a report whose failed combinations are generated again
+	NoTemplateConfig    bool   // load single file templates without the templates config section
+	DumpAnswersJSON     bool   // print only the answers to stdout as a JSON object, without secret answers
+	FromAnswersOf       string // output directory of an earlier generation to recover answers from
+	PromptMissing       bool   // prompt only for unanswered questions and skip the confirmation
+	TemplateFile        string // single file template to render instead of the configured templates
+	PrintTree           bool   // print a tree of the files to generate before the confirmation
+	Diff                bool   // print the diff against the existing files before the confirmation
+	Review              *bool  // overrides the configured answer review setting when set
+	ListCombinations    bool   // print the answers of each combination without generating
+	CLIExample          *bool  // overrides the configured show_cli_example setting when set
+
+	// Echo receives each answer given at a prompt of the default prompter as a
+	// "key: value" line when set.
+	Echo io.Writer
 }
 
+// ExitCodeInterrupted is the process exit code used when interrupted by a signal.
+const ExitCodeInterrupted = 130
+
+// DefaultWarnAbove is the number of files above which a large generation is warned about.
+const DefaultWarnAbove = 100
+
 // Generator handles the main generation workflow.
 type Generator struct {
-	config   *config.Config
-	prompter prompt.PrompterInterface
-	answers  map[string]interface{}
+	config           *config.Config
+	prompter         prompt.PrompterInterface
+	answers          map[string]interface{}
+	templateTypes    []string // explicitly selected templates, overriding the template question
+	keepGoing        bool     // continue past per-combination errors
+	filters          []combinationFilter
+	outputLayout     string                 // OutputLayoutNested or OutputLayoutFlat
+	presets          map[string]interface{} // profile answers pre-selected in prompts
+	output           io.Writer              // receives the human-readable output
+	traceOutput      io.Writer              // receives the data of each rendered combination when set
+	verboseOutput    io.Writer              // receives diagnostics, such as auto-selected answers, when set
+	data             map[string]interface{} // content of the configured data files
+	lock             *generatedLock         // records generated combinations when set
+	skipGenerated    bool                   // skip combinations recorded in the lock
+	strictChoices    bool                   // re-ask search prompts answered outside the choices
+	namespace        bool                   // prefix output paths with the template name
+	report           *Report                // outcome of each combination, written to reportPath when set
+	reportPath       string
+	retry            *Report      // report whose failed combinations are generated instead when set
+	noTemplateConfig bool         // skip the templates config section when loading templates
+	templateFile     string       // single file template loaded instead of the configured templates
+	written          []string     // paths of the files written, listed in the output.index file
+	rendered         *renderCache // targets rendered for the current generation
+	started          time.Time    // start of the current generation, exposed as .Meta.Timestamp
 }
 
 // New creates a new Generator instance.
@@ -36,18 +113,92 @@ func New() (*Generator, error) {
 
 // NewWithConfig creates a new Generator instance with specified config path.
 func NewWithConfig(configPath string) (*Generator, error) {
-	cfg, err := config.LoadConfig(configPath)
+	return NewWithConfigOptions(configPath, config.LoadOptions{})
+}
+
+// NewWithConfigOptions creates a new Generator instance with specified config path and load options.
+func NewWithConfigOptions(configPath string, loadOptions config.LoadOptions) (*Generator, error) {
+	cfg, err := config.LoadConfigWithOptions(configPath, loadOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	data, err := cfg.LoadDataFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data files: %w", err)
+	}
+
 	return &Generator{
 		config:   cfg,
-		prompter: prompt.NewPrompter(),
+		prompter: prompt.NewPrompterWithTheme(promptTheme(cfg.Theme)),
 		answers:  make(map[string]interface{}),
+		data:     data,
+		output:   os.Stdout,
 	}, nil
 }
 
+// promptOptions returns the options of the default prompter for a run with options.
+func promptOptions(options *Options) []prompt.Option {
+	var promptOpts []prompt.Option
+	if options.DumpAnswersJSON {
+		promptOpts = append(promptOpts, prompt.WithOutput(os.Stderr))
+	}
+	if options.Echo != nil {
+		promptOpts = append(promptOpts, prompt.WithEcho(options.Echo))
+	}
+	return promptOpts
+}
+
+// promptTheme converts the theme configuration into a prompt theme.
+func promptTheme(theme *config.ThemeConfig) *prompt.Theme {
+	if theme == nil {
+		return nil
+	}
+
+	return &prompt.Theme{
+		NoColor:       theme.NoColor,
+		QuestionIcon:  theme.QuestionIcon,
+		QuestionColor: theme.QuestionColor,
+		SelectIcon:    theme.SelectIcon,
+		SelectColor:   theme.SelectColor,
+	}
+}
+
+// Answers returns a copy of the answers collected by the generator.
+func (g *Generator) Answers() map[string]interface{} {
+	return g.copyAnswers()
+}
+
+// Config returns a deep copy of the configuration used by the generator.
+func (g *Generator) Config() *config.Config {
+	return g.config.Clone()
+}
+
+// Combinations returns the combinations of answers that files would be generated for
+// with the given answers, after CLI filters and skip_when, without loading or rendering
+// templates. Multi-value answers are expanded to one value per combination.
+// The answers collected by the generator are left unchanged.
+func (g *Generator) Combinations(answers map[string]interface{}) ([]map[string]interface{}, error) {
+	// Resolve on a copy of the generator holding its own copy of the given answers
+	scoped := *g
+	scoped.answers = make(map[string]interface{}, len(answers))
+	for key, value := range answers {
+		scoped.answers[key] = value
+	}
+	scoped.rendered = nil
+
+	_, multiValueQuestions, err := scoped.determineTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine template and multi-values: %w", err)
+	}
+	return scoped.resolveCombinations(multiValueQuestions)
+}
+
+// SetPrompter replaces the prompter used to ask questions.
+func (g *Generator) SetPrompter(prompter prompt.PrompterInterface) {
+	g.prompter = prompter
+}
+
 // Run executes the generation workflow.
 func (g *Generator) Run() error {
 	return g.RunWithOptions(&Options{})
@@ -64,27 +215,166 @@ func (g *Generator) RunWithOptions(options *Options) error {
 
 	go func() {
 		<-sigChan
-		fmt.Println("\nOperation canceled by user")
+		fmt.Fprintln(g.output, "\nOperation canceled by user")
 		cancel()
-		os.Exit(1)
+		os.Exit(ExitCodeInterrupted)
 	}()
 
-	// Use CLI options if skip prompt is enabled
-	if options.SkipPrompt {
-		if err := g.validateOptions(options); err != nil {
-			return fmt.Errorf("invalid options: %w", err)
+	// Targets are rendered again for the answers of this run
+	g.rendered = nil
+
+	// Dumped answers are the only output on stdout, everything else goes to stderr
+	g.output = os.Stdout
+	if options.DumpAnswersJSON {
+		g.output = os.Stderr
+	}
+	if _, isDefault := g.prompter.(*prompt.Prompter); isDefault {
+		g.prompter = prompt.NewPrompterWithTheme(promptTheme(g.config.Theme), promptOptions(options)...)
+	}
+
+	colored, err := g.colorEnabled(options, isTerminal(os.Stdout))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	prompt.SetColor(colored)
+
+	presets, err := g.configure(options)
+	if err != nil {
+		return err
+	}
+
+	// Recovered answers fill in those not given explicitly
+	if options.FromAnswersOf != "" {
+		recovered, err := g.recoverAnswers(options.FromAnswersOf, options.Answers, g.output)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
+		options = withPresetAnswers(options, recovered)
+	}
+
+	// Failed combinations are retried with the answers recorded in the report
+	if g.retry == nil {
+		if err := g.collectAnswers(ctx, options, presets); err != nil {
+			return err
+		}
+		if g.shouldReview(options) {
+			if err := g.reviewAnswers(); err != nil {
+				return err
+			}
+		}
+		if err := g.checkSelectionCounts(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
 		}
-		// Copy all provided answers
-		for key, value := range options.Answers {
-			g.answers[key] = value
+		if err := g.checkValidations(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
+	}
+
+	// Explain template and combination decisions without generating
+	if options.Explain {
+		return g.explain(g.output)
+	}
+
+	// Report the combination and file counts without rendering
+	if options.Count {
+		return g.printCount(g.output)
+	}
+
+	// List the combinations without rendering
+	if options.ListCombinations {
+		return g.listCombinations(g.output)
+	}
+
+	if err := g.checkMaxCombinations(options); err != nil {
+		return err
+	}
+
+	g.warnLargeGeneration(g.output)
+
+	// Generate and show preview (unless disabled)
+	previewEnabled := g.shouldShowPreview(options)
+	if previewEnabled {
+		if err := g.generatePreview(g.output); err != nil {
+			return fmt.Errorf("failed to generate preview: %w", err)
+		}
+	}
+	if options.PrintTree {
+		if err := g.printTree(g.output); err != nil {
+			return fmt.Errorf("failed to print tree: %w", err)
+		}
+	}
+	if options.Diff {
+		if err := g.printDiff(g.output); err != nil {
+			return fmt.Errorf("failed to print diff: %w", err)
+		}
+	}
+
+	// Confirm generation (skip if using --yes or --answer-prompt-missing flag)
+	if !options.SkipPrompt && !options.PromptMissing {
+		confirmed, err := g.prompter.Confirm(g.confirmMessage(), g.confirmDefault(options))
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+
+		if !confirmed {
+			fmt.Fprintln(g.output, "Generation canceled")
+			return nil
+		}
+	}
+
+	// Changing existing files needs its own confirmation, or --force without prompts
+	proceed, err := g.confirmOverwrites(options, g.output)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Fprintln(g.output, "Generation canceled")
+		return nil
+	}
+
+	// Generate files
+	if err := g.generateFiles(); err != nil {
+		return fmt.Errorf("failed to generate files: %w", err)
+	}
+
+	// Show CLI example if run interactively
+	if !options.SkipPrompt && g.retry == nil && g.shouldShowCLIExample(options) {
+		g.showCLIExample(g.output)
+	}
+
+	fmt.Fprintln(g.output, "generated!")
+
+	if options.DumpAnswersJSON {
+		return g.writeAnswersJSON(os.Stdout)
+	}
+	return nil
+}
+
+// collectAnswers sets the answers from the options, prompting for the remaining
+// questions unless prompts are skipped.
+func (g *Generator) collectAnswers(ctx context.Context, options *Options, presets map[string]interface{}) error {
+	// Use CLI options if skip prompt is enabled
+	if options.SkipPrompt && !options.PromptMissing {
+		if err := g.useProvidedAnswers(options, presets); err != nil {
+			return err
 		}
 	} else {
+		if options.PromptMissing {
+			// Profile answers are used as-is, like with --yes
+			options = withPresetAnswers(options, presets)
+		} else {
+			g.presets = presets
+		}
+
 		// Pre-fill answers with CLI options if provided
 		if options.Answers != nil {
 			for key, value := range options.Answers {
 				g.answers[key] = value
 			}
 		}
+		if err := g.coerceAnswers(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
 
 		// Process questions in the order defined in config
 		questionOrder := g.config.Questions.GetOrder()
@@ -93,12 +383,17 @@ func (g *Generator) RunWithOptions(options *Options) error {
 		for _, questionKey := range questionOrder {
 			select {
 			case <-ctx.Done():
-				return fmt.Errorf("operation canceled")
+				return ErrCanceled
 			default:
 			}
 
 			// Skip if already answered via CLI option
-			if _, exists := g.answers[questionKey]; exists && !options.SkipPrompt {
+			if _, exists := g.answers[questionKey]; exists {
+				continue
+			}
+
+			// The template question is irrelevant when templates are selected explicitly
+			if g.isIgnoredTemplateQuestion(questionKey) {
 				continue
 			}
 
@@ -116,38 +411,91 @@ func (g *Generator) RunWithOptions(options *Options) error {
 		}
 	}
 
-	// Generate and show preview (unless disabled)
-	previewEnabled := g.shouldShowPreview(options)
-	if previewEnabled {
-		if err := g.generatePreview(); err != nil {
-			return fmt.Errorf("failed to generate preview: %w", err)
-		}
+	return nil
+}
+
+// configure applies the options that do not depend on the answers and returns the
+// answers preset by the selected profile.
+func (g *Generator) configure(options *Options) (map[string]interface{}, error) {
+	// Select templates explicitly in all-templates mode
+	templateTypes, err := g.selectTemplates(options)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
 	}
+	g.templateTypes = templateTypes
+	g.noTemplateConfig = options.NoTemplateConfig
+	g.templateFile = options.TemplateFile
+	g.keepGoing = options.KeepGoing
+	g.strictChoices = options.StrictChoices
+	g.started = time.Now()
+
+	filters, err := parseFilters(options.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	g.filters = filters
 
-	// Confirm generation (skip if using --yes flag)
-	if !options.SkipPrompt {
-		confirmed, err := g.prompter.Confirm("Do you want to proceed with file generation?")
+	if _, err := newOutputLayout(options.OutputLayout); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	g.outputLayout = options.OutputLayout
+	g.namespace = options.NamespaceByTemplate
+	if options.Report != "" {
+		g.report = &Report{Combinations: []ReportEntry{}}
+		g.reportPath = options.Report
+	}
+	if options.RetryFrom != "" {
+		if options.Explain {
+			return nil, fmt.Errorf("%w: --retry-from cannot be combined with --explain", ErrInvalidOptions)
+		}
+		retry, err := loadReport(options.RetryFrom)
 		if err != nil {
-			return fmt.Errorf("failed to get confirmation: %w", err)
+			return nil, err
 		}
+		g.retry = retry
+	}
+	if options.TraceTemplate {
+		g.traceOutput = os.Stderr
+	}
+	if options.Verbose {
+		g.verboseOutput = os.Stderr
+	}
 
-		if !confirmed {
-			fmt.Println("Generation canceled")
-			return nil
+	// The lock is only kept with --skip-generated; --force then generates recorded
+	// combinations anyway
+	if options.SkipGenerated {
+		lock, err := loadGeneratedLock(filepath.Join(g.config.Root, ".yg", GeneratedLockFile))
+		if err != nil {
+			return nil, err
 		}
+		g.lock = lock
+		g.skipGenerated = !options.Force
 	}
 
-	// Generate files
-	if err := g.generateFiles(); err != nil {
-		return fmt.Errorf("failed to generate files: %w", err)
+	presets, err := g.profileAnswers(options.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
 	}
+	return presets, nil
+}
 
-	// Show CLI example if run interactively
-	if !options.SkipPrompt {
-		g.showCLIExample()
+// useProvidedAnswers sets the answers from the options without prompting. Profile
+// answers are used as-is, with explicit answers taking precedence.
+func (g *Generator) useProvidedAnswers(options *Options, presets map[string]interface{}) error {
+	options = withPresetAnswers(options, presets)
+	if err := g.validateOptions(options); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	// Copy all provided answers
+	for key, value := range options.Answers {
+		g.answers[key] = value
+	}
+	if err := g.coerceAnswers(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	if err := g.applyInheritedAnswers(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
 	}
-
-	fmt.Println("generated!")
 	return nil
 }
 
@@ -156,10 +504,23 @@ func (g *Generator) validateOptions(options *Options) error {
 		return fmt.Errorf("answers map is required")
 	}
 
-	// Validate that all required questions have answers
+	// Validate that all required questions have answers. Like the interactive
+	// prompts, only the questions listed in order are required.
 	questions := g.config.Questions.GetQuestions()
-	for questionKey := range questions {
+	for _, questionKey := range g.config.Questions.GetOrder() {
+		if _, defined := questions[questionKey]; !defined {
+			return fmt.Errorf("question %s not found in config", questionKey)
+		}
+		if g.isIgnoredTemplateQuestion(questionKey) {
+			continue
+		}
 		if _, exists := options.Answers[questionKey]; !exists {
+			// Inherited answers are filled in once the provided answers are known
+			if source := questions[questionKey].DefaultFrom; source != "" {
+				if _, inherited := options.Answers[source]; inherited {
+					continue
+				}
+			}
 			return fmt.Errorf("answer for question '%s' is required", questionKey)
 		}
 	}
@@ -167,26 +528,199 @@ func (g *Generator) validateOptions(options *Options) error {
 	return nil
 }
 
-// determineTemplateAndMultiValues determines which question provides the template type and which are multi-value.
-func (g *Generator) determineTemplateAndMultiValues() (string, map[string][]string, error) {
+// profileAnswers returns the answers of the named profile, splitting comma-separated
+// values of multi-select questions. An empty name returns no answers.
+func (g *Generator) profileAnswers(name string) (map[string]interface{}, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	answers, err := g.config.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+
 	questions := g.config.Questions.GetQuestions()
-	multiValueQuestions := make(map[string][]string)
-	var templateType string
+	for key, value := range answers {
+		question, exists := questions[key]
+		if !exists {
+			return nil, fmt.Errorf("profile '%s' answers unknown question '%s'", name, key)
+		}
+		if str, ok := value.(string); ok && question.IsMultiple() {
+			answers[key] = strings.Split(str, ",")
+		}
+	}
+	return answers, nil
+}
+
+// withPresetAnswers returns a copy of options whose answers are the presets
+// overridden by the explicitly provided answers.
+func withPresetAnswers(options *Options, presets map[string]interface{}) *Options {
+	if len(presets) == 0 {
+		return options
+	}
+
+	merged := *options
+	merged.Answers = make(map[string]interface{}, len(presets)+len(options.Answers))
+	for key, value := range presets {
+		merged.Answers[key] = value
+	}
+	for key, value := range options.Answers {
+		merged.Answers[key] = value
+	}
+	return &merged
+}
+
+// coerceAnswers converts the provided answers of numeric questions to int, checking
+// that they are in range, and those of boolean questions to bool.
+func (g *Generator) coerceAnswers() error {
+	for questionKey, question := range g.config.Questions.GetQuestions() {
+		answer, exists := g.answers[questionKey]
+		if !exists {
+			continue
+		}
+
+		var value interface{}
+		var err error
+		switch {
+		case question.IsNumber():
+			value, err = question.ParseNumber(answer)
+		case question.IsBool():
+			value, err = question.ParseBool(answer)
+		case question.IsKeyValue():
+			value, err = question.ParseKeyValue(answer)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("invalid answer for question '%s': %w", questionKey, err)
+		}
+		g.answers[questionKey] = value
+	}
+	return nil
+}
+
+// applyInheritedAnswers answers unanswered questions with the default inherited
+// from their default_from question, in question order.
+func (g *Generator) applyInheritedAnswers() error {
+	questions := g.config.Questions.GetQuestions()
+	for _, questionKey := range g.config.Questions.GetOrder() {
+		question, exists := questions[questionKey]
+		if !exists || question.DefaultFrom == "" {
+			continue
+		}
+		if _, answered := g.answers[questionKey]; answered {
+			continue
+		}
+
+		choices, err := question.GetChoices(g.answers)
+		if err != nil {
+			return fmt.Errorf("failed to get choices for %s: %w", questionKey, err)
+		}
+
+		inherited := question.InheritedDefault(g.answers, choices)
+		if len(inherited) == 0 {
+			return fmt.Errorf(
+				"answer for question '%s' is required: the answer to '%s' is not one of its choices",
+				questionKey, question.DefaultFrom,
+			)
+		}
 
-	// First, collect all multi-value questions
-	for questionKey, question := range questions {
-		answer := g.answers[questionKey]
 		if question.IsMultiple() {
-			// This is a multi-value question
-			if strSlice, ok := answer.([]string); ok {
-				multiValueQuestions[questionKey] = strSlice
+			g.answers[questionKey] = inherited
+		} else {
+			g.answers[questionKey] = inherited[0]
+		}
+	}
+
+	return nil
+}
+
+// selectTemplates returns the templates selected via options, or nil when the
+// template should be determined from the answers.
+func (g *Generator) selectTemplates(options *Options) ([]string, error) {
+	if options.NoTemplateConfig && (options.AllTemplates || len(options.Templates) > 0) {
+		return nil, fmt.Errorf("--no-template-config cannot be combined with --all-templates or --templates")
+	}
+
+	// A template file is rendered on its own, whatever the template question answers
+	if options.TemplateFile != "" {
+		if options.AllTemplates || len(options.Templates) > 0 || options.NoTemplateConfig {
+			return nil, fmt.Errorf("--template-file cannot be combined with --all-templates, --templates or --no-template-config")
+		}
+		return []string{options.TemplateFile}, nil
+	}
+
+	if len(options.Templates) > 0 {
+		for _, name := range options.Templates {
+			if _, exists := g.config.Templates[name]; !exists {
+				return nil, fmt.Errorf("template '%s' not found in templates config", name)
 			}
 		}
+		return options.Templates, nil
+	}
+
+	if !options.AllTemplates {
+		return nil, nil
+	}
+
+	if len(g.config.Templates) == 0 {
+		return nil, fmt.Errorf("no templates configured for all-templates mode")
+	}
+
+	names := make([]string, 0, len(g.config.Templates))
+	for name := range g.config.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isIgnoredTemplateQuestion reports whether questionKey is the template question
+// while templates are selected explicitly.
+func (g *Generator) isIgnoredTemplateQuestion(questionKey string) bool {
+	return len(g.templateTypes) > 0 && questionKey == g.config.Questions.GetTemplateQuestion()
+}
+
+// collectMultiValues collects the answers of all multi-value questions.
+func (g *Generator) collectMultiValues() map[string][]string {
+	multiValueQuestions := make(map[string][]string)
+	for questionKey, question := range g.config.Questions.GetQuestions() {
+		if !question.IsMultiple() {
+			continue
+		}
+		if strSlice, ok := g.answers[questionKey].([]string); ok {
+			multiValueQuestions[questionKey] = strSlice
+		}
+	}
+	return multiValueQuestions
+}
+
+// determineTemplateAndMultiValues determines which question provides the template type and which are multi-value.
+func (g *Generator) determineTemplateAndMultiValues() (string, map[string][]string, error) {
+	questions := g.config.Questions.GetQuestions()
+	multiValueQuestions := g.collectMultiValues()
+	var templateType string
+
+	// The first matching template_when rule takes precedence
+	rule, err := g.matchTemplateRule()
+	if err != nil {
+		return "", nil, err
 	}
 
 	// Determine template type based on configuration or heuristics
+	templateName := g.config.Questions.GetTemplateName()
 	templateQuestionKey := g.config.Questions.GetTemplateQuestion()
-	if templateQuestionKey != "" {
+	if rule >= 0 {
+		templateType = g.config.Questions.TemplateWhen[rule].Template
+	} else if templateName != "" {
+		// Compose template name from answers
+		name, err := g.renderTemplateName(templateName)
+		if err != nil {
+			return "", nil, err
+		}
+		templateType = name
+	} else if templateQuestionKey != "" {
 		// Use configured template question
 		answer, exists := g.answers[templateQuestionKey]
 		if !exists {
@@ -226,6 +760,363 @@ func (g *Generator) determineTemplateAndMultiValues() (string, map[string][]stri
 	return templateType, multiValueQuestions, nil
 }
 
+// matchTemplateRule returns the index of the first template_when rule whose condition
+// holds for the answers, or -1 if none does.
+func (g *Generator) matchTemplateRule() (int, error) {
+	data := &template.Data{Questions: g.answers, Data: g.data}
+	for i, rule := range g.config.Questions.TemplateWhen {
+		matched, err := template.EvaluateCondition(fmt.Sprintf("template_when %d", i+1), rule.When, data)
+		if err != nil {
+			return -1, fmt.Errorf("failed to evaluate template_when rule %d: %w", i+1, err)
+		}
+		if matched {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// templateSource describes where the template type decision comes from.
+func (g *Generator) templateSource() string {
+	if len(g.templateTypes) > 0 {
+		return "explicit template selection (--all-templates/--templates)"
+	}
+	if rule, err := g.matchTemplateRule(); err == nil && rule >= 0 {
+		return fmt.Sprintf("template_when rule %d (%s)", rule+1, g.config.Questions.TemplateWhen[rule].When)
+	}
+	if key := g.config.Questions.GetTemplateName(); key != "" {
+		return fmt.Sprintf("template_name %q", key)
+	}
+	if key := g.config.Questions.GetTemplateQuestion(); key != "" {
+		return fmt.Sprintf("template_question %q", key)
+	}
+	return "heuristic (first single-value question in order)"
+}
+
+// explain writes the resolved template type, its source, the multi-value questions
+// and the enumerated combinations to w.
+func (g *Generator) explain(w io.Writer) error {
+	templateTypes, multiValueQuestions, err := g.determineTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to determine template and multi-values: %w", err)
+	}
+
+	fmt.Fprintf(w, "Template: %s\n", strings.Join(templateTypes, ", "))
+	fmt.Fprintf(w, "Source: %s\n", g.templateSource())
+
+	multiKeys := g.orderedKeys(multiValueQuestions)
+
+	fmt.Fprintln(w, "Multi-value questions:")
+	if len(multiKeys) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, key := range multiKeys {
+		fmt.Fprintf(w, "  %s: %s\n", key, strings.Join(multiValueQuestions[key], ", "))
+	}
+
+	combinations, err := g.resolveCombinations(multiValueQuestions)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Combinations (%d):\n", len(combinations))
+	for i, combination := range combinations {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, describeCombination(combination, multiKeys))
+	}
+
+	return nil
+}
+
+// listCombinations writes the answers of each combination that would be generated to w,
+// one combination per line as key=value pairs, without loading or rendering templates.
+// Secret answers are redacted.
+func (g *Generator) listCombinations(w io.Writer) error {
+	_, multiValueQuestions, err := g.determineTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to determine template and multi-values: %w", err)
+	}
+	combinations, err := g.resolveCombinations(multiValueQuestions)
+	if err != nil {
+		return err
+	}
+
+	questions := g.config.Questions.GetQuestions()
+	for _, combination := range combinations {
+		answered := make(map[string][]string, len(combination))
+		for key := range combination {
+			answered[key] = nil
+		}
+		parts := make([]string, 0, len(combination))
+		for _, key := range g.orderedKeys(answered) {
+			value := combination[key]
+			if question, exists := questions[key]; exists && question.IsSecret() {
+				value = SecretPlaceholder
+			} else if values, ok := value.([]string); ok {
+				value = strings.Join(values, ",")
+			} else if pairs, ok := value.(map[string]string); ok {
+				value = config.FormatKeyValue(pairs)
+			}
+			parts = append(parts, fmt.Sprintf("%s=%v", key, value))
+		}
+		fmt.Fprintln(w, strings.Join(parts, " "))
+	}
+	return nil
+}
+
+// orderedKeys returns the keys of the multi-value questions in config order, followed
+// by any keys missing from the order in sorted order.
+func (g *Generator) orderedKeys(multiValueQuestions map[string][]string) []string {
+	keys := make([]string, 0, len(multiValueQuestions))
+	seen := make(map[string]bool, len(multiValueQuestions))
+	for _, key := range g.config.Questions.GetOrder() {
+		if _, exists := multiValueQuestions[key]; exists && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	var rest []string
+	for key := range multiValueQuestions {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// describeCombination formats the multi-value answers of a combination as key=value pairs.
+func describeCombination(combination map[string]interface{}, keys []string) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, combination[key]))
+	}
+	if len(parts) == 0 {
+		return "(single combination)"
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderTemplateName renders the configured template_name string using the answers as data.
+func (g *Generator) renderTemplateName(templateName string) (string, error) {
+	tmpl, err := gotemplate.New("template_name").Option("missingkey=error").Parse(templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template_name '%s': %w", templateName, err)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, g.answers); err != nil {
+		return "", fmt.Errorf("failed to render template_name '%s': %w", templateName, err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// renderPrompt renders a question prompt using the answers given so far as data.
+func (g *Generator) renderPrompt(prompt string) (string, error) {
+	if !strings.Contains(prompt, "{{") {
+		return prompt, nil
+	}
+
+	tmpl, err := gotemplate.New("prompt").Option("missingkey=error").Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt '%s': %w", prompt, err)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, g.answers); err != nil {
+		return "", fmt.Errorf("failed to render prompt '%s': %w", prompt, err)
+	}
+
+	return buf.String(), nil
+}
+
+// renderTarget pairs a loaded template with a single combination of answers.
+type renderTarget struct {
+	templateType string
+	template     *template.Template
+	combination  map[string]interface{}
+	data         map[string]interface{} // content of the configured data files
+	meta         template.Meta
+	label        string // human-readable description of the combination
+}
+
+// templateData returns the data the target's template is rendered with, for its
+// content as well as its output paths and filenames.
+func (t renderTarget) templateData() *template.Data {
+	return &template.Data{
+		Questions: t.combination,
+		Data:      t.data,
+		Values:    t.data,
+		Env:       environ(),
+		Meta:      t.meta,
+	}
+}
+
+// render renders the target's template with its combination of answers.
+func (t renderTarget) render() (*template.RenderResult, error) {
+	renderResult, err := t.template.Render(t.templateData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", t.templateType, err)
+	}
+	return renderResult, nil
+}
+
+// trace writes the template data of target to the trace output as JSON, with secret
+// answers redacted. It does nothing unless tracing is enabled.
+func (g *Generator) trace(target renderTarget) {
+	if g.traceOutput == nil {
+		return
+	}
+
+	questions := g.config.Questions.GetQuestions()
+	redacted := make(map[string]interface{}, len(target.combination))
+	for key, value := range target.combination {
+		if question, exists := questions[key]; exists && question.IsSecret() {
+			value = SecretPlaceholder
+		}
+		redacted[key] = value
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{"Questions": redacted}, "", "  ")
+	if err != nil {
+		fmt.Fprintf(g.traceOutput, "trace: failed to encode template data for %s: %v\n", target.label, err)
+		return
+	}
+	fmt.Fprintf(g.traceOutput, "trace: %s\n%s\n", target.label, data)
+}
+
+// templateMeta returns the generation metadata templateType is rendered with.
+func (g *Generator) templateMeta(templateType string) template.Meta {
+	if g.started.IsZero() {
+		g.started = time.Now()
+	}
+	return template.Meta{
+		Template:  templateType,
+		Timestamp: g.started.UTC().Format(template.TimestampFormat),
+	}
+}
+
+// environ returns the environment variables of the process as a map.
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, found := strings.Cut(entry, "="); found {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// resolveTargets determines the templates to render and the combinations to render them with.
+func (g *Generator) resolveTargets() ([]renderTarget, error) {
+	if g.retry != nil {
+		return g.retryTargets()
+	}
+
+	// Determine template types and multi-value questions
+	templateTypes, multiValueQuestions, err := g.determineTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine template and multi-values: %w", err)
+	}
+
+	// Generate all combinations for multi-value questions
+	combinations, err := g.resolveCombinations(multiValueQuestions)
+	if err != nil {
+		return nil, err
+	}
+
+	multiKeys := g.orderedKeys(multiValueQuestions)
+
+	var targets []renderTarget
+	for _, templateType := range templateTypes {
+		// Templates are loaded once per variant, the answer of the template_variant question
+		variants := make(map[string]*template.Template)
+		for _, combination := range combinations {
+			variant := g.templateVariant(combination)
+			tmpl, loaded := variants[variant]
+			if !loaded {
+				tmpl, err = g.loadTemplate(templateType, variant)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load template: %w", err)
+				}
+				variants[variant] = tmpl
+			}
+
+			targets = append(targets, renderTarget{
+				templateType: templateType,
+				template:     tmpl,
+				combination:  combination,
+				data:         g.data,
+				meta:         g.templateMeta(templateType),
+				label:        templateType + ": " + describeCombination(combination, multiKeys),
+			})
+		}
+	}
+
+	return targets, nil
+}
+
+// loadTemplate loads the named template or its variant, as a single file template only
+// when the templates config section is skipped. A template file given in the options is
+// loaded instead.
+func (g *Generator) loadTemplate(templateType, variant string) (*template.Template, error) {
+	var tmpl *template.Template
+	var err error
+	switch {
+	case g.templateFile != "":
+		tmpl, err = template.LoadFileTemplateAt(g.templateFile)
+	case g.noTemplateConfig:
+		tmpl, err = template.LoadFileTemplateVariantFrom(g.config.Root, templateType, variant)
+	default:
+		tmpl, err = template.LoadConfiguredTemplate(g.config.Root, g.templateEntries(), templateType, variant)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl.TrimBlocks = g.config.Output != nil && g.config.Output.TrimBlocks
+	return tmpl, nil
+}
+
+// templateEntries returns the templates config section of the loaded config, whichever
+// file it was loaded from.
+func (g *Generator) templateEntries() map[string]template.ConfigEntry {
+	entries := make(map[string]template.ConfigEntry, len(g.config.Templates))
+	for name, entry := range g.config.Templates {
+		entries[name] = template.ConfigEntry(entry)
+	}
+	return entries
+}
+
+// templateVariant returns the answer of the template_variant question in combination,
+// or an empty string when there is none or it cannot name a template file.
+func (g *Generator) templateVariant(combination map[string]interface{}) string {
+	key := g.config.Questions.TemplateVariant
+	if key == "" {
+		return ""
+	}
+	variant, _ := combination[key].(string)
+	if strings.ContainsAny(variant, `/\`) || strings.Contains(variant, "..") {
+		return ""
+	}
+	return variant
+}
+
+// determineTemplates returns the template types to render and the multi-value questions.
+// When templates were selected explicitly (e.g. --all-templates), those are used instead
+// of the template determined from the answers.
+func (g *Generator) determineTemplates() ([]string, map[string][]string, error) {
+	if len(g.templateTypes) > 0 {
+		return g.templateTypes, g.collectMultiValues(), nil
+	}
+
+	templateType, multiValueQuestions, err := g.determineTemplateAndMultiValues()
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{templateType}, multiValueQuestions, nil
+}
+
 // generateCombinations generates all combinations of multi-value questions with single-value answers.
 func (g *Generator) generateCombinations(multiValueQuestions map[string][]string) []map[string]interface{} {
 	if len(multiValueQuestions) == 0 {
@@ -233,22 +1124,76 @@ func (g *Generator) generateCombinations(multiValueQuestions map[string][]string
 		return []map[string]interface{}{g.copyAnswers()}
 	}
 
-	// Process hierarchical selections by parsing formatted choices
-	processedQuestions := g.parseHierarchicalSelections(multiValueQuestions)
-
-	// Extract keys and values for combination generation
-	keys := make([]string, 0, len(processedQuestions))
-	values := make([][]map[string]string, 0, len(processedQuestions))
+	// Process hierarchical selections by parsing formatted choices
+	processedQuestions := g.parseHierarchicalSelections(multiValueQuestions)
+
+	// Extract keys and values for combination generation, in config order so that
+	// combinations are generated in a stable sequence
+	keys := g.orderedKeys(multiValueQuestions)
+	values := make([][]map[string]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, processedQuestions[key])
+	}
+
+	var combinations []map[string]interface{}
+	g.generateHierarchicalCombinationsRecursive(keys, values, 0, make(map[string]string), &combinations)
+
+	return combinations
+}
+
+// resolveCombinations generates all combinations, keeps those matching the CLI filters
+// and drops those matching the configured skip_when condition.
+func (g *Generator) resolveCombinations(multiValueQuestions map[string][]string) ([]map[string]interface{}, error) {
+	combinations := filterCombinations(g.generateCombinations(multiValueQuestions), g.filters)
+	if g.config.SkipWhen == "" {
+		return combinations, nil
+	}
+
+	result := make([]map[string]interface{}, 0, len(combinations))
+	for _, combination := range combinations {
+		skip, err := template.EvaluateCondition("skip_when", g.config.SkipWhen, &template.Data{Questions: combination, Data: g.data})
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate skip_when condition: %w", err)
+		}
+		if !skip {
+			result = append(result, combination)
+		}
+	}
+
+	return result, nil
+}
 
-	for key, vals := range processedQuestions {
-		keys = append(keys, key)
-		values = append(values, vals)
+// checkSelectionCounts checks the number of selections of every answered multi-select
+// question against its min_select and max_select.
+func (g *Generator) checkSelectionCounts() error {
+	questions := g.config.Questions.GetQuestions()
+	for _, questionKey := range g.config.Questions.GetOrder() {
+		question := questions[questionKey]
+		selected, answered := g.answers[questionKey].([]string)
+		if !answered || !question.IsMultiple() {
+			continue
+		}
+		if err := question.CheckSelectionCount(len(selected)); err != nil {
+			return fmt.Errorf("invalid answer for question '%s': %w", questionKey, err)
+		}
 	}
+	return nil
+}
 
-	var combinations []map[string]interface{}
-	g.generateHierarchicalCombinationsRecursive(keys, values, 0, make(map[string]string), &combinations)
-
-	return combinations
+// checkValidations evaluates the configured validation rules against all answers and
+// returns the message of the first rule that does not hold.
+func (g *Generator) checkValidations() error {
+	data := &template.Data{Questions: g.answers, Data: g.data}
+	for i, validation := range g.config.Validations {
+		valid, err := template.EvaluateCondition(fmt.Sprintf("validation %d", i+1), validation.Rule, data)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate validation %d: %w", i+1, err)
+		}
+		if !valid {
+			return errors.New(validation.Message)
+		}
+	}
+	return nil
 }
 
 func (g *Generator) copyAnswers() map[string]interface{} {
@@ -329,149 +1274,657 @@ func (g *Generator) generateHierarchicalCombinationsRecursive(
 	}
 
 	for _, valueMap := range values[index] {
-		// Add all values from this valueMap to current
+		// A child paired with another parent value than the one already chosen belongs
+		// to a different combination
+		if !consistentSelection(current, valueMap) {
+			continue
+		}
+
+		// Add the values from this valueMap that are not set yet
+		var added []string
 		for k, v := range valueMap {
-			current[k] = v
+			if _, exists := current[k]; !exists {
+				current[k] = v
+				added = append(added, k)
+			}
 		}
 
 		g.generateHierarchicalCombinationsRecursive(keys, values, index+1, current, result)
 
 		// Backtrack - remove the values we just added
-		for k := range valueMap {
+		for _, k := range added {
 			delete(current, k)
 		}
 	}
 }
 
-func (g *Generator) askQuestion(_ string, question config.Question) (interface{}, error) {
+// consistentSelection reports whether the values agree with those already in current.
+func consistentSelection(current, values map[string]string) bool {
+	for k, v := range values {
+		if existing, exists := current[k]; exists && existing != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *Generator) askQuestion(questionKey string, question config.Question) (interface{}, error) {
+	// Echoed answers are labeled with the question key; the pairs of a keyvalue
+	// question keep the labels of their own prompts
+	if questioner, ok := g.prompter.(prompt.Questioner); ok && !question.IsKeyValue() {
+		questioner.SetQuestion(questionKey)
+		defer questioner.SetQuestion("")
+	}
+
+	message, err := g.renderPrompt(question.Prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	if question.IsSecret() {
+		return g.prompter.Password(message)
+	}
+
+	if question.IsNumber() {
+		input, err := g.prompter.Input(message, func(value string) error {
+			_, err := question.ParseNumber(value)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return question.ParseNumber(input)
+	}
+
+	if question.IsBool() {
+		defaultValue := false
+		if len(question.Default) > 0 {
+			defaultValue, _ = question.ParseBool(question.Default[0])
+		}
+		return g.prompter.Confirm(message, defaultValue)
+	}
+
+	if question.IsKeyValue() {
+		return g.askKeyValue(message)
+	}
+
 	choices, err := question.GetChoices(g.answers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get choices: %w", err)
 	}
 
+	defaults, err := question.GetDefaults(choices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default: %w", err)
+	}
+
+	// A value read from a file takes precedence over the configured default
+	fileDefault, err := question.FileDefault()
+	if err != nil {
+		return nil, err
+	}
+	if fromFile := config.FilterChoices(fileDefault, choices); fileDefault != "" && len(fromFile) > 0 {
+		defaults = fromFile
+	}
+
+	// A default inherited from an earlier answer takes precedence, and a profile answer over both
+	if inherited := question.InheritedDefault(g.answers, choices); len(inherited) > 0 {
+		defaults = inherited
+	}
+	if preset := g.presetDefault(questionKey, choices); len(preset) > 0 {
+		defaults = preset
+	}
+
+	// Questions without choices take their default without prompting
+	if len(choices) == 0 {
+		if len(defaults) == 0 {
+			if question.Type != nil && question.Type.Dynamic != nil {
+				return nil, fmt.Errorf("question %s has no choices for %s", questionKey,
+					g.describeDependencyAnswers(question.Type.Dynamic.DependencyQuestions))
+			}
+			return nil, fmt.Errorf("question %s has no choices and no default", questionKey)
+		}
+		if question.IsMultiple() {
+			return defaults, nil
+		}
+		return defaults[0], nil
+	}
+
+	// A sole choice is selected without prompting when the question opts in
+	if question.AutoSelectSingle && len(choices) == 1 {
+		if !question.IsMultiple() {
+			g.logf("Selected %s for %s (only choice)", choices[0], questionKey)
+			return choices[0], nil
+		}
+		if question.CheckSelectionCount(1) == nil {
+			g.logf("Selected %s for %s (only choice)", choices[0], questionKey)
+			return choices, nil
+		}
+	}
+
+	g.describeChoices(question, choices)
+
 	if question.IsMultiple() {
-		return g.prompter.MultiSelect(question.Prompt, choices)
+		// Ask again until the number of selections is within min_select and max_select
+		for {
+			selected, err := g.askMultiSelect(message, choices, defaults)
+			if err != nil {
+				return nil, err
+			}
+			countErr := question.CheckSelectionCount(len(selected))
+			if countErr == nil {
+				return selected, nil
+			}
+			fmt.Fprintf(g.output, "%v, please select again\n", countErr)
+		}
+	}
+
+	var defaultValue string
+	if len(defaults) > 0 {
+		defaultValue = defaults[0]
 	}
 
 	if question.Type != nil && question.Type.Interactive {
-		return g.prompter.Search(question.Prompt, choices)
+		return g.askSearch(message, choices, defaultValue)
 	}
 
-	return g.prompter.Select(question.Prompt, choices)
+	return g.prompter.Select(message, choices, defaultValue)
 }
 
-func (g *Generator) generatePreview() error {
-	fmt.Println("\nOutput:")
-	fmt.Println()
-
-	// Determine template type and multi-value questions
-	templateType, multiValueQuestions, err := g.determineTemplateAndMultiValues()
+// askKeyValue asks for key=value pairs, one key and value at a time, until no other
+// pair is to be added.
+func (g *Generator) askKeyValue(message string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	more, err := g.prompter.Confirm(message+" Add a key=value pair?", true)
+	for err == nil && more {
+		var key, value string
+		key, err = g.prompter.Input("Key:", func(key string) error {
+			if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "=,") {
+				return fmt.Errorf("keys must not be empty or contain = or ,")
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		value, err = g.prompter.Input(fmt.Sprintf("Value of %s:", key), func(value string) error {
+			if strings.Contains(value, ",") {
+				return fmt.Errorf("values must not contain ,")
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		more, err = g.prompter.Confirm("Add another key=value pair?", false)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to determine template and multi-values: %w", err)
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// describeDependencyAnswers formats the answers to the given questions as key=value pairs.
+func (g *Generator) describeDependencyAnswers(dependencies []string) string {
+	parts := make([]string, 0, len(dependencies))
+	for _, dep := range dependencies {
+		parts = append(parts, fmt.Sprintf("%s=%v", dep, g.answers[dep]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// logf writes a diagnostic line in verbose mode.
+func (g *Generator) logf(format string, args ...interface{}) {
+	if g.verboseOutput == nil {
+		return
+	}
+	fmt.Fprintf(g.verboseOutput, format+"\n", args...)
+}
+
+// describeChoices passes the descriptions of choices to the prompter, when it can show them.
+func (g *Generator) describeChoices(question config.Question, choices []string) {
+	describer, ok := g.prompter.(prompt.Describer)
+	if !ok {
+		return
+	}
+
+	descriptions := question.ChoiceDescriptions()
+	result := make(map[string]string)
+	for _, choice := range choices {
+		description, exists := descriptions[choice]
+		if !exists {
+			// Hierarchical choices are formatted as "parent: choice"
+			if _, child, found := strings.Cut(choice, ": "); found {
+				description = descriptions[child]
+			}
+		}
+		if description != "" {
+			result[choice] = description
+		}
+	}
+	describer.SetDescriptions(result)
+}
+
+// askSearch asks a search question. In strict choices mode, values that are not one of
+// the choices are rejected and the question is asked again.
+func (g *Generator) askSearch(message string, choices []string, defaultValue string) (string, error) {
+	for {
+		value, err := g.prompter.Search(message, choices, defaultValue)
+		if err != nil || !g.strictChoices || slices.Contains(choices, value) {
+			return value, err
+		}
+		fmt.Fprintf(g.output, "%q is not one of the choices, please select one from the list\n", value)
+	}
+}
+
+// SecretPlaceholder replaces secret answers in output such as the CLI example.
+const SecretPlaceholder = "<secret>"
+
+// SelectAllOption is the pseudo-option offered at the top of multi-select lists to select every option.
+const SelectAllOption = "[All]"
+
+// askMultiSelect asks a multi-select question, offering SelectAllOption when there
+// is more than one choice and expanding it to every choice in the answer.
+func (g *Generator) askMultiSelect(message string, choices, defaults []string) ([]string, error) {
+	if len(choices) < 2 {
+		return g.prompter.MultiSelect(message, choices, defaults)
 	}
 
-	tmpl, err := template.LoadTemplate(templateType)
+	options := append([]string{SelectAllOption}, choices...)
+	selected, err := g.prompter.MultiSelect(message, options, defaults)
 	if err != nil {
-		return fmt.Errorf("failed to load template: %w", err)
+		return nil, err
 	}
 
-	// Generate all combinations for multi-value questions
-	combinations := g.generateCombinations(multiValueQuestions)
+	for _, value := range selected {
+		if value == SelectAllOption {
+			return choices, nil
+		}
+	}
+	return selected, nil
+}
 
-	for _, combination := range combinations {
-		// Create template data for this combination
-		templateData := &template.Data{
-			Questions: combination,
+// presetDefault returns the profile answer for questionKey, keeping only available choices.
+func (g *Generator) presetDefault(questionKey string, choices []string) []string {
+	return config.FilterChoices(g.presets[questionKey], choices)
+}
+
+// generatePreview writes the files that would be generated to w, with their content verbatim.
+func (g *Generator) generatePreview(w io.Writer) error {
+	fmt.Fprintln(w, "\nOutput:")
+	fmt.Fprintln(w)
+
+	targets, _, err := g.renderTargets()
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if target.err != nil {
+			if !g.keepGoing {
+				return target.err
+			}
+			fmt.Fprintf(w, "! %s: %v\n\n", target.label, target.err)
+			continue
 		}
 
-		renderResult, err := tmpl.Render(templateData)
-		if err != nil {
-			return fmt.Errorf("failed to render template: %w", err)
+		// Every file may be disabled for this combination
+		if len(target.files) == 0 {
+			fmt.Fprintf(w, "- no files generated for %s (all files are disabled)\n\n", target.label)
+			continue
 		}
 
 		// Show preview for all files in the result
-		for _, file := range renderResult.Files {
+		for _, file := range target.files {
+			file, err := g.mergeExisting(file)
+			if err != nil {
+				return err
+			}
 			fullPath := filepath.Join(file.Path, file.Filename)
-			fmt.Printf("* %s\n\n", fullPath)
+			fmt.Fprintf(w, "* %s\n\n", fullPath)
+			writePreviewContent(w, file.Content)
+			fmt.Fprintln(w)
+		}
+	}
 
-			// Show the rendered content preview
-			lines := strings.Split(file.Content, "\n")
-			for _, line := range lines {
-				if line != "" {
-					fmt.Printf("%s\n", line)
-				}
-			}
-			fmt.Println()
+	return nil
+}
+
+// writePreviewContent writes content with a line-number gutter, keeping blank lines
+// so that the preview matches the written file.
+func writePreviewContent(w io.Writer, content string) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for i, line := range lines {
+		// Empty lines get no space after the gutter; other lines are kept as they are
+		if line == "" {
+			fmt.Fprintf(w, "%4d |\n", i+1)
+			continue
+		}
+		fmt.Fprintf(w, "%4d | %s\n", i+1, line)
+	}
+}
+
+// count returns the number of combinations and files that would be generated,
+// without rendering the template content.
+func (g *Generator) count() (int, int, error) {
+	targets, _, err := g.pendingTargets()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	files := 0
+	for _, target := range targets {
+		n, err := target.template.FileCount(target.templateData())
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to count files for %s: %w", target.label, err)
 		}
+		files += n
+	}
+
+	return len(targets), files, nil
+}
+
+// printCount writes the combination and file counts to w.
+func (g *Generator) printCount(w io.Writer) error {
+	combinations, files, err := g.count()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Combinations: %d\n", combinations)
+	fmt.Fprintf(w, "Files: %d\n", files)
+	return nil
+}
+
+// checkMaxCombinations returns an ErrInvalidOptions error when more combinations would
+// be rendered than allowed by options or config.
+func (g *Generator) checkMaxCombinations(options *Options) error {
+	limit := g.config.MaxCombinations
+	if options.MaxCombinations > 0 {
+		limit = options.MaxCombinations
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	targets, _, err := g.pendingTargets()
+	if err != nil {
+		return err
 	}
 
+	if len(targets) > limit {
+		return fmt.Errorf(
+			"%w: %d combinations exceed the limit of %d; narrow them with --filter or fewer selections, or raise --max-combinations",
+			ErrInvalidOptions, len(targets), limit,
+		)
+	}
 	return nil
 }
 
+// warnLargeGeneration writes a warning to w when the number of files to generate
+// exceeds the configured threshold. Counting errors are left to preview and generation to report.
+func (g *Generator) warnLargeGeneration(w io.Writer) {
+	combinations, files, err := g.count()
+	if err != nil {
+		return
+	}
+
+	if threshold := g.warnAbove(); files > threshold {
+		fmt.Fprintf(w, "Warning: %d files will be generated from %d combinations (more than %d)\n",
+			files, combinations, threshold)
+	}
+}
+
+// warnAbove returns the file count threshold for the large generation warning.
+func (g *Generator) warnAbove() int {
+	if g.config.Confirm != nil && g.config.Confirm.WarnAbove > 0 {
+		return g.config.Confirm.WarnAbove
+	}
+	return DefaultWarnAbove
+}
+
+// confirmMessage returns the generation confirmation message from config or the default.
+func (g *Generator) confirmMessage() string {
+	if g.config.Confirm != nil && g.config.Confirm.Message != "" {
+		return g.config.Confirm.Message
+	}
+	return "Do you want to proceed with file generation?"
+}
+
+// resolveBool returns the first setting that is set: the CLI flag, then the config,
+// then the built-in fallback.
+func resolveBool(flag, configured *bool, fallback bool) bool {
+	if flag != nil {
+		return *flag
+	}
+	if configured != nil {
+		return *configured
+	}
+	return fallback
+}
+
+// falseIf returns a pointer to false when condition holds, and nil (unset) otherwise.
+// It maps one-way settings such as --no-preview to an optional boolean.
+func falseIf(condition bool) *bool {
+	if !condition {
+		return nil
+	}
+	disabled := false
+	return &disabled
+}
+
+// confirmDefault determines the confirmation default based on config and CLI options.
+// It defaults to not proceeding.
+func (g *Generator) confirmDefault(options *Options) bool {
+	var configured *bool
+	if g.config.Confirm != nil {
+		configured = &g.config.Confirm.Default
+	}
+	return resolveBool(options.ConfirmDefault, configured, false)
+}
+
+// shouldShowCLIExample determines if the CLI example is shown after an interactive
+// generation based on config and CLI options. Defaults to true.
+func (g *Generator) shouldShowCLIExample(options *Options) bool {
+	return resolveBool(options.CLIExample, g.config.ShowCLIExample, true)
+}
+
 // shouldShowPreview determines if preview should be shown based on config and CLI options.
+// It defaults to enabled.
 func (g *Generator) shouldShowPreview(options *Options) bool {
-	// CLI option takes precedence
+	flag := options.Preview
 	if options.NoPreview {
-		return false
+		flag = falseIf(true)
 	}
 
-	// Check config setting
+	var configured *bool
 	if g.config.Preview != nil {
-		return g.config.Preview.Enabled
+		configured = &g.config.Preview.Enabled
 	}
+	return resolveBool(flag, configured, true)
+}
 
-	// Default to enabled if no configuration is set
-	return true
+// Color modes supported by the --color option.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// colorEnabled determines if prompts are colored based on config and CLI options.
+// In auto mode, it defaults to enabled when stdout is a terminal unless the NO_COLOR
+// environment variable is set. The always and never modes override everything else.
+func (g *Generator) colorEnabled(options *Options, terminal bool) (bool, error) {
+	switch options.Color {
+	case "", ColorAuto:
+	case ColorAlways:
+		if options.NoColor {
+			return false, errors.New("--color always cannot be combined with --no-color")
+		}
+		return true, nil
+	case ColorNever:
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid color mode %q (expected %s, %s or %s)", options.Color, ColorAuto, ColorAlways, ColorNever)
+	}
+
+	var configured *bool
+	if g.config.Theme != nil {
+		configured = falseIf(g.config.Theme.NoColor)
+	}
+	return resolveBool(falseIf(options.NoColor), configured, terminal && os.Getenv("NO_COLOR") == ""), nil
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
 func (g *Generator) generateFiles() error {
-	// Determine template type and multi-value questions
-	templateType, multiValueQuestions, err := g.determineTemplateAndMultiValues()
-	if err != nil {
-		return fmt.Errorf("failed to determine template and multi-values: %w", err)
-	}
+	// Later generations render the answers they are given again
+	defer func() { g.rendered = nil }()
 
-	tmpl, err := template.LoadTemplate(templateType)
+	targets, skipped, err := g.renderTargets()
 	if err != nil {
-		return fmt.Errorf("failed to load template: %w", err)
+		return err
+	}
+	if skipped > 0 {
+		fmt.Fprintf(g.output, "Skipping %d already generated combinations (use --force to generate them again)\n", skipped)
 	}
 
-	// Generate all combinations for multi-value questions
-	combinations := g.generateCombinations(multiValueQuestions)
+	var failures []string
+	for _, target := range targets {
+		err := g.writeTarget(target)
+		g.recordOutcome(target.renderTarget, err)
+		if err != nil {
+			if !g.keepGoing {
+				return errors.Join(err, g.saveLock(), g.saveReport())
+			}
+			// Record the failure and continue with the remaining combinations
+			failures = append(failures, fmt.Sprintf("%s: %v", target.label, err))
+			continue
+		}
+		if err := g.recordGenerated(target.renderTarget); err != nil {
+			return err
+		}
+	}
+	if err := errors.Join(g.saveLock(), g.saveReport(), g.writeIndex()); err != nil {
+		return err
+	}
 
-	for _, combination := range combinations {
-		// Create template data for this combination
-		templateData := &template.Data{
-			Questions: combination,
+	if len(failures) > 0 {
+		fmt.Fprintf(g.output, "\n%d of %d combinations failed:\n", len(failures), len(targets))
+		for _, failure := range failures {
+			fmt.Fprintf(g.output, "  - %s\n", failure)
 		}
+		return fmt.Errorf("%d of %d combinations failed", len(failures), len(targets))
+	}
+
+	return nil
+}
+
+// writeTarget writes all the rendered files of a target.
+func (g *Generator) writeTarget(target renderedTarget) error {
+	if target.err != nil {
+		return target.err
+	}
 
-		renderResult, err := tmpl.Render(templateData)
+	if len(target.files) == 0 {
+		fmt.Fprintf(g.output, "No files generated for %s (all files are disabled)\n", target.label)
+		return nil
+	}
+
+	// Check every file before writing so that an empty one leaves the target unwritten
+	files := make([]template.RenderedFile, len(target.files))
+	for i, file := range target.files {
+		if err := g.checkNotEmpty(target.renderTarget, file); err != nil {
+			return err
+		}
+		merged, err := g.mergeExisting(file)
 		if err != nil {
-			return fmt.Errorf("failed to render template: %w", err)
+			return err
+		}
+		files[i] = merged
+	}
+
+	// Write all files in the result
+	for _, file := range files {
+		if err := writeFile(file); err != nil {
+			return err
 		}
+		g.written = append(g.written, filepath.Join(file.Path, file.Filename))
+	}
 
-		// Write all files in the result
-		for _, file := range renderResult.Files {
-			// Create directory if it doesn't exist
-			if err := os.MkdirAll(file.Path, 0o755); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", file.Path, err)
-			}
+	return nil
+}
 
-			// Write file
-			fullPath := filepath.Join(file.Path, file.Filename)
-			if err := os.WriteFile(fullPath, []byte(file.Content), 0o600); err != nil {
-				return fmt.Errorf("failed to write file %s: %w", fullPath, err)
-			}
+// checkNotEmpty returns an error for a file with whitespace-only content when
+// output.disallow_empty is set.
+func (g *Generator) checkNotEmpty(target renderTarget, file template.RenderedFile) error {
+	if g.config.Output == nil || !g.config.Output.DisallowEmpty || strings.TrimSpace(file.Content) != "" {
+		return nil
+	}
+	return fmt.Errorf("template %s rendered empty content for %s", target.templateType, filepath.Join(file.Path, file.Filename))
+}
+
+// writeFile writes a rendered file, creating its directory if needed.
+func writeFile(file template.RenderedFile) error {
+	// Reject paths escaping the output root
+	if err := validateOutputPath(filepath.Join(file.Path, file.Filename)); err != nil {
+		return err
+	}
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(file.Path, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", file.Path, err)
+	}
+
+	// Write file
+	fullPath := filepath.Join(file.Path, file.Filename)
+	if err := os.WriteFile(fullPath, []byte(file.Content), 0o600); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", fullPath, err)
+	}
+	return nil
+}
+
+// normalize applies the configured output normalization to the content of file.
+func (g *Generator) normalize(file template.RenderedFile) template.RenderedFile {
+	output := g.config.Output
+	if output == nil {
+		return file
+	}
+
+	if output.TrimTrailingWhitespace {
+		lines := strings.Split(file.Content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
 		}
+		file.Content = strings.Join(lines, "\n")
+	}
+
+	if output.Normalize {
+		file.Content = strings.TrimRight(file.Content, "\n") + "\n"
 	}
 
+	return file
+}
+
+// validateOutputPath ensures that a rendered output path stays within the output root.
+func validateOutputPath(path string) error {
+	cleaned := filepath.Clean(path)
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("rendered output path %s escapes the output root", path)
+	}
 	return nil
 }
 
-// showCLIExample displays the CLI command equivalent of the interactive session.
-func (g *Generator) showCLIExample() {
-	fmt.Println("\nCLI Example:")
-	fmt.Print("yg --yes")
+// showCLIExample writes the CLI command equivalent of the interactive session to w.
+// Secret answers are redacted.
+func (g *Generator) showCLIExample(w io.Writer) {
+	fmt.Fprintln(w, "\nCLI Example:")
+	fmt.Fprint(w, "yg --yes")
 
 	// Get question order from config
 	questionOrder := g.config.Questions.GetOrder()
@@ -490,25 +1943,55 @@ func (g *Generator) showCLIExample() {
 		}
 
 		var answerStr string
-		if question.IsMultiple() {
+		if question.IsSecret() {
+			answerStr = SecretPlaceholder
+		} else if question.IsMultiple() {
 			// Handle multiple selection questions - join with comma
 			if strSlice, ok := answer.([]string); ok {
 				answerStr = strings.Join(strSlice, ",")
 			} else {
 				continue // Skip if not string slice
 			}
+		} else if pairs, ok := answer.(map[string]string); ok {
+			answerStr = config.FormatKeyValue(pairs)
 		} else {
-			// Handle single selection questions
-			if str, ok := answer.(string); ok {
-				answerStr = str
-			} else {
-				continue // Skip if not string
+			// Handle single selection and numeric questions
+			switch value := answer.(type) {
+			case string:
+				answerStr = value
+			case int:
+				answerStr = strconv.Itoa(value)
+			case bool:
+				answerStr = strconv.FormatBool(value)
+			default:
+				continue // Skip if not string, int or bool
 			}
 		}
 
-		fmt.Printf(" --answer %s=%s", questionKey, answerStr)
+		fmt.Fprintf(w, " --answer %s=%s", questionKey, answerStr)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+}
+
+// writeAnswersJSON writes the answers to w as a single-line JSON object, with
+// multi-value answers as arrays. Secret answers are left out, so that replaying the
+// answers asks for them again instead of using a placeholder.
+func (g *Generator) writeAnswersJSON(w io.Writer) error {
+	questions := g.config.Questions.GetQuestions()
+	answers := make(map[string]interface{}, len(g.answers))
+	for key, value := range g.answers {
+		if question, exists := questions[key]; exists && question.IsSecret() {
+			continue
+		}
+		answers[key] = value
 	}
 
-	fmt.Println()
-	fmt.Println()
+	data, err := json.Marshal(answers)
+	if err != nil {
+		return fmt.Errorf("failed to encode answers: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
 }
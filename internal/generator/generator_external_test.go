@@ -0,0 +1,278 @@
+package generator_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/daylight55/yg/internal/generator"
+	"github.com/daylight55/yg/internal/prompt"
+)
+
+func setupExternalTestEnvironment(t *testing.T) string {
+	tempDir := t.TempDir()
+
+	templateDir := filepath.Join(tempDir, ".yg", "_templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatalf("Failed to create temp template directory: %v", err)
+	}
+
+	configContent := `questions:
+  order:
+    - app
+    - env
+  definitions:
+    app:
+      prompt: "What type of template do you want to use?"
+      choices:
+        - deployment
+    env:
+      prompt: "Which environment do you want to target?"
+      type:
+        multiple: true
+      choices:
+        - dev
+        - staging`
+
+	configFile := filepath.Join(tempDir, ".yg", "config.yaml")
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	templateContent := `path: {{.Questions.env}}/deployment
+filename: {{.Questions.app}}.yaml
+---
+env: {{.Questions.env}}`
+
+	templateFile := filepath.Join(templateDir, "deployment.yaml")
+	if err := os.WriteFile(templateFile, []byte(templateContent), 0644); err != nil {
+		t.Fatalf("Failed to write deployment template: %v", err)
+	}
+
+	return tempDir
+}
+
+func TestGeneratorAccessors(t *testing.T) {
+	tempDir := setupExternalTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	gen, err := generator.New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &generator.Options{
+		Answers: map[string]interface{}{
+			"app": "deployment",
+			"env": []string{"dev"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+	}
+
+	if err := gen.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator with options: %v", err)
+	}
+
+	answers := gen.Answers()
+	if answers["app"] != "deployment" {
+		t.Errorf("Expected answer 'deployment' for app, got %v", answers["app"])
+	}
+
+	// Mutating the returned map must not affect the generator state
+	answers["app"] = "job"
+	if gen.Answers()["app"] != "deployment" {
+		t.Error("Answers should return a copy of the generator answers")
+	}
+
+	cfg := gen.Config()
+	if cfg == nil {
+		t.Fatal("Config should not be nil")
+	}
+
+	if len(cfg.Questions.GetOrder()) != 2 {
+		t.Errorf("Expected 2 questions in order, got %d", len(cfg.Questions.GetOrder()))
+	}
+
+	cfg.Preview = nil
+	cfg.Questions.TemplateQuestion = "env"
+	if gen.Config().Questions.TemplateQuestion != "" {
+		t.Error("Config should return a copy of the generator config")
+	}
+
+	// Nested questions are copied too
+	for key := range cfg.Questions.GetQuestions() {
+		delete(cfg.Questions.GetQuestions(), key)
+	}
+	if len(gen.Config().Questions.GetQuestions()) != 2 {
+		t.Error("Config should return a deep copy of the generator config")
+	}
+}
+
+func TestRunWithScriptedPrompter(t *testing.T) {
+	tempDir := setupExternalTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	gen, err := generator.New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	scripted := prompt.NewScriptedPrompter([]prompt.Response{
+		prompt.SelectResponse("deployment"),
+		prompt.MultiSelectResponse("dev", "staging"),
+		prompt.ConfirmResponse(true),
+	})
+	gen.SetPrompter(scripted)
+
+	if err := gen.RunWithOptions(&generator.Options{NoPreview: true}); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	if scripted.Remaining() != 0 {
+		t.Errorf("Expected all scripted responses to be used, %d remaining", scripted.Remaining())
+	}
+
+	for _, env := range []string{"dev", "staging"} {
+		expectedFile := filepath.Join(tempDir, env, "deployment", "deployment.yaml")
+		if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
+			t.Errorf("Expected file %s was not generated", expectedFile)
+		}
+	}
+}
+
+func TestRunWithScriptedPrompterExhausted(t *testing.T) {
+	tempDir := setupExternalTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	gen, err := generator.New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	gen.SetPrompter(prompt.NewScriptedPrompter([]prompt.Response{
+		prompt.SelectResponse("deployment"),
+	}))
+
+	if err := gen.RunWithOptions(&generator.Options{NoPreview: true}); err == nil {
+		t.Error("Expected error when the prompt script is exhausted")
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	tempDir := setupExternalTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	gen, err := generator.New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	combinations, err := gen.Combinations(map[string]interface{}{
+		"app": "deployment",
+		"env": []string{"dev", "staging"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to list combinations: %v", err)
+	}
+
+	expected := []map[string]interface{}{
+		{"app": "deployment", "env": "dev"},
+		{"app": "deployment", "env": "staging"},
+	}
+	if !reflect.DeepEqual(combinations, expected) {
+		t.Errorf("Expected combinations %v, got %v", expected, combinations)
+	}
+
+	// Listing combinations leaves the answers of the generator unchanged
+	if answers := gen.Answers(); len(answers) != 0 {
+		t.Errorf("Expected no answers to be collected, got %v", answers)
+	}
+
+	// Listing combinations does not generate files
+	if _, err := os.Stat(filepath.Join(tempDir, "dev")); !os.IsNotExist(err) {
+		t.Errorf("Expected no files to be generated, got err %v", err)
+	}
+}
+
+func TestRunWithStrictChoices(t *testing.T) {
+	testCases := []struct {
+		name      string
+		strict    bool
+		responses []prompt.Response
+		expected  string
+	}{
+		{
+			name:   "strict re-asks invalid values",
+			strict: true,
+			responses: []prompt.Response{
+				prompt.SelectResponse("deployment"),
+				prompt.SearchResponse("prod"),
+				prompt.SearchResponse("dev"),
+				prompt.ConfirmResponse(true),
+			},
+			expected: "dev",
+		},
+		{
+			name: "lax returns the value as is",
+			responses: []prompt.Response{
+				prompt.SelectResponse("deployment"),
+				prompt.SearchResponse("prod"),
+				prompt.ConfirmResponse(true),
+			},
+			expected: "prod",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := setupExternalTestEnvironment(t)
+			configContent := `questions:
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        interactive: true
+      choices: ["dev", "staging"]`
+			if err := os.WriteFile(filepath.Join(tempDir, ".yg", "config.yaml"), []byte(configContent), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			gen, err := generator.New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			scripted := prompt.NewScriptedPrompter(tc.responses)
+			gen.SetPrompter(scripted)
+
+			options := &generator.Options{NoPreview: true, StrictChoices: tc.strict}
+			if err := gen.RunWithOptions(options); err != nil {
+				t.Fatalf("Failed to run generator: %v", err)
+			}
+
+			if answer := gen.Answers()["env"]; answer != tc.expected {
+				t.Errorf("Expected env %s, got %v", tc.expected, answer)
+			}
+			if scripted.Remaining() != 0 {
+				t.Errorf("Expected all scripted responses to be used, %d remaining", scripted.Remaining())
+			}
+		})
+	}
+}
@@ -1,10 +1,22 @@
 package generator
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/daylight55/yg/internal/config"
+	"github.com/daylight55/yg/internal/prompt"
+	"github.com/daylight55/yg/internal/template"
 )
 
 const (
@@ -38,6 +50,15 @@ type MockPrompter struct {
 	multiSelectIndex   int
 	searchIndex        int
 	confirmIndex       int
+	confirmMessage     string
+	confirmDefault     bool
+	multiSelectDefault []string
+	selectDefault      string
+	inputResults       []string
+	inputIndex         int
+	inputErrors        []error // validation errors of rejected inputs
+	passwordResults    []string
+	passwordIndex      int
 }
 
 func (m *MockPrompter) Reset() {
@@ -47,37 +68,55 @@ func (m *MockPrompter) Reset() {
 	m.confirmIndex = 0
 }
 
-func (m *MockPrompter) Select(_ string, options []string) (string, error) {
+func (m *MockPrompter) Select(_ string, options []string, defaultValue string) (string, error) {
+	m.selectDefault = defaultValue
 	if m.selectIndex < len(m.selectResults) {
 		result := m.selectResults[m.selectIndex]
 		m.selectIndex++
 		return result, nil
 	}
+	if defaultValue != "" {
+		return defaultValue, nil
+	}
 	return options[0], nil
 }
 
-func (m *MockPrompter) MultiSelect(_ string, options []string) ([]string, error) {
+func (m *MockPrompter) MultiSelect(_ string, options []string, defaults []string) ([]string, error) {
+	m.multiSelectDefault = defaults
 	if m.multiSelectIndex < len(m.multiSelectResults) {
 		result := m.multiSelectResults[m.multiSelectIndex]
 		m.multiSelectIndex++
 		return result, nil
 	}
+	if len(defaults) > 0 {
+		return defaults, nil
+	}
+	// Return the first real option, skipping the select-all pseudo-option
+	if len(options) > 0 && options[0] == SelectAllOption {
+		options = options[1:]
+	}
 	if len(options) > 0 {
 		return options[:1], nil
 	}
 	return []string{}, nil
 }
 
-func (m *MockPrompter) Search(_ string, options []string) (string, error) {
+func (m *MockPrompter) Search(_ string, options []string, defaultValue string) (string, error) {
+	m.selectDefault = defaultValue
 	if m.searchIndex < len(m.searchResults) {
 		result := m.searchResults[m.searchIndex]
 		m.searchIndex++
 		return result, nil
 	}
+	if defaultValue != "" {
+		return defaultValue, nil
+	}
 	return options[0], nil
 }
 
-func (m *MockPrompter) Confirm(_ string) (bool, error) {
+func (m *MockPrompter) Confirm(message string, defaultValue bool) (bool, error) {
+	m.confirmMessage = message
+	m.confirmDefault = defaultValue
 	if m.confirmIndex < len(m.confirmResults) {
 		result := m.confirmResults[m.confirmIndex]
 		m.confirmIndex++
@@ -86,6 +125,29 @@ func (m *MockPrompter) Confirm(_ string) (bool, error) {
 	return true, nil
 }
 
+// Input returns the first scripted input accepted by validate, recording rejected ones.
+func (m *MockPrompter) Input(_ string, validate func(string) error) (string, error) {
+	for m.inputIndex < len(m.inputResults) {
+		result := m.inputResults[m.inputIndex]
+		m.inputIndex++
+		if err := validate(result); err != nil {
+			m.inputErrors = append(m.inputErrors, err)
+			continue
+		}
+		return result, nil
+	}
+	return "", errors.New("no valid input")
+}
+
+func (m *MockPrompter) Password(_ string) (string, error) {
+	if m.passwordIndex < len(m.passwordResults) {
+		result := m.passwordResults[m.passwordIndex]
+		m.passwordIndex++
+		return result, nil
+	}
+	return "", errors.New("no password")
+}
+
 func setupTestEnvironment(t *testing.T) string {
 	tempDir := t.TempDir()
 
@@ -394,7 +456,7 @@ func TestAskQuestionMultiSelect(t *testing.T) {
 	}
 }
 
-func TestAskQuestionInteractiveSearch(t *testing.T) {
+func TestAskQuestionMultiSelectAll(t *testing.T) {
 	tempDir := setupTestEnvironment(t)
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
@@ -404,31 +466,85 @@ func TestAskQuestionInteractiveSearch(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
-
-	// Set up generator answers to satisfy dependencies
-	generator.answers = map[string]interface{}{
-		"app": testAppTypeDeployment,
+	generator.answers = map[string]interface{}{"env": "dev"}
+	question := generator.config.Questions.GetQuestions()["cluster"]
+
+	testCases := []struct {
+		name     string
+		selected []string
+		expected string
+	}{
+		{"all only", []string{SelectAllOption}, "dev-cluster-1,dev-cluster-2,dev-cluster-3"},
+		{"all mixed with others", []string{"dev-cluster-2", SelectAllOption}, "dev-cluster-1,dev-cluster-2,dev-cluster-3"},
+		{"without all", []string{"dev-cluster-2"}, "dev-cluster-2"},
 	}
 
-	mockPrompter := &MockPrompter{
-		searchResults: []string{"sample-server-1"},
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator.prompter = &MockPrompter{multiSelectResults: [][]string{tc.selected}}
+
+			answer, err := generator.askQuestion("cluster", question)
+			if err != nil {
+				t.Fatalf("Failed to ask multi-select question: %v", err)
+			}
+
+			if answerSlice, ok := answer.([]string); !ok || strings.Join(answerSlice, ",") != tc.expected {
+				t.Errorf("Expected %s, got %v", tc.expected, answer)
+			}
+		})
 	}
-	generator.prompter = mockPrompter
+}
 
-	questions := generator.config.Questions.GetQuestions()
-	question := questions["appName"]
-	answer, err := generator.askQuestion("appName", question)
-	if err != nil {
-		t.Fatalf("Failed to ask interactive search question: %v", err)
+func TestAskMultiSelectOffersSelectAllFirst(t *testing.T) {
+	recorder := &optionsRecorder{}
+	generator := &Generator{prompter: recorder}
+
+	if _, err := generator.askMultiSelect("Env?", []string{"dev", "staging"}, nil); err != nil {
+		t.Fatalf("Failed to ask multi-select question: %v", err)
+	}
+	if strings.Join(recorder.options, ",") != SelectAllOption+",dev,staging" {
+		t.Errorf("Expected select-all pseudo-option first, got %v", recorder.options)
 	}
 
-	if answer != "sample-server-1" {
-		t.Errorf("Expected answer 'sample-server-1', got %s", answer)
+	// A single choice needs no select-all option
+	if _, err := generator.askMultiSelect("Env?", []string{"dev"}, nil); err != nil {
+		t.Fatalf("Failed to ask multi-select question: %v", err)
+	}
+	if strings.Join(recorder.options, ",") != "dev" {
+		t.Errorf("Expected no select-all option for a single choice, got %v", recorder.options)
 	}
 }
 
-func TestDetermineTemplateAndMultiValues(t *testing.T) {
-	tempDir := setupTestEnvironment(t)
+// optionsRecorder records the options of the last multi-select prompt.
+type optionsRecorder struct {
+	MockPrompter
+	options []string
+}
+
+func (r *optionsRecorder) MultiSelect(message string, options []string, defaults []string) ([]string, error) {
+	r.options = options
+	return r.MockPrompter.MultiSelect(message, options, defaults)
+}
+
+const testPromptTemplateConfig = `questions:
+  order: ["env", "cluster", "replicas"]
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev", "staging"]
+    cluster:
+      prompt: "Which cluster in {{ .env }}?"
+      type:
+        multiple: true
+      choices: ["a", "b"]
+    replicas:
+      prompt: "Replicas for {{ .missing }}?"
+      choices: ["1", "2"]`
+
+func TestAskQuestionRendersPrompt(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": testPromptTemplateConfig})
+
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
@@ -437,39 +553,74 @@ func TestDetermineTemplateAndMultiValues(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
+	generator.answers = map[string]interface{}{"env": "staging"}
+	recorder := &messageRecorder{}
+	generator.prompter = recorder
 
-	// Set up test answers
-	generator.answers = map[string]interface{}{
-		"app":     testAppTypeDeployment,
-		"appName": "test-app",
-		"env":     []string{"dev", "staging"},
-		"cluster": []string{"dev-cluster-1", "staging-cluster-1"},
+	questions := generator.config.Questions.GetQuestions()
+	if _, err := generator.askQuestion("cluster", questions["cluster"]); err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
 	}
-
-	templateType, multiValues, err := generator.determineTemplateAndMultiValues()
-	if err != nil {
-		t.Fatalf("Failed to determine template and multi-values: %v", err)
+	if recorder.message != "Which cluster in staging?" {
+		t.Errorf("Expected prompt rendered with the env answer, got %q", recorder.message)
 	}
 
-	if templateType != testAppTypeDeployment {
-		t.Errorf("Expected template type '%s', got %s", testAppTypeDeployment, templateType)
+	// Static prompts are passed through unchanged
+	if _, err := generator.askQuestion("env", questions["env"]); err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
 	}
-
-	if len(multiValues) != 2 {
-		t.Errorf("Expected 2 multi-value questions, got %d", len(multiValues))
+	if recorder.message != "Env?" {
+		t.Errorf("Expected static prompt, got %q", recorder.message)
 	}
 
-	if _, exists := multiValues["env"]; !exists {
-		t.Error("Expected 'env' in multi-value questions")
+	// Referring to an answer that was not given is an error
+	if _, err := generator.askQuestion("replicas", questions["replicas"]); err == nil {
+		t.Error("Expected error for a prompt referring to a missing answer")
 	}
+}
 
-	if _, exists := multiValues["cluster"]; !exists {
-		t.Error("Expected 'cluster' in multi-value questions")
-	}
+// messageRecorder records the message of the last select or multi-select prompt.
+type messageRecorder struct {
+	MockPrompter
+	message string
 }
 
-func TestGenerateCombinations(t *testing.T) {
-	tempDir := setupTestEnvironment(t)
+func (r *messageRecorder) Select(message string, options []string, defaultValue string) (string, error) {
+	r.message = message
+	return r.MockPrompter.Select(message, options, defaultValue)
+}
+
+func (r *messageRecorder) MultiSelect(message string, options []string, defaults []string) ([]string, error) {
+	r.message = message
+	return r.MockPrompter.MultiSelect(message, options, defaults)
+}
+
+// descriptionRecorder records the option descriptions set before each prompt.
+type descriptionRecorder struct {
+	MockPrompter
+	descriptions map[string]string
+}
+
+func (r *descriptionRecorder) SetDescriptions(descriptions map[string]string) {
+	r.descriptions = descriptions
+}
+
+func TestAskQuestionChoiceDescriptions(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": `questions:
+  order: ["env", "app"]
+  definitions:
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices:
+        - value: dev
+          description: "Shared development environment"
+        - staging
+    app:
+      prompt: "App?"
+      choices: ["deployment"]`})
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
@@ -478,43 +629,27 @@ func TestGenerateCombinations(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
+	recorder := &descriptionRecorder{}
+	generator.prompter = recorder
+	questions := generator.config.Questions.GetQuestions()
 
-	generator.answers = map[string]interface{}{
-		"app":     testAppTypeDeployment,
-		"appName": "test-app",
+	if _, err := generator.askQuestion("env", questions["env"]); err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
 	}
-
-	multiValues := map[string][]string{
-		"env":     {"dev", "staging"},
-		"cluster": {"cluster-1", "cluster-2"},
+	if len(recorder.descriptions) != 1 || recorder.descriptions["dev"] != "Shared development environment" {
+		t.Errorf("Expected a description for dev only, got %v", recorder.descriptions)
 	}
 
-	combinations := generator.generateCombinations(multiValues)
-
-	// Should generate 2 * 2 = 4 combinations
-	expectedCount := 4
-	if len(combinations) != expectedCount {
-		t.Errorf("Expected %d combinations, got %d", expectedCount, len(combinations))
+	// Plain choices clear the descriptions of the previous question
+	if _, err := generator.askQuestion("app", questions["app"]); err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
 	}
-
-	// Each combination should have all base answers plus the specific multi-value selections
-	for i, combination := range combinations {
-		if combination["app"] != testAppTypeDeployment {
-			t.Errorf("Combination %d: expected app='%s', got %v", i, testAppTypeDeployment, combination["app"])
-		}
-		if combination["appName"] != "test-app" {
-			t.Errorf("Combination %d: expected appName='test-app', got %v", i, combination["appName"])
-		}
-		if _, exists := combination["env"]; !exists {
-			t.Errorf("Combination %d: missing env", i)
-		}
-		if _, exists := combination["cluster"]; !exists {
-			t.Errorf("Combination %d: missing cluster", i)
-		}
+	if len(recorder.descriptions) != 0 {
+		t.Errorf("Expected no descriptions for plain choices, got %v", recorder.descriptions)
 	}
 }
 
-func TestGenerateCombinationsEmpty(t *testing.T) {
+func TestAskQuestionMultiSelectDefaults(t *testing.T) {
 	tempDir := setupTestEnvironment(t)
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
@@ -525,25 +660,37 @@ func TestGenerateCombinationsEmpty(t *testing.T) {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	generator.answers = map[string]interface{}{
-		"app":     testAppTypeDeployment,
-		"appName": "test-app",
+	question := generator.config.Questions.GetQuestions()["env"]
+	question.Default = []string{"dev", "staging"}
+
+	// Accepting the pre-selected options returns the defaults
+	mockPrompter := &MockPrompter{}
+	generator.prompter = mockPrompter
+	answer, err := generator.askQuestion("env", question)
+	if err != nil {
+		t.Fatalf("Failed to ask multi-select question: %v", err)
 	}
 
-	// No multi-value questions
-	combinations := generator.generateCombinations(map[string][]string{})
+	if strings.Join(mockPrompter.multiSelectDefault, ",") != "dev,staging" {
+		t.Errorf("Expected defaults dev,staging to be pre-selected, got %v", mockPrompter.multiSelectDefault)
+	}
+	if answerSlice, ok := answer.([]string); !ok || strings.Join(answerSlice, ",") != "dev,staging" {
+		t.Errorf("Expected answer dev,staging, got %v", answer)
+	}
 
-	// Should return single combination with all answers
-	if len(combinations) != 1 {
-		t.Errorf("Expected 1 combination, got %d", len(combinations))
+	// The user can still change the selection
+	generator.prompter = &MockPrompter{multiSelectResults: [][]string{{"staging"}}}
+	answer, err = generator.askQuestion("env", question)
+	if err != nil {
+		t.Fatalf("Failed to ask multi-select question: %v", err)
 	}
 
-	if combinations[0]["app"] != testAppTypeDeployment {
-		t.Errorf("Expected app='%s', got %v", testAppTypeDeployment, combinations[0]["app"])
+	if answerSlice, ok := answer.([]string); !ok || strings.Join(answerSlice, ",") != "staging" {
+		t.Errorf("Expected overridden answer staging, got %v", answer)
 	}
 }
 
-func TestRunWithOptionsPrefilledAnswers(t *testing.T) {
+func TestAskQuestionMultiSelectInvalidDefault(t *testing.T) {
 	tempDir := setupTestEnvironment(t)
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
@@ -553,223 +700,3297 @@ func TestRunWithOptionsPrefilledAnswers(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
+	generator.prompter = &MockPrompter{}
 
-	// Mock prompter for remaining questions
-	mockPrompter := &MockPrompter{
-		searchResults:      []string{"sample-server-1"},
-		multiSelectResults: [][]string{{"dev-cluster-1"}},
-		confirmResults:     []bool{true},
+	question := generator.config.Questions.GetQuestions()["env"]
+	question.Default = []string{"production"}
+
+	if _, err := generator.askQuestion("env", question); err == nil {
+		t.Error("Expected error for a default that is not an available choice")
+	}
+}
+
+const testDefaultFromConfig = `questions:
+  order: ["app", "env", "namespace"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      choices: ["dev", "staging"]
+    namespace:
+      prompt: "Namespace?"
+      default_from: env
+      choices: ["dev", "staging", "shared"]`
+
+func TestAskQuestionInheritsDefault(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": testDefaultFromConfig})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
 	}
+	generator.answers = map[string]interface{}{"app": "deployment", "env": "staging"}
+
+	// namespace inherits the answer to env
+	mockPrompter := &MockPrompter{}
 	generator.prompter = mockPrompter
+	answer, err := generator.askQuestion("namespace", generator.config.Questions.GetQuestions()["namespace"])
+	if err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
+	}
 
-	// Pre-fill some answers
-	options := &Options{
-		Answers: map[string]interface{}{
-			"app": testAppTypeDeployment,
-			"env": []string{"dev"},
-		},
-		SkipPrompt: false,
+	if mockPrompter.selectDefault != "staging" || answer != "staging" {
+		t.Errorf("Expected namespace to default to staging, got default %q and answer %v",
+			mockPrompter.selectDefault, answer)
 	}
 
-	err = generator.RunWithOptions(options)
+	// An explicit selection overrides the inherited default
+	generator.prompter = &MockPrompter{selectResults: []string{"shared"}}
+	answer, err = generator.askQuestion("namespace", generator.config.Questions.GetQuestions()["namespace"])
 	if err != nil {
-		t.Fatalf("Failed to run generator with prefilled answers: %v", err)
+		t.Fatalf("Failed to ask question: %v", err)
 	}
 
-	// Check that pre-filled answers were used
-	if generator.answers["app"] != testAppTypeDeployment {
-		t.Errorf("Expected app='%s', got %v", testAppTypeDeployment, generator.answers["app"])
+	if answer != "shared" {
+		t.Errorf("Expected overridden namespace shared, got %v", answer)
 	}
 }
 
-func TestDynamicChoicesInRun(t *testing.T) {
-	tempDir := setupTestEnvironment(t)
-	defer func() { _ = os.Chdir(tempDir) }()
+func TestAskQuestionPassthroughWithoutChoices(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": `questions:
+  order: ["env", "namespace", "label"]
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev", "staging"]
+    namespace:
+      prompt: "Namespace"
+      default_from: env
+    label:
+      prompt: "Label"`})
 
-	// Change to temp directory before creating generator
-	err := os.Chdir(tempDir)
-	if err != nil {
-		t.Fatalf("Failed to change directory: %v", err)
-	}
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
 
 	generator, err := New()
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
+	generator.answers = map[string]interface{}{"env": "staging"}
 
-	// Set up answers simulating dynamic choice selection
-	// First select multiple environments, then verify cluster choices are combined
-	generator.answers = map[string]interface{}{
-		"app": testAppTypeDeployment,
-		"env": []string{"dev", "staging"}, // Multiple environments
+	// The passthrough question is answered without prompting
+	mockPrompter := &MockPrompter{}
+	generator.prompter = mockPrompter
+	questions := generator.config.Questions.GetQuestions()
+	answer, err := generator.askQuestion("namespace", questions["namespace"])
+	if err != nil {
+		t.Fatalf("Failed to ask passthrough question: %v", err)
 	}
 
-	// Mock the prompter to simulate user selections
-	mockPrompter := &MockPrompter{
-		selectResults: []string{testAppTypeDeployment}, // app selection
-		searchResults: []string{"dynamic-app"},         // appName search
-		multiSelectResults: [][]string{
-			{"dev", "staging"}, // env multiselect
-			{"dev-cluster-1", "dev-cluster-2", "staging-cluster-1"}, // cluster multiselect (combined from multiple envs)
-		},
-		confirmResults: []bool{true}, // final confirmation
+	if answer != "staging" {
+		t.Errorf("Expected namespace to carry staging forward, got %v", answer)
+	}
+	if mockPrompter.selectIndex != 0 || mockPrompter.selectDefault != "" {
+		t.Error("Passthrough question should not prompt")
 	}
-	generator.prompter = mockPrompter
 
-	// Test getting dynamic choices for cluster question
-	questions := generator.config.Questions.GetQuestions()
-	clusterQuestion, exists := questions["cluster"]
-	if !exists {
-		t.Fatal("cluster question should exist in config")
+	if _, err := generator.askQuestion("label", questions["label"]); err == nil {
+		t.Error("Expected error for a question without choices or default")
 	}
+}
 
-	choices, err := clusterQuestion.GetChoices(generator.answers)
+func TestAskQuestionDynamicWithoutChoices(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": `questions:
+  order: ["env", "cluster"]
+  definitions:
+    env:
+      prompt: "Env?"
+      choices: ["dev", "staging", "prod"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-1"]
+        staging: []
+        prod:`})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
 	if err != nil {
-		t.Fatalf("Failed to get dynamic choices: %v", err)
+		t.Fatalf("Failed to create generator: %v", err)
 	}
-
-	// Should get choices from both dev and staging environments in hierarchical format
-	if len(choices) < 3 { // At least dev choices should be present
-		t.Errorf("Expected at least 3 dynamic choices, got %d: %v", len(choices), choices)
+	generator.prompter = &MockPrompter{}
+	question := generator.config.Questions.GetQuestions()["cluster"]
+
+	// Both an empty list and a value without children yield no choices
+	for _, env := range []string{"staging", "prod"} {
+		generator.answers = map[string]interface{}{"env": env}
+		_, err := generator.askQuestion("cluster", question)
+		expected := "question cluster has no choices for env=" + env
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q, got %v", expected, err)
+		}
 	}
+}
 
-	// Verify that choices contain hierarchical format and expected values from both environments
-	devChoicesFound := 0
-	stagingChoicesFound := 0
-	hierarchicalChoicesFound := 0
+const testNumberConfig = `questions:
+  order: ["app", "replicas"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    replicas:
+      prompt: "Replicas?"
+      type:
+        number:
+          min: 1
+          max: 10`
 
-	for _, choice := range choices {
-		// Check if choice is in hierarchical format
+func TestAskQuestionNumber(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": testNumberConfig})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Out-of-range and non-integer inputs are rejected until a valid one is entered
+	mockPrompter := &MockPrompter{inputResults: []string{"0", "many", "11", "3"}}
+	generator.prompter = mockPrompter
+	answer, err := generator.askQuestion("replicas", generator.config.Questions.GetQuestions()["replicas"])
+	if err != nil {
+		t.Fatalf("Failed to ask numeric question: %v", err)
+	}
+
+	if answer != 3 {
+		t.Errorf("Expected answer coerced to int 3, got %v (%T)", answer, answer)
+	}
+	if len(mockPrompter.inputErrors) != 3 {
+		t.Errorf("Expected 3 rejected inputs, got %d", len(mockPrompter.inputErrors))
+	}
+}
+
+func TestRunWithNumberSkipPrompt(t *testing.T) {
+	testCases := []struct {
+		replicas  string
+		expectErr bool
+	}{
+		{"1", false},
+		{"10", false},
+		{"0", true},
+		{"11", true},
+		{"three", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.replicas, func(t *testing.T) {
+			tempDir := t.TempDir()
+			writeTestFiles(t, tempDir, map[string]string{
+				".yg/config.yaml": testNumberConfig,
+				".yg/_templates/deployment.yaml": `path: out
+filename: app.yaml
+---
+replicas: {{ .Questions.replicas }}`,
+			})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			err = generator.RunWithOptions(&Options{
+				Answers:    map[string]interface{}{"app": "deployment", "replicas": tc.replicas},
+				SkipPrompt: true,
+				NoPreview:  true,
+			})
+			if tc.expectErr {
+				if !errors.Is(err, ErrInvalidOptions) {
+					t.Errorf("Expected invalid options error for %s, got: %v", tc.replicas, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected %s to be accepted, got: %v", tc.replicas, err)
+			}
+			if _, ok := generator.Answers()["replicas"].(int); !ok {
+				t.Errorf("Expected replicas to be stored as int, got %T", generator.Answers()["replicas"])
+			}
+		})
+	}
+}
+
+func TestRunWithBoolSkipPrompt(t *testing.T) {
+	testCases := []struct {
+		tls      string
+		expected string
+	}{
+		{"y", "tls: enabled"},
+		{"Yes", "tls: enabled"},
+		{"1", "tls: enabled"},
+		{"true", "tls: enabled"},
+		{"n", "tls: disabled"},
+		{"no", "tls: disabled"},
+		{"0", "tls: disabled"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tls, func(t *testing.T) {
+			tempDir := t.TempDir()
+			writeTestFiles(t, tempDir, map[string]string{
+				".yg/config.yaml": `questions:
+  order: ["app", "tls"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    tls:
+      prompt: "Enable TLS?"
+      type:
+        bool: true`,
+				".yg/_templates/deployment.yaml": `path: out
+filename: app.yaml
+---
+tls: {{if .Questions.tls}}enabled{{else}}disabled{{end}}`,
+			})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			err = generator.RunWithOptions(&Options{
+				Answers:    map[string]interface{}{"app": "deployment", "tls": tc.tls},
+				SkipPrompt: true,
+				NoPreview:  true,
+			})
+			if err != nil {
+				t.Fatalf("Expected %s to be accepted, got: %v", tc.tls, err)
+			}
+			content, err := os.ReadFile(filepath.Join("out", "app.yaml"))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+			if string(content) != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestApplyInheritedAnswersSkipPrompt(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": testDefaultFromConfig})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers:    map[string]interface{}{"app": "deployment", "env": "dev"},
+		SkipPrompt: true,
+	}
+	if err := generator.validateOptions(options); err != nil {
+		t.Fatalf("Expected namespace to be optional when it inherits env: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{"app": "deployment", "env": "dev"}
+	if err := generator.applyInheritedAnswers(); err != nil {
+		t.Fatalf("Failed to apply inherited answers: %v", err)
+	}
+	if generator.answers["namespace"] != "dev" {
+		t.Errorf("Expected namespace to inherit dev, got %v", generator.answers["namespace"])
+	}
+
+	// An explicit answer wins over the inherited one
+	generator.answers = map[string]interface{}{"app": "deployment", "env": "dev", "namespace": "shared"}
+	if err := generator.applyInheritedAnswers(); err != nil {
+		t.Fatalf("Failed to apply inherited answers: %v", err)
+	}
+	if generator.answers["namespace"] != "shared" {
+		t.Errorf("Expected explicit namespace shared, got %v", generator.answers["namespace"])
+	}
+}
+
+func TestRunWithValidations(t *testing.T) {
+	testCases := []struct {
+		name       string
+		skipPrompt bool
+		env        string
+		cluster    string
+		wantErr    bool
+	}{
+		{name: "valid non-production", skipPrompt: true, env: "dev", cluster: "default"},
+		{name: "valid production", skipPrompt: true, env: "prod", cluster: "blue"},
+		{name: "invalid production", skipPrompt: true, env: "prod", cluster: "default", wantErr: true},
+		{name: "invalid production interactive", env: "prod", cluster: "default", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			writeTestFiles(t, tempDir, map[string]string{
+				".yg/config.yaml": `questions:
+  order: ["app", "env", "cluster"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      choices: ["dev", "prod"]
+    cluster:
+      prompt: "Cluster?"
+      choices: ["default", "blue"]
+validations:
+  - rule: '{{ or (ne .Questions.env "prod") (ne .Questions.cluster "default") }}'
+    message: "production requires a non-default cluster"`,
+				".yg/_templates/deployment.yaml": `path: {{.Questions.env}}
+filename: deployment.yaml
+---
+cluster: {{.Questions.cluster}}`,
+			})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			options := &Options{SkipPrompt: tc.skipPrompt, NoPreview: true}
+			if tc.skipPrompt {
+				options.Answers = map[string]interface{}{"app": "deployment", "env": tc.env, "cluster": tc.cluster}
+			} else {
+				generator.prompter = &MockPrompter{
+					selectResults:  []string{"deployment", tc.env, tc.cluster},
+					confirmResults: []bool{true},
+				}
+			}
+
+			err = generator.RunWithOptions(options)
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("Failed to run generator: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), "production requires a non-default cluster") {
+				t.Fatalf("Expected the validation message, got %v", err)
+			}
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Expected ErrInvalidOptions, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(tempDir, tc.env, "deployment.yaml")); !os.IsNotExist(err) {
+				t.Errorf("Expected no file to be generated, got err %v", err)
+			}
+		})
+	}
+}
+
+func TestRunWithOrderOmittingQuestion(t *testing.T) {
+	testCases := []struct {
+		name       string
+		skipPrompt bool
+	}{
+		{name: "interactive", skipPrompt: false},
+		{name: "skip prompt", skipPrompt: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			writeTestFiles(t, tempDir, map[string]string{
+				".yg/config.yaml": `questions:
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      choices: ["dev"]
+    owner:
+      prompt: "Owner?"
+      choices: ["team-a"]`,
+				".yg/_templates/deployment.yaml": `path: {{.Questions.env}}
+filename: deployment.yaml
+---
+env: {{.Questions.env}}`,
+			})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			generator.prompter = &MockPrompter{}
+
+			options := &Options{
+				SkipPrompt: tc.skipPrompt,
+				NoPreview:  true,
+			}
+			if tc.skipPrompt {
+				options.Answers = map[string]interface{}{"app": "deployment", "env": "dev"}
+			}
+
+			// owner is not in order, so it is neither asked nor required
+			if err := generator.RunWithOptions(options); err != nil {
+				t.Fatalf("Failed to run generator: %v", err)
+			}
+			if _, exists := generator.Answers()["owner"]; exists {
+				t.Errorf("Expected owner to stay unanswered, got %v", generator.Answers()["owner"])
+			}
+			if _, err := os.Stat(filepath.Join(tempDir, "dev", "deployment.yaml")); err != nil {
+				t.Errorf("Expected dev/deployment.yaml to be generated: %v", err)
+			}
+		})
+	}
+}
+
+const testProfileConfig = `profiles:
+  ci-deploy:
+    app: deployment
+    appName: sample-server-1
+    env: [dev, staging]
+    cluster: dev-cluster-1
+questions:
+  order: ["app", "appName", "env", "cluster"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    appName:
+      prompt: "Name?"
+      choices: ["sample-server-1", "sample-server-2"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        multiple: true
+      choices: ["dev-cluster-1", "dev-cluster-2"]`
+
+func TestRunWithProfile(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml":                testProfileConfig,
+		".yg/_templates/deployment.yaml": testDeploymentContent,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers:    map[string]interface{}{"appName": "sample-server-2"},
+		SkipPrompt: true,
+		NoPreview:  true,
+		Profile:    "ci-deploy",
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator with profile: %v", err)
+	}
+
+	answers := generator.Answers()
+	if answers["appName"] != "sample-server-2" {
+		t.Errorf("Expected explicit answer to override the profile, got %v", answers["appName"])
+	}
+	if env, ok := answers["env"].([]string); !ok || strings.Join(env, ",") != "dev,staging" {
+		t.Errorf("Expected env from profile, got %v", answers["env"])
+	}
+
+	// Scalar profile values of multi-select questions become lists
+	if cluster, ok := answers["cluster"].([]string); !ok || len(cluster) != 1 {
+		t.Errorf("Expected cluster list from profile, got %v", answers["cluster"])
+	}
+
+	if len(options.Answers) != 1 {
+		t.Error("Profile answers should not be merged into the caller's options")
+	}
+}
+
+func TestRunWithUnknownProfile(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": testProfileConfig})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	err = generator.RunWithOptions(&Options{SkipPrompt: true, Profile: "local-dev"})
+	if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), "ci-deploy") {
+		t.Errorf("Expected invalid options error listing available profiles, got: %v", err)
+	}
+}
+
+func TestAskQuestionWithProfileDefault(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": testProfileConfig})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	presets, err := generator.profileAnswers("ci-deploy")
+	if err != nil {
+		t.Fatalf("Failed to load profile: %v", err)
+	}
+	generator.presets = presets
+
+	// Without --yes, profile answers are pre-selected but can be changed
+	mockPrompter := &MockPrompter{selectResults: []string{"sample-server-2"}}
+	generator.prompter = mockPrompter
+	answer, err := generator.askQuestion("appName", generator.config.Questions.GetQuestions()["appName"])
+	if err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
+	}
+
+	if mockPrompter.selectDefault != "sample-server-1" {
+		t.Errorf("Expected profile answer to be pre-selected, got %q", mockPrompter.selectDefault)
+	}
+	if answer != "sample-server-2" {
+		t.Errorf("Expected overridden answer sample-server-2, got %v", answer)
+	}
+}
+
+func TestAskQuestionInteractiveSearch(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Set up generator answers to satisfy dependencies
+	generator.answers = map[string]interface{}{
+		"app": testAppTypeDeployment,
+	}
+
+	mockPrompter := &MockPrompter{
+		searchResults: []string{"sample-server-1"},
+	}
+	generator.prompter = mockPrompter
+
+	questions := generator.config.Questions.GetQuestions()
+	question := questions["appName"]
+	answer, err := generator.askQuestion("appName", question)
+	if err != nil {
+		t.Fatalf("Failed to ask interactive search question: %v", err)
+	}
+
+	if answer != "sample-server-1" {
+		t.Errorf("Expected answer 'sample-server-1', got %s", answer)
+	}
+}
+
+func TestDetermineTemplateAndMultiValues(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Set up test answers
+	generator.answers = map[string]interface{}{
+		"app":     testAppTypeDeployment,
+		"appName": "test-app",
+		"env":     []string{"dev", "staging"},
+		"cluster": []string{"dev-cluster-1", "staging-cluster-1"},
+	}
+
+	templateType, multiValues, err := generator.determineTemplateAndMultiValues()
+	if err != nil {
+		t.Fatalf("Failed to determine template and multi-values: %v", err)
+	}
+
+	if templateType != testAppTypeDeployment {
+		t.Errorf("Expected template type '%s', got %s", testAppTypeDeployment, templateType)
+	}
+
+	if len(multiValues) != 2 {
+		t.Errorf("Expected 2 multi-value questions, got %d", len(multiValues))
+	}
+
+	if _, exists := multiValues["env"]; !exists {
+		t.Error("Expected 'env' in multi-value questions")
+	}
+
+	if _, exists := multiValues["cluster"]; !exists {
+		t.Error("Expected 'cluster' in multi-value questions")
+	}
+}
+
+func TestGenerateCombinations(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"app":     testAppTypeDeployment,
+		"appName": "test-app",
+	}
+
+	multiValues := map[string][]string{
+		"env":     {"dev", "staging"},
+		"cluster": {"cluster-1", "cluster-2"},
+	}
+
+	combinations := generator.generateCombinations(multiValues)
+
+	// Should generate 2 * 2 = 4 combinations
+	expectedCount := 4
+	if len(combinations) != expectedCount {
+		t.Errorf("Expected %d combinations, got %d", expectedCount, len(combinations))
+	}
+
+	// Each combination should have all base answers plus the specific multi-value selections
+	for i, combination := range combinations {
+		if combination["app"] != testAppTypeDeployment {
+			t.Errorf("Combination %d: expected app='%s', got %v", i, testAppTypeDeployment, combination["app"])
+		}
+		if combination["appName"] != "test-app" {
+			t.Errorf("Combination %d: expected appName='test-app', got %v", i, combination["appName"])
+		}
+		if _, exists := combination["env"]; !exists {
+			t.Errorf("Combination %d: missing env", i)
+		}
+		if _, exists := combination["cluster"]; !exists {
+			t.Errorf("Combination %d: missing cluster", i)
+		}
+	}
+}
+
+func TestGenerateCombinationsEmpty(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"app":     testAppTypeDeployment,
+		"appName": "test-app",
+	}
+
+	// No multi-value questions
+	combinations := generator.generateCombinations(map[string][]string{})
+
+	// Should return single combination with all answers
+	if len(combinations) != 1 {
+		t.Errorf("Expected 1 combination, got %d", len(combinations))
+	}
+
+	if combinations[0]["app"] != testAppTypeDeployment {
+		t.Errorf("Expected app='%s', got %v", testAppTypeDeployment, combinations[0]["app"])
+	}
+}
+
+func TestRunWithOptionsPrefilledAnswers(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Mock prompter for remaining questions
+	mockPrompter := &MockPrompter{
+		searchResults:      []string{"sample-server-1"},
+		multiSelectResults: [][]string{{"dev-cluster-1"}},
+		confirmResults:     []bool{true},
+	}
+	generator.prompter = mockPrompter
+
+	// Pre-fill some answers
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app": testAppTypeDeployment,
+			"env": []string{"dev"},
+		},
+		SkipPrompt: false,
+	}
+
+	err = generator.RunWithOptions(options)
+	if err != nil {
+		t.Fatalf("Failed to run generator with prefilled answers: %v", err)
+	}
+
+	// Check that pre-filled answers were used
+	if generator.answers["app"] != testAppTypeDeployment {
+		t.Errorf("Expected app='%s', got %v", testAppTypeDeployment, generator.answers["app"])
+	}
+}
+
+// promptRecorder records the message of every prompt shown.
+type promptRecorder struct {
+	MockPrompter
+	prompts []string
+}
+
+func (r *promptRecorder) Select(message string, options []string, defaultValue string) (string, error) {
+	r.prompts = append(r.prompts, message)
+	return r.MockPrompter.Select(message, options, defaultValue)
+}
+
+func (r *promptRecorder) MultiSelect(message string, options []string, defaults []string) ([]string, error) {
+	r.prompts = append(r.prompts, message)
+	return r.MockPrompter.MultiSelect(message, options, defaults)
+}
+
+func (r *promptRecorder) Search(message string, options []string, defaultValue string) (string, error) {
+	r.prompts = append(r.prompts, message)
+	return r.MockPrompter.Search(message, options, defaultValue)
+}
+
+func (r *promptRecorder) Confirm(message string, defaultValue bool) (bool, error) {
+	r.prompts = append(r.prompts, message)
+	return r.MockPrompter.Confirm(message, defaultValue)
+}
+
+func TestRunWithPromptMissing(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	recorder := &promptRecorder{MockPrompter: MockPrompter{multiSelectResults: [][]string{{"dev-cluster-1"}}}}
+	generator.prompter = recorder
+
+	err = generator.RunWithOptions(&Options{
+		Answers: map[string]interface{}{
+			"app":     testAppTypeDeployment,
+			"appName": "my-app",
+			"env":     []string{"dev"},
+		},
+		PromptMissing: true,
+		NoPreview:     true,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	// Only the unanswered cluster question is asked, without a confirmation
+	expected := []string{"Which target destinations do you want to deploy to?"}
+	if len(recorder.prompts) != len(expected) || recorder.prompts[0] != expected[0] {
+		t.Errorf("Expected prompts %v, got %v", expected, recorder.prompts)
+	}
+	if _, err := os.Stat(filepath.Join("dev", "dev-cluster-1", "deployment", "my-app-deployment.yaml")); err != nil {
+		t.Errorf("Expected the deployment to be generated: %v", err)
+	}
+}
+
+func TestDynamicChoicesInRun(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	defer func() { _ = os.Chdir(tempDir) }()
+
+	// Change to temp directory before creating generator
+	err := os.Chdir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Set up answers simulating dynamic choice selection
+	// First select multiple environments, then verify cluster choices are combined
+	generator.answers = map[string]interface{}{
+		"app": testAppTypeDeployment,
+		"env": []string{"dev", "staging"}, // Multiple environments
+	}
+
+	// Mock the prompter to simulate user selections
+	mockPrompter := &MockPrompter{
+		selectResults: []string{testAppTypeDeployment}, // app selection
+		searchResults: []string{"dynamic-app"},         // appName search
+		multiSelectResults: [][]string{
+			{"dev", "staging"}, // env multiselect
+			{"dev-cluster-1", "dev-cluster-2", "staging-cluster-1"}, // cluster multiselect (combined from multiple envs)
+		},
+		confirmResults: []bool{true}, // final confirmation
+	}
+	generator.prompter = mockPrompter
+
+	// Test getting dynamic choices for cluster question
+	questions := generator.config.Questions.GetQuestions()
+	clusterQuestion, exists := questions["cluster"]
+	if !exists {
+		t.Fatal("cluster question should exist in config")
+	}
+
+	choices, err := clusterQuestion.GetChoices(generator.answers)
+	if err != nil {
+		t.Fatalf("Failed to get dynamic choices: %v", err)
+	}
+
+	// Should get choices from both dev and staging environments in hierarchical format
+	if len(choices) < 3 { // At least dev choices should be present
+		t.Errorf("Expected at least 3 dynamic choices, got %d: %v", len(choices), choices)
+	}
+
+	// Verify that choices contain hierarchical format and expected values from both environments
+	devChoicesFound := 0
+	stagingChoicesFound := 0
+	hierarchicalChoicesFound := 0
+
+	for _, choice := range choices {
+		// Check if choice is in hierarchical format
 		if strings.Contains(choice, ": ") {
 			hierarchicalChoicesFound++
 
-			// Parse hierarchical choice to check parent environment
-			parts := strings.SplitN(choice, ": ", 2)
-			if len(parts) == 2 {
-				parent := parts[0]
-				child := parts[1]
+			// Parse hierarchical choice to check parent environment
+			parts := strings.SplitN(choice, ": ", 2)
+			if len(parts) == 2 {
+				parent := parts[0]
+				child := parts[1]
+
+				if parent == "dev" && strings.Contains(child, "dev-cluster") {
+					devChoicesFound++
+				}
+				if parent == "staging" && strings.Contains(child, "staging-cluster") {
+					stagingChoicesFound++
+				}
+			}
+		}
+	}
+
+	if hierarchicalChoicesFound == 0 {
+		t.Error("Expected hierarchical choices with format 'parent: child', but none found")
+	}
+	if devChoicesFound == 0 {
+		t.Error("No dev cluster choices found in dynamic selection")
+	}
+	if stagingChoicesFound == 0 {
+		t.Error("No staging cluster choices found in dynamic selection")
+	}
+}
+
+func setupTestEnvironmentWithTemplateQuestion(t *testing.T) string {
+	tempDir := t.TempDir()
+
+	// Create .yg directory structure
+	templateDir := filepath.Join(tempDir, ".yg", "_templates")
+	err := os.MkdirAll(templateDir, 0755)
+	if err != nil {
+		t.Fatalf("Failed to create temp template directory: %v", err)
+	}
+
+	// Create config file with template_question specified
+	configFile := filepath.Join(templateDir, ".yg-config.yaml")
+	configContent := `questions:
+  template_question: "appType"  # Explicitly set which question provides template
+  order:
+    - appType
+    - appName
+    - env
+    - cluster
+  definitions:
+    appType:
+      prompt: "Select application type"
+      choices:
+        - deployment
+        - job
+        - microservice
+    appName:
+      prompt: "What is the name of your item?"
+      type:
+        interactive: true
+      choices:
+        - sample-app-1
+        - sample-app-2
+        - sample-service-1
+    env:
+      prompt: "Which environment do you want to target?"
+      type:
+        multiple: true
+      choices:
+        - dev
+        - staging
+        - production
+    cluster:
+      prompt: "Which target destinations do you want to deploy to?"
+      type:
+        multiple: true
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev:
+          - dev-cluster-1
+          - dev-cluster-2
+        staging:
+          - staging-cluster-1
+          - staging-cluster-2`
+
+	err = os.WriteFile(configFile, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// Create template files
+	deploymentTemplate := filepath.Join(templateDir, "deployment.yaml")
+	deploymentContent := testDeploymentContent
+
+	err = os.WriteFile(deploymentTemplate, []byte(deploymentContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write deployment template: %v", err)
+	}
+
+	jobTemplate := filepath.Join(templateDir, "job.yaml")
+	jobContent := testJobContent
+
+	err = os.WriteFile(jobTemplate, []byte(jobContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write job template: %v", err)
+	}
+
+	microserviceTemplate := filepath.Join(templateDir, "microservice.yaml")
+	microserviceContent := `path: {{.Questions.env}}/{{.Questions.cluster}}/microservice
+filename: {{.Questions.appName}}-microservice.yaml
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Questions.appName}}
+  namespace: {{.Questions.env}}
+  labels:
+    type: microservice`
+
+	err = os.WriteFile(microserviceTemplate, []byte(microserviceContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write microservice template: %v", err)
+	}
+
+	return tempDir
+}
+
+func TestDetermineTemplateAndMultiValuesWithTemplateQuestion(t *testing.T) {
+	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Set up test answers - note that order is different from typical config
+	generator.answers = map[string]interface{}{
+		"appName": "test-app",                // This would be first non-multi in order
+		"appType": "microservice",            // This is specified as template_question
+		"env":     []string{"dev"},           // Multi-value
+		"cluster": []string{"dev-cluster-1"}, // Multi-value
+	}
+
+	templateType, multiValues, err := generator.determineTemplateAndMultiValues()
+	if err != nil {
+		t.Fatalf("Failed to determine template and multi-values: %v", err)
+	}
+
+	// Should use appType (configured template_question) not appName (first non-multi)
+	if templateType != "microservice" {
+		t.Errorf("Expected template type 'microservice', got '%s'", templateType)
+	}
+
+	if len(multiValues) != 2 {
+		t.Errorf("Expected 2 multi-value questions, got %d", len(multiValues))
+	}
+
+	if _, exists := multiValues["env"]; !exists {
+		t.Error("Expected 'env' in multi-value questions")
+	}
+
+	if _, exists := multiValues["cluster"]; !exists {
+		t.Error("Expected 'cluster' in multi-value questions")
+	}
+}
+
+func TestDetermineTemplateAndMultiValuesWithMissingTemplateQuestion(t *testing.T) {
+	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Missing the template question answer
+	generator.answers = map[string]interface{}{
+		"appName": "test-app",
+		"env":     []string{"dev"},
+		"cluster": []string{"dev-cluster-1"},
+		// appType is missing
+	}
+
+	_, _, err = generator.determineTemplateAndMultiValues()
+	if err == nil {
+		t.Error("Expected error when template question is not answered")
+	}
+
+	if !strings.Contains(err.Error(), "template question 'appType' not answered") {
+		t.Errorf("Expected error about missing template question, got: %v", err)
+	}
+}
+
+func TestDetermineTemplateAndMultiValuesWithInvalidTemplateAnswer(t *testing.T) {
+	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Template question has non-string answer
+	generator.answers = map[string]interface{}{
+		"appName": "test-app",
+		"appType": []string{"deployment", "job"}, // Array instead of string
+		"env":     []string{"dev"},
+		"cluster": []string{"dev-cluster-1"},
+	}
+
+	_, _, err = generator.determineTemplateAndMultiValues()
+	if err == nil {
+		t.Error("Expected error when template question has non-string answer")
+	}
+
+	if !strings.Contains(err.Error(), "template question 'appType' must have a single string answer") {
+		t.Errorf("Expected error about invalid template answer type, got: %v", err)
+	}
+}
+
+func TestDetermineTemplateAndMultiValuesHeuristicFallback(t *testing.T) {
+	// Test that heuristic fallback still works when template_question is not set
+	tempDir := setupTestEnvironment(t) // This config has no template_question
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Set up test answers
+	generator.answers = map[string]interface{}{
+		"app":     "deployment",
+		"appName": "test-app",
+		"env":     []string{"dev"},
+		"cluster": []string{"dev-cluster-1"},
+	}
+
+	templateType, multiValues, err := generator.determineTemplateAndMultiValues()
+	if err != nil {
+		t.Fatalf("Failed to determine template and multi-values: %v", err)
+	}
+
+	// Should use first non-multi question (app) via heuristic
+	if templateType != "deployment" {
+		t.Errorf("Expected template type 'deployment', got '%s'", templateType)
+	}
+
+	if len(multiValues) != 2 {
+		t.Errorf("Expected 2 multi-value questions, got %d", len(multiValues))
+	}
+}
+
+func TestRunWithTemplateQuestion(t *testing.T) {
+	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Mock the prompter
+	mockPrompter := &MockPrompter{
+		selectResults:      []string{"microservice"},               // appType selection
+		searchResults:      []string{"sample-service-1"},           // appName search
+		multiSelectResults: [][]string{{"dev"}, {"dev-cluster-1"}}, // env, then cluster
+		confirmResults:     []bool{true},                           // final confirmation
+	}
+	generator.prompter = mockPrompter
+
+	err = generator.Run()
+	if err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	// Check if the microservice template was used
+	expectedFile := filepath.Join(tempDir, "dev", "dev-cluster-1", "microservice", "sample-service-1-microservice.yaml")
+	if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
+		t.Errorf("Expected microservice file %s was not generated", expectedFile)
+	}
+
+	// Read the generated file to verify it used the microservice template
+	content, err := os.ReadFile(expectedFile)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+
+	contentStr := string(content)
+	if !strings.Contains(contentStr, "type: microservice") {
+		t.Error("Generated file should contain 'type: microservice' from template")
+	}
+}
+
+func TestShowCLIExample(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Set up test answers
+	generator.answers = map[string]interface{}{
+		"app":     "deployment",
+		"appName": "test-app",
+		"env":     []string{"dev", "staging"},
+		"cluster": []string{"dev-cluster-1", "staging-cluster-1"},
+	}
+
+	var buf bytes.Buffer
+	generator.showCLIExample(&buf)
+
+	expected := "yg --yes --answer app=deployment --answer appName=test-app" +
+		" --answer env=dev,staging --answer cluster=dev-cluster-1,staging-cluster-1"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected CLI example %q, got:\n%s", expected, buf.String())
+	}
+}
+
+func TestShowCLIExampleBool(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": `questions:
+  order: ["app", "tls"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    tls:
+      prompt: "Enable TLS?"
+      type:
+        bool: true`})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{"app": "deployment", "tls": true}
+	var buf bytes.Buffer
+	generator.showCLIExample(&buf)
+
+	expected := "yg --yes --answer app=deployment --answer tls=true"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected CLI example %q, got:\n%s", expected, buf.String())
+	}
+}
+
+func TestSecretQuestion(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": `questions:
+  order: ["app", "token"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    token:
+      prompt: "API token?"
+      type:
+        secret: true`})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	mockPrompter := &MockPrompter{passwordResults: []string{"s3cr3t"}}
+	generator.prompter = mockPrompter
+	answer, err := generator.askQuestion("token", generator.config.Questions.GetQuestions()["token"])
+	if err != nil {
+		t.Fatalf("Failed to ask secret question: %v", err)
+	}
+
+	if mockPrompter.passwordIndex != 1 || answer != "s3cr3t" {
+		t.Errorf("Expected the password prompt to be used, got answer %v", answer)
+	}
+
+	generator.answers = map[string]interface{}{"app": "deployment", "token": answer}
+	var buf bytes.Buffer
+	generator.showCLIExample(&buf)
+
+	if strings.Contains(buf.String(), "s3cr3t") {
+		t.Errorf("CLI example must not contain the secret, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "--answer token="+SecretPlaceholder) {
+		t.Errorf("Expected redacted token in CLI example, got:\n%s", buf.String())
+	}
+
+	// Dumped answers leave the secret out, so that a replay asks for it again
+	buf.Reset()
+	if err := generator.writeAnswersJSON(&buf); err != nil {
+		t.Fatalf("Failed to write answers: %v", err)
+	}
+	if buf.String() != `{"app":"deployment"}`+"\n" {
+		t.Errorf("Expected the secret answer to be left out, got %q", buf.String())
+	}
+}
+
+func TestShowCLIExampleNoAnswers(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Empty answers
+	generator.answers = map[string]interface{}{}
+
+	// Should not panic with empty answers
+	generator.showCLIExample(&bytes.Buffer{})
+}
+
+func TestEchoSelections(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Each answer is echoed with its question key, not the prompt message
+	var buf bytes.Buffer
+	generator.prompter = prompt.NewScriptedPrompter([]prompt.Response{
+		prompt.SelectResponse("deployment"),
+		prompt.SearchResponse("sample-server-1"),
+		prompt.MultiSelectResponse("dev", "staging"),
+		prompt.MultiSelectResponse("dev-cluster-1", "staging-cluster-1"),
+	}, prompt.WithEcho(&buf))
+	if err := generator.collectAnswers(context.Background(), &Options{}, nil); err != nil {
+		t.Fatalf("Failed to collect answers: %v", err)
+	}
+
+	expected := "app: deployment\nappName: sample-server-1\nenv: dev, staging\ncluster: dev-cluster-1, staging-cluster-1\n"
+	if buf.String() != expected {
+		t.Errorf("Expected echoed selections:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteAnswersJSONRoundTrip(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Answer interactively with the answers dumped to stdout
+	generator.prompter = &MockPrompter{
+		selectResults:      []string{"deployment", "test-app"},
+		multiSelectResults: [][]string{{"dev", "staging"}, {"dev-cluster-1", "staging-cluster-1"}},
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	originalStdout := os.Stdout
+	os.Stdout = writer
+	err = generator.RunWithOptions(&Options{DumpAnswersJSON: true})
+	os.Stdout = originalStdout
+	_ = writer.Close()
+	if err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+	stdout, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read stdout: %v", err)
+	}
+
+	// Stdout holds nothing but the JSON object, the preview and messages go to stderr
+	if strings.Count(string(stdout), "\n") != 1 || !strings.Contains(string(stdout), `"env":["dev","staging"]`) {
+		t.Errorf("Expected only a single-line JSON object with arrays on stdout, got %q", stdout)
+	}
+
+	// The dump is accepted as answers for a non-interactive run
+	answers, err := config.LoadAnswers(bytes.NewReader(stdout), config.AnswersFormatJSON)
+	if err != nil {
+		t.Fatalf("Failed to load dumped answers: %v", err)
+	}
+	replay, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := replay.RunWithOptions(&Options{Answers: answers, SkipPrompt: true, NoPreview: true}); err != nil {
+		t.Fatalf("Failed to run with dumped answers: %v", err)
+	}
+
+	original, _ := json.Marshal(generator.Answers())
+	replayed, _ := json.Marshal(replay.Answers())
+	if string(original) != string(replayed) {
+		t.Errorf("Expected answers %s, got %s", original, replayed)
+	}
+}
+
+func TestShouldShowPreview(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	// Test with no preview config (should default to enabled)
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{}
+	if !generator.shouldShowPreview(options) {
+		t.Error("Expected preview to be enabled by default")
+	}
+
+	// Test with CLI flag disabled
+	options.NoPreview = true
+	if generator.shouldShowPreview(options) {
+		t.Error("Expected preview to be disabled with NoPreview flag")
+	}
+}
+
+func TestShouldShowPreviewWithConfig(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create config with preview disabled
+	configDir := filepath.Join(tempDir, ".yg")
+	err := os.MkdirAll(configDir, 0755)
+	if err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	configContent := `questions:
+  definitions:
+    app:
+      prompt: "What type of template?"
+      choices:
+        - deployment
+  order:
+    - app
+preview:
+  enabled: false
+`
+	configFile := filepath.Join(configDir, "config.yaml")
+	err = os.WriteFile(configFile, []byte(configContent), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Test with preview disabled in config
+	options := &Options{}
+	if generator.shouldShowPreview(options) {
+		t.Error("Expected preview to be disabled per config")
+	}
+
+	// Test CLI override - CLI should take precedence
+	options.NoPreview = false // CLI doesn't disable preview
+	if generator.shouldShowPreview(options) {
+		t.Error("Expected preview to be disabled per config even with CLI not set")
+	}
+}
+
+func TestShouldShowPreviewConfigEnabled(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create config with preview enabled
+	configDir := filepath.Join(tempDir, ".yg")
+	err := os.MkdirAll(configDir, 0755)
+	if err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	configContent := `questions:
+  definitions:
+    app:
+      prompt: "What type of template?"
+      choices:
+        - deployment
+  order:
+    - app
+preview:
+  enabled: true
+`
+	configFile := filepath.Join(configDir, "config.yaml")
+	err = os.WriteFile(configFile, []byte(configContent), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Test with preview enabled in config
+	options := &Options{}
+	if !generator.shouldShowPreview(options) {
+		t.Error("Expected preview to be enabled per config")
+	}
+
+	// Test CLI override takes precedence over config
+	options.NoPreview = true
+	if generator.shouldShowPreview(options) {
+		t.Error("Expected CLI NoPreview to override config enabled setting")
+	}
+}
+
+func TestShouldShowCLIExample(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `show_cli_example: false
+questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	if generator.shouldShowCLIExample(&Options{}) {
+		t.Error("Expected the CLI example to be disabled per config")
+	}
+	shown, hidden := true, false
+	if !generator.shouldShowCLIExample(&Options{CLIExample: &shown}) {
+		t.Error("Expected the CLI flag to override the config")
+	}
+
+	generator.config.ShowCLIExample = nil
+	if !generator.shouldShowCLIExample(&Options{}) {
+		t.Error("Expected the CLI example to be shown by default")
+	}
+	if generator.shouldShowCLIExample(&Options{CLIExample: &hidden}) {
+		t.Error("Expected --no-cli-example to disable the CLI example")
+	}
+}
+
+// writeTestFiles writes the given files (relative path -> content) under dir.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestDetermineTemplateAndMultiValuesWithTemplateName(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  template_name: "{{ .kind }}-{{ .tier }}"
+  order:
+    - kind
+    - tier
+    - env
+  definitions:
+    kind:
+      prompt: "Kind?"
+      choices: ["deployment", "job"]
+    tier:
+      prompt: "Tier?"
+      choices: ["critical", "standard"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]`,
+		".yg/_templates/deployment-critical.yaml": `path: {{.Questions.env}}
+filename: {{.Questions.kind}}-{{.Questions.tier}}.yaml
+---
+kind: {{.Questions.kind}}
+tier: {{.Questions.tier}}`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"kind": "deployment",
+		"tier": "critical",
+		"env":  []string{"dev"},
+	}
+
+	templateType, multiValues, err := generator.determineTemplateAndMultiValues()
+	if err != nil {
+		t.Fatalf("Failed to determine template: %v", err)
+	}
+
+	if templateType != "deployment-critical" {
+		t.Errorf("Expected template type 'deployment-critical', got %s", templateType)
+	}
+
+	if len(multiValues["env"]) != 1 {
+		t.Errorf("Expected env to be a multi-value question, got %v", multiValues)
+	}
+
+	if err := generator.generateFiles(); err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+
+	expectedFile := filepath.Join(tempDir, "dev", "deployment-critical.yaml")
+	if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
+		t.Errorf("Expected file %s was not generated", expectedFile)
+	}
+}
+
+func TestDetermineTemplateAndMultiValuesWithTemplateNameMissingAnswer(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  template_name: "{{ .kind }}-{{ .tier }}"
+  definitions:
+    kind:
+      prompt: "Kind?"
+      choices: ["deployment"]
+    tier:
+      prompt: "Tier?"
+      choices: ["critical"]`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"kind": "deployment",
+	}
+
+	if _, _, err := generator.determineTemplateAndMultiValues(); err == nil {
+		t.Error("Expected error when template_name references an unanswered question")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"appType": "microservice",
+		"appName": "sample-app-1",
+		"env":     []string{"dev"},
+		"cluster": []string{"dev-cluster-1", "dev-cluster-2"},
+	}
+
+	var buf bytes.Buffer
+	if err := generator.explain(&buf); err != nil {
+		t.Fatalf("Failed to explain: %v", err)
+	}
+
+	output := buf.String()
+	expectedLines := []string{
+		"Template: microservice",
+		`Source: template_question "appType"`,
+		"cluster: dev-cluster-1, dev-cluster-2",
+		"Combinations (2):",
+	}
+	for _, expected := range expectedLines {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected explanation to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+const testCountConfig = `templates:
+  service:
+    type: directory
+    path: service
+confirm:
+  warn_above: 10
+questions:
+  template_question: app
+  order: ["app", "env", "region"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["service"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]
+    region:
+      prompt: "Region?"
+      type:
+        multiple: true
+      choices: ["a", "b"]`
+
+func setupCountTestEnvironment(t *testing.T) string {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": testCountConfig,
+		".yg/_templates/service/.template-config.yaml": `output:
+  base_path: "{{.Questions.env}}/{{.Questions.region}}"
+  formats: [yaml, json]
+files:
+  app.yaml:
+    filename: app.yaml
+  debug.yaml:
+    filename: debug.yaml
+    enabled: "{{ eq .Questions.env \"dev\" }}"`,
+		".yg/_templates/service/app.yaml":   "env: {{.Questions.env}}",
+		".yg/_templates/service/debug.yaml": "debug: true",
+	})
+	return tempDir
+}
+
+func TestCount(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"app":    "service",
+		"env":    []string{"dev", "staging"},
+		"region": []string{"a", "b"},
+	}
+
+	combinations, files, err := generator.count()
+	if err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+
+	if combinations != 4 {
+		t.Errorf("Expected 4 combinations, got %d", combinations)
+	}
+
+	// dev: 2 files x 2 formats x 2 regions, staging: 1 file x 2 formats x 2 regions
+	if files != 12 {
+		t.Errorf("Expected 12 files, got %d", files)
+	}
+
+	var buf bytes.Buffer
+	generator.warnLargeGeneration(&buf)
+	if !strings.Contains(buf.String(), "Warning: 12 files will be generated from 4 combinations") {
+		t.Errorf("Expected large generation warning, got: %q", buf.String())
+	}
+}
+
+func TestTraceTemplateData(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"app":    "service",
+		"env":    []string{"dev", "staging"},
+		"region": []string{"a"},
+	}
+	var buf bytes.Buffer
+	generator.traceOutput = &buf
+
+	if err := generator.generateFiles(); err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+
+	targets, err := generator.resolveTargets()
+	if err != nil {
+		t.Fatalf("Failed to resolve targets: %v", err)
+	}
+
+	// Each trace is a "trace: <label>" line followed by the JSON template data
+	traces := strings.Split(strings.TrimPrefix(buf.String(), "trace: "), "\ntrace: ")
+	if len(traces) != len(targets) {
+		t.Fatalf("Expected %d traces, got %d:\n%s", len(targets), len(traces), buf.String())
+	}
+	for i, trace := range traces {
+		label, data, _ := strings.Cut(trace, "\n")
+		if label != targets[i].label {
+			t.Errorf("Expected trace label %q, got %q", targets[i].label, label)
+		}
+
+		var traced struct {
+			Questions map[string]interface{}
+		}
+		if err := json.Unmarshal([]byte(data), &traced); err != nil {
+			t.Fatalf("Failed to parse traced data: %v", err)
+		}
+		for key, value := range targets[i].combination {
+			if fmt.Sprint(traced.Questions[key]) != fmt.Sprint(value) {
+				t.Errorf("Expected traced %s = %v, got %v", key, value, traced.Questions[key])
+			}
+		}
+	}
+}
+
+func TestRunFromNestedDirectory(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	nestedDir := filepath.Join(tempDir, "services", "api")
+	if err := os.MkdirAll(nestedDir, 0o755); err != nil {
+		t.Fatalf("Failed to create nested directory: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(nestedDir)
+
+	// Both the config and the directory template come from the ancestor .yg
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator from nested directory: %v", err)
+	}
+	if root := generator.Config().Root; root != filepath.Join("..", "..") {
+		t.Errorf("Expected config root ../.., got %q", root)
+	}
+
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app":    "service",
+			"env":    []string{"dev"},
+			"region": []string{"a"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	// Output is written relative to the working directory
+	if _, err := os.Stat(filepath.Join(nestedDir, "dev", "a", "app.yaml")); err != nil {
+		t.Errorf("Expected dev/a/app.yaml under the working directory: %v", err)
+	}
+}
+
+func TestRunWithDataFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `data_files:
+  presets: presets.yaml
+questions:
+  order: ["app", "tier"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    tier:
+      prompt: "Tier?"
+      choices: ["small", "large"]`,
+		".yg/presets.yaml": `small:
+  cpu: 100m
+large:
+  cpu: "2"`,
+		".yg/_templates/deployment.yaml": `path: out
+filename: {{.Questions.tier}}.yaml
+---
+cpu: {{ (index .Data.presets .Questions.tier).cpu }}`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers:    map[string]interface{}{"app": "deployment", "tier": "large"},
+		SkipPrompt: true,
+		NoPreview:  true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "out", "large.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "cpu: 2" {
+		t.Errorf("Expected cpu from the large preset, got %q", content)
+	}
+}
+
+func TestRunWithDataInOutputPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `data_files:
+  release: release.yaml
+templates:
+  service:
+    type: directory
+    path: service
+  job:
+    type: file
+    path: job.yaml
+questions:
+  template_question: app
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["service", "job"]
+    env:
+      prompt: "Env?"
+      choices: ["dev"]`,
+		".yg/release.yaml": `channel: stable
+version: "1.2"`,
+		".yg/_templates/service/.template-config.yaml": `output:
+  base_path: "releases/{{.Data.release.channel}}/{{.Questions.env}}"
+files:
+  app.yaml:
+    filename: "app-{{.Data.release.version}}.yaml"`,
+		".yg/_templates/service/app.yaml": "env: {{.Questions.env}}",
+		".yg/_templates/job.yaml": `path: releases/{{.Data.release.channel}}/jobs
+filename: job-{{.Data.release.version}}.yaml
+---
+env: {{.Questions.env}}`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers:      map[string]interface{}{"env": "dev"},
+		SkipPrompt:   true,
+		NoPreview:    true,
+		AllTemplates: true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join("releases", "stable", "dev", "app-1.2.yaml"),
+		filepath.Join("releases", "stable", "jobs", "job-1.2.yaml"),
+	} {
+		if _, err := os.Stat(filepath.Join(tempDir, path)); err != nil {
+			t.Errorf("Expected %s to be generated: %v", path, err)
+		}
+	}
+}
+
+func TestRunWithValuesMetaAndEnvInOutputPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `data_files:
+  release: release.yaml
+templates:
+  service:
+    type: directory
+    path: service
+  job:
+    type: file
+    path: job.yaml
+questions:
+  template_question: app
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["service", "job"]
+    env:
+      prompt: "Env?"
+      choices: ["dev"]`,
+		".yg/release.yaml": `channel: stable
+version: "1.2"`,
+		".yg/_templates/service/.template-config.yaml": `output:
+  base_path: "releases/{{.Values.release.channel}}/{{.Env.YG_TEST_TEAM}}"
+files:
+  app.yaml:
+    filename: "{{.Meta.Template}}-{{.Values.release.version}}.yaml"`,
+		".yg/_templates/service/app.yaml": "env: {{.Questions.env}}",
+		".yg/_templates/job.yaml": `path: jobs/{{.Meta.Timestamp}}
+filename: job-{{.Values.release.version}}.yaml
+---
+env: {{.Questions.env}}`,
+	})
+	t.Setenv("YG_TEST_TEAM", "payments")
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers:      map[string]interface{}{"env": "dev"},
+		SkipPrompt:   true,
+		NoPreview:    true,
+		AllTemplates: true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	servicePath := filepath.Join("releases", "stable", "payments", "service-1.2.yaml")
+	if _, err := os.Stat(filepath.Join(tempDir, servicePath)); err != nil {
+		t.Errorf("Expected %s to be generated: %v", servicePath, err)
+	}
+
+	jobs, err := filepath.Glob(filepath.Join(tempDir, "jobs", "*", "job-1.2.yaml"))
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("Expected one job under a timestamp directory, got %v (%v)", jobs, err)
+	}
+	timestamp := filepath.Base(filepath.Dir(jobs[0]))
+	if _, err := time.Parse(template.TimestampFormat, timestamp); err != nil {
+		t.Errorf("Expected the job directory to be a timestamp, got %q: %v", timestamp, err)
+	}
+}
+
+func TestGenerateCombinationsFollowsConfigOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": `questions:
+  order: ["app", "region", "env", "tier"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    region:
+      prompt: "Region?"
+      type:
+        multiple: true
+      choices: ["a", "b"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]
+    tier:
+      prompt: "Tier?"
+      type:
+        multiple: true
+      choices: ["small", "large"]`})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{
+		"app":    "deployment",
+		"region": []string{"b", "a"},
+		"env":    []string{"staging", "dev"},
+		"tier":   []string{"large"},
+	}
+
+	describe := func() string {
+		var described []string
+		for _, combination := range generator.generateCombinations(generator.collectMultiValues()) {
+			described = append(described, fmt.Sprintf("%v/%v/%v", combination["region"], combination["env"], combination["tier"]))
+		}
+		return strings.Join(described, " ")
+	}
+
+	// Keys vary in config order (region before env) and values in their listed order
+	expected := "b/staging/large b/dev/large a/staging/large a/dev/large"
+	for i := 0; i < 20; i++ {
+		if got := describe(); got != expected {
+			t.Fatalf("Expected combinations %s, got %s", expected, got)
+		}
+	}
+}
+
+func TestRunWithCountDoesNotGenerate(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app":    "service",
+			"env":    []string{"dev"},
+			"region": []string{"a"},
+		},
+		SkipPrompt: true,
+		Count:      true,
+	}
+
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator with count: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "dev")); !os.IsNotExist(err) {
+		t.Error("Count mode should not generate files")
+	}
+
+	var buf bytes.Buffer
+	generator.warnLargeGeneration(&buf)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no warning below the threshold, got: %q", buf.String())
+	}
+}
+
+func TestListCombinations(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{
+		"app":     testAppTypeDeployment,
+		"appName": "my-app",
+		"env":     []string{"dev", "staging"},
+		"cluster": []string{"dev-cluster-1", "staging-cluster-1"},
+	}
+
+	var buf bytes.Buffer
+	if err := generator.listCombinations(&buf); err != nil {
+		t.Fatalf("Failed to list combinations: %v", err)
+	}
+
+	expected := `app=deployment appName=my-app env=dev cluster=dev-cluster-1
+app=deployment appName=my-app env=dev cluster=staging-cluster-1
+app=deployment appName=my-app env=staging cluster=dev-cluster-1
+app=deployment appName=my-app env=staging cluster=staging-cluster-1
+`
+	if buf.String() != expected {
+		t.Errorf("Expected combinations:\n%s\ngot:\n%s", expected, buf.String())
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "dev")); !os.IsNotExist(err) {
+		t.Error("Listing combinations should not generate files")
+	}
+}
+
+func TestRunWithMaxCombinations(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    string
+		limit     int
+		expectErr bool
+	}{
+		{"above option limit", "", 3, true},
+		{"at option limit", "", 4, false},
+		{"above config limit", "max_combinations: 2\n", 0, true},
+		{"option overrides config limit", "max_combinations: 2\n", 10, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := setupCountTestEnvironment(t)
+			writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": tc.config + testCountConfig})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			options := &Options{
+				Answers: map[string]interface{}{
+					"app":    "service",
+					"env":    []string{"dev", "staging"},
+					"region": []string{"a", "b"},
+				},
+				SkipPrompt:      true,
+				NoPreview:       true,
+				MaxCombinations: tc.limit,
+			}
+
+			err = generator.RunWithOptions(options)
+			_, statErr := os.Stat(filepath.Join(tempDir, "dev"))
+			if tc.expectErr {
+				if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), "--filter") {
+					t.Errorf("Expected combination limit error suggesting --filter, got: %v", err)
+				}
+				if !os.IsNotExist(statErr) {
+					t.Error("No files should be generated above the limit")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected generation below the limit to succeed, got: %v", err)
+			}
+			if statErr != nil {
+				t.Errorf("Expected files to be generated below the limit: %v", statErr)
+			}
+		})
+	}
+}
+
+func TestRunWithOutputNormalize(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `output:
+  normalize: true
+questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]`,
+		// Single file template content is trimmed, so it is rendered without a final newline
+		".yg/_templates/deployment.yaml": "path: out\nfilename: app.yaml\n---\nkind: Deployment\n\n\n",
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers:    map[string]interface{}{"app": "deployment"},
+		SkipPrompt: true,
+		NoPreview:  true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "out", "app.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "kind: Deployment\n" {
+		t.Errorf("Expected exactly one trailing newline, got %q", content)
+	}
+}
+
+func TestRunWithTrimBlocks(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `output:
+  trim_blocks: true
+questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      choices: ["dev", "prod"]`,
+		".yg/_templates/deployment.yaml": "path: out\nfilename: app.yaml\n---\nkind: Deployment\nspec:\n  {{ if eq .Questions.env \"prod\" }}\n  replicas: 3\n  {{ end }}\n  paused: false",
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	options := &Options{
+		Answers:    map[string]interface{}{"app": "deployment", "env": "dev"},
+		SkipPrompt: true,
+		NoPreview:  true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "out", "app.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "kind: Deployment\nspec:\n  paused: false" {
+		t.Errorf("Expected no blank line for the false conditional, got %q", content)
+	}
+}
+
+func TestRunWithDisallowEmpty(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `output:
+  disallow_empty: true
+questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      choices: ["dev", "prod"]`,
+		// The body is only rendered for prod
+		".yg/_templates/deployment.yaml": "path: {{.Questions.env}}\nfilename: app.yaml\n---\n{{ if eq .Questions.env \"prod\" }}kind: Deployment{{ end }}\n  \n",
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	testCases := []struct {
+		env      string
+		expected string // error fragment, empty when generation succeeds
+	}{
+		{env: "dev", expected: "template deployment rendered empty content for dev/app.yaml"},
+		{env: "prod"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.env, func(t *testing.T) {
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			err = generator.RunWithOptions(&Options{
+				Answers:    map[string]interface{}{"app": "deployment", "env": tc.env},
+				SkipPrompt: true,
+				NoPreview:  true,
+			})
+			_, statErr := os.Stat(filepath.Join(tempDir, tc.env, "app.yaml"))
+
+			if tc.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expected) {
+					t.Errorf("Expected error containing %q, got %v", tc.expected, err)
+				}
+				if !os.IsNotExist(statErr) {
+					t.Error("Expected the empty file not to be written")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected generation with content to succeed, got: %v", err)
+			}
+			if statErr != nil {
+				t.Errorf("Expected the file to be generated: %v", statErr)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   *config.OutputConfig
+		content  string
+		expected string
+	}{
+		{"disabled", nil, "a  \nb", "a  \nb"},
+		{"missing newline", &config.OutputConfig{Normalize: true}, "a\nb", "a\nb\n"},
+		{"extra newlines", &config.OutputConfig{Normalize: true}, "a\nb\n\n\n", "a\nb\n"},
+		{"trim whitespace", &config.OutputConfig{TrimTrailingWhitespace: true}, "a \t\nb  ", "a\nb"},
+		{"both", &config.OutputConfig{Normalize: true, TrimTrailingWhitespace: true}, "a \nb \n \n", "a\nb\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator := &Generator{config: &config.Config{Output: tc.output}}
+			file := generator.normalize(template.RenderedFile{Content: tc.content})
+			if file.Content != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, file.Content)
+			}
+		})
+	}
+}
+
+func TestGeneratePreviewKeepsBlankLines(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]`,
+		".yg/_templates/deployment.yaml": `path: out
+filename: app.yaml
+---
+kind: ConfigMap
+data:
+  script: |
+    echo start
+
+    echo done
+---
+kind: Service  
+name: web`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{"app": "deployment"}
+
+	var buf bytes.Buffer
+	if err := generator.generatePreview(&buf); err != nil {
+		t.Fatalf("Failed to generate preview: %v", err)
+	}
+
+	expected := `   4 |     echo start
+   5 |
+   6 |     echo done
+   7 | ---
+   8 | kind: Service  
+   9 | name: web
+`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected preview to keep blank lines and trailing spaces, got:\n%s", buf.String())
+	}
+}
+
+func TestGenerateWithAllFilesDisabled(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/_templates/service/.template-config.yaml": `output:
+  base_path: "{{.Questions.env}}/{{.Questions.region}}"
+files:
+  debug.yaml:
+    filename: debug.yaml
+    enabled: "{{ eq .Questions.env \"dev\" }}"`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{
+		"app":    "service",
+		"env":    []string{"staging"},
+		"region": []string{"a"},
+	}
+
+	var buf bytes.Buffer
+	if err := generator.generatePreview(&buf); err != nil {
+		t.Fatalf("Failed to generate preview: %v", err)
+	}
+	expected := "- no files generated for service: env=staging region=a (all files are disabled)"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected preview to contain %q, got:\n%s", expected, buf.String())
+	}
+
+	if err := generator.generateFiles(); err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "staging")); !os.IsNotExist(err) {
+		t.Errorf("Expected no output for the disabled combination, got err %v", err)
+	}
+}
+
+func TestExplainHeuristic(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app":     testAppTypeDeployment,
+			"appName": "test-app",
+			"env":     []string{"dev"},
+			"cluster": []string{"dev-cluster-1"},
+		},
+		SkipPrompt: true,
+		Explain:    true,
+	}
+
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator with explain: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := generator.explain(&buf); err != nil {
+		t.Fatalf("Failed to explain: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Source: heuristic") {
+		t.Errorf("Expected heuristic source, got:\n%s", buf.String())
+	}
+
+	// Explain mode must not generate files
+	unexpectedFile := filepath.Join(tempDir, "dev", "dev-cluster-1", "deployment", "test-app-deployment.yaml")
+	if _, err := os.Stat(unexpectedFile); !os.IsNotExist(err) {
+		t.Errorf("Explain mode should not generate %s", unexpectedFile)
+	}
+}
+
+func TestRunWithTemplateWhen(t *testing.T) {
+	testCases := []struct {
+		env      string
+		expected string
+		source   string
+	}{
+		{env: "prod", expected: "hardened", source: `Source: template_when rule 1 ({{ eq .Questions.env "prod" }})`},
+		{env: "dev", expected: "service", source: `Source: template_question "app"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.env, func(t *testing.T) {
+			tempDir := t.TempDir()
+			writeTestFiles(t, tempDir, map[string]string{
+				".yg/config.yaml": `questions:
+  template_question: app
+  template_when:
+    - when: '{{ eq .Questions.env "prod" }}'
+      template: hardened
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["service"]
+    env:
+      prompt: "Env?"
+      choices: ["dev", "prod"]`,
+				".yg/_templates/service.yaml": `path: {{.Questions.env}}
+filename: app.yaml
+---
+template: service`,
+				".yg/_templates/hardened.yaml": `path: {{.Questions.env}}
+filename: app.yaml
+---
+template: hardened`,
+			})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			options := &Options{
+				Answers:    map[string]interface{}{"app": "service", "env": tc.env},
+				SkipPrompt: true,
+				NoPreview:  true,
+			}
+			if err := generator.RunWithOptions(options); err != nil {
+				t.Fatalf("Failed to run generator: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(tempDir, tc.env, "app.yaml"))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+			if string(content) != "template: "+tc.expected {
+				t.Errorf("Expected the %s template, got %q", tc.expected, content)
+			}
+
+			var buf bytes.Buffer
+			if err := generator.explain(&buf); err != nil {
+				t.Fatalf("Failed to explain: %v", err)
+			}
+			if !strings.Contains(buf.String(), tc.source) {
+				t.Errorf("Expected %s, got:\n%s", tc.source, buf.String())
+			}
+		})
+	}
+}
+
+func TestGenerateFilesWithSkipWhen(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `skip_when: '{{ and (eq .Questions.env "staging") (eq .Questions.region "eu") }}'
+questions:
+  order:
+    - app
+    - env
+    - region
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]
+    region:
+      prompt: "Region?"
+      type:
+        multiple: true
+      choices: ["us", "eu"]`,
+		".yg/_templates/deployment.yaml": `path: {{.Questions.env}}/{{.Questions.region}}
+filename: deployment.yaml
+---
+env: {{.Questions.env}}`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{
+		"app":    testAppTypeDeployment,
+		"env":    []string{"dev", "staging"},
+		"region": []string{"us", "eu"},
+	}
+
+	if err := generator.generateFiles(); err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+
+	for _, dir := range []string{"dev/us", "dev/eu", "staging/us"} {
+		expectedFile := filepath.Join(tempDir, dir, "deployment.yaml")
+		if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
+			t.Errorf("Expected file %s was not generated", expectedFile)
+		}
+	}
+
+	skippedFile := filepath.Join(tempDir, "staging", "eu", "deployment.yaml")
+	if _, err := os.Stat(skippedFile); !os.IsNotExist(err) {
+		t.Errorf("Combination matching skip_when should not generate %s", skippedFile)
+	}
+}
+
+func TestRunWithOptionsNoColor(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+	t.Setenv("NO_COLOR", "")
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	defer func() { _ = prompt.NewPrompter() }()
+
+	if prompt.ColorDisabled() {
+		t.Fatal("Expected color to be enabled before running with --no-color")
+	}
+
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app":     testAppTypeDeployment,
+			"appName": "test-app",
+			"env":     []string{"dev"},
+			"cluster": []string{"dev-cluster-1"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+		NoColor:    true,
+	}
+
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	if !prompt.ColorDisabled() {
+		t.Error("Expected --no-color to disable prompt color")
+	}
+}
+
+const testAllTemplatesConfig = `templates:
+  deployment:
+    type: file
+    path: deployment.yaml
+  job:
+    type: file
+    path: job.yaml
+questions:
+  template_question: "appType"
+  order:
+    - appType
+    - appName
+    - env
+    - cluster
+  definitions:
+    appType:
+      prompt: "Select application type"
+      choices: ["deployment", "job"]
+    appName:
+      prompt: "What is the name of your item?"
+      choices: ["sample-app"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        multiple: true
+      choices: ["dev-cluster-1"]`
+
+func TestRunWithOptionsAllTemplates(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml":                testAllTemplatesConfig,
+		".yg/_templates/deployment.yaml": testDeploymentContent,
+		".yg/_templates/job.yaml":        testJobContent,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// The template question is not required in all-templates mode
+	options := &Options{
+		Answers: map[string]interface{}{
+			"appName": "sample-app",
+			"env":     []string{"dev"},
+			"cluster": []string{"dev-cluster-1"},
+		},
+		SkipPrompt:   true,
+		NoPreview:    true,
+		AllTemplates: true,
+	}
+
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator in all-templates mode: %v", err)
+	}
+
+	expectedFiles := []string{
+		filepath.Join(tempDir, "dev", "dev-cluster-1", "deployment", "sample-app-deployment.yaml"),
+		filepath.Join(tempDir, "dev", "dev-cluster-1", "job", "sample-app-job.yaml"),
+	}
+	for _, expectedFile := range expectedFiles {
+		if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
+			t.Errorf("Expected file %s was not generated", expectedFile)
+		}
+	}
+}
+
+func TestRunWithOptionsTemplateSubset(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml":                testAllTemplatesConfig,
+		".yg/_templates/deployment.yaml": testDeploymentContent,
+		".yg/_templates/job.yaml":        testJobContent,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers: map[string]interface{}{
+			"appName": "sample-app",
+			"env":     []string{"dev"},
+			"cluster": []string{"dev-cluster-1"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+		Templates:  []string{"job"},
+	}
+
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator with template subset: %v", err)
+	}
+
+	jobFile := filepath.Join(tempDir, "dev", "dev-cluster-1", "job", "sample-app-job.yaml")
+	if _, err := os.Stat(jobFile); os.IsNotExist(err) {
+		t.Errorf("Expected file %s was not generated", jobFile)
+	}
+
+	deploymentFile := filepath.Join(tempDir, "dev", "dev-cluster-1", "deployment", "sample-app-deployment.yaml")
+	if _, err := os.Stat(deploymentFile); !os.IsNotExist(err) {
+		t.Errorf("Template outside the subset should not generate %s", deploymentFile)
+	}
+
+	options.Templates = []string{"unknown"}
+	if err := generator.RunWithOptions(options); err == nil {
+		t.Error("Expected error for unknown template in subset")
+	}
+}
+
+func TestGenerateFilesRejectsEscapingPath(t *testing.T) {
+	tempDir := t.TempDir()
+	outputDir := filepath.Join(tempDir, "output")
+	writeTestFiles(t, outputDir, map[string]string{
+		".yg/config.yaml": `questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["escape"]`,
+		".yg/_templates/escape.yaml": `path: ../../{{.Questions.app}}
+filename: escaped.yaml
+---
+escaped: true`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(outputDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generator.answers = map[string]interface{}{"app": "escape"}
+
+	err = generator.generateFiles()
+	if err == nil {
+		t.Fatal("Expected error for output path escaping the output root")
+	}
+
+	if !strings.Contains(err.Error(), "../../escape/escaped.yaml") {
+		t.Errorf("Expected error to name the offending path, got: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "..", "escape")); !os.IsNotExist(err) {
+		t.Error("No directory should be created outside the output root")
+	}
+}
+
+func TestValidateOutputPath(t *testing.T) {
+	testCases := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"dev/cluster/app.yaml", false},
+		{"dev/../app.yaml", false},
+		{"./app.yaml", false},
+		{"..app/file.yaml", false},
+		{"../app.yaml", true},
+		{"dev/../../app.yaml", true},
+		{"/etc/passwd", true},
+	}
+
+	for _, tc := range testCases {
+		err := validateOutputPath(tc.path)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("validateOutputPath(%q): expected error %v, got %v", tc.path, tc.wantErr, err)
+		}
+	}
+}
+
+func setupKeepGoingEnvironment(t *testing.T) string {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  order:
+    - app
+    - env
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging", "production"]`,
+		// Rendering fails for the staging combination only
+		".yg/_templates/deployment.yaml": `path: {{.Questions.env}}
+filename: deployment.yaml
+---
+env: {{.Questions.env}}
+{{ if eq .Questions.env "staging" }}{{ index .Questions.env 99 }}{{ end }}`,
+	})
+	return tempDir
+}
+
+func TestGenerateFilesKeepGoing(t *testing.T) {
+	tempDir := setupKeepGoingEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app": testAppTypeDeployment,
+			"env": []string{"dev", "staging", "production"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+		KeepGoing:  true,
+	}
 
-				if parent == "dev" && strings.Contains(child, "dev-cluster") {
-					devChoicesFound++
-				}
-				if parent == "staging" && strings.Contains(child, "staging-cluster") {
-					stagingChoicesFound++
-				}
-			}
+	err = generator.RunWithOptions(options)
+	if err == nil {
+		t.Fatal("Expected error summarizing the failed combination")
+	}
+
+	if !strings.Contains(err.Error(), "1 of 3 combinations failed") {
+		t.Errorf("Expected failure summary, got: %v", err)
+	}
+
+	for _, env := range []string{"dev", "production"} {
+		expectedFile := filepath.Join(tempDir, env, "deployment.yaml")
+		if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
+			t.Errorf("Expected file %s was not generated", expectedFile)
 		}
 	}
 
-	if hierarchicalChoicesFound == 0 {
-		t.Error("Expected hierarchical choices with format 'parent: child', but none found")
+	if _, err := os.Stat(filepath.Join(tempDir, "staging", "deployment.yaml")); !os.IsNotExist(err) {
+		t.Error("Failed combination should not generate a file")
 	}
-	if devChoicesFound == 0 {
-		t.Error("No dev cluster choices found in dynamic selection")
+}
+
+func TestGenerateFilesWithoutKeepGoing(t *testing.T) {
+	tempDir := setupKeepGoingEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
 	}
-	if stagingChoicesFound == 0 {
-		t.Error("No staging cluster choices found in dynamic selection")
+
+	generator.answers = map[string]interface{}{
+		"app": testAppTypeDeployment,
+		"env": []string{"staging"},
+	}
+
+	if err := generator.generateFiles(); err == nil {
+		t.Error("Expected generation to abort on the failing combination")
 	}
 }
 
-func setupTestEnvironmentWithTemplateQuestion(t *testing.T) string {
-	tempDir := t.TempDir()
+func TestConfirmDefault(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
 
-	// Create .yg directory structure
-	templateDir := filepath.Join(tempDir, ".yg", "_templates")
-	err := os.MkdirAll(templateDir, 0755)
+	generator, err := New()
 	if err != nil {
-		t.Fatalf("Failed to create temp template directory: %v", err)
+		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	// Create config file with template_question specified
-	configFile := filepath.Join(templateDir, ".yg-config.yaml")
-	configContent := `questions:
-  template_question: "appType"  # Explicitly set which question provides template
-  order:
-    - appType
-    - appName
-    - env
-    - cluster
+	if generator.confirmDefault(&Options{}) {
+		t.Error("Expected confirmation default to be false without config")
+	}
+
+	generator.config.Confirm = &config.ConfirmConfig{Default: true, Message: "Generate now?"}
+	if !generator.confirmDefault(&Options{}) {
+		t.Error("Expected confirmation default from config")
+	}
+
+	cliDefault := false
+	if generator.confirmDefault(&Options{ConfirmDefault: &cliDefault}) {
+		t.Error("Expected CLI option to override config confirmation default")
+	}
+}
+
+func TestBoolSettingResolution(t *testing.T) {
+	enabled, disabled := true, false
+
+	// The flag wins when set, then the config, then the built-in default
+	// (preview enabled, confirmation declined)
+	testCases := []struct {
+		configured      *bool
+		flag            *bool
+		expectedPreview bool
+		expectedConfirm bool
+	}{
+		{configured: nil, flag: nil, expectedPreview: true, expectedConfirm: false},
+		{configured: nil, flag: &enabled, expectedPreview: true, expectedConfirm: true},
+		{configured: nil, flag: &disabled, expectedPreview: false, expectedConfirm: false},
+		{configured: &enabled, flag: nil, expectedPreview: true, expectedConfirm: true},
+		{configured: &enabled, flag: &enabled, expectedPreview: true, expectedConfirm: true},
+		{configured: &enabled, flag: &disabled, expectedPreview: false, expectedConfirm: false},
+		{configured: &disabled, flag: nil, expectedPreview: false, expectedConfirm: false},
+		{configured: &disabled, flag: &enabled, expectedPreview: true, expectedConfirm: true},
+		{configured: &disabled, flag: &disabled, expectedPreview: false, expectedConfirm: false},
+	}
+
+	describe := func(value *bool) string {
+		if value == nil {
+			return "unset"
+		}
+		return fmt.Sprint(*value)
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("config %s, flag %s", describe(tc.configured), describe(tc.flag)), func(t *testing.T) {
+			generator := &Generator{config: &config.Config{}}
+			if tc.configured != nil {
+				generator.config.Preview = &config.PreviewConfig{Enabled: *tc.configured}
+				generator.config.Confirm = &config.ConfirmConfig{Default: *tc.configured}
+			}
+
+			if got := generator.shouldShowPreview(&Options{Preview: tc.flag}); got != tc.expectedPreview {
+				t.Errorf("Expected preview %v, got %v", tc.expectedPreview, got)
+			}
+			if got := generator.confirmDefault(&Options{ConfirmDefault: tc.flag}); got != tc.expectedConfirm {
+				t.Errorf("Expected confirmation default %v, got %v", tc.expectedConfirm, got)
+			}
+
+			// NoPreview disables the preview whatever the config
+			if generator.shouldShowPreview(&Options{Preview: tc.flag, NoPreview: true}) {
+				t.Error("Expected NoPreview to disable the preview")
+			}
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	testCases := []struct {
+		name        string
+		configured  bool
+		flag        bool
+		mode        string
+		notTerminal bool
+		noColorEnv  string
+		expected    bool
+		wantErr     bool
+	}{
+		{name: "default", expected: true},
+		{name: "config", configured: true, expected: false},
+		{name: "flag", flag: true, expected: false},
+		{name: "environment", noColorEnv: "1", expected: false},
+		{name: "auto", mode: ColorAuto, expected: true},
+		{name: "auto without terminal", mode: ColorAuto, notTerminal: true, expected: false},
+		{name: "always without terminal", mode: ColorAlways, notTerminal: true, expected: true},
+		{name: "always over config and environment", mode: ColorAlways, configured: true, noColorEnv: "1", expected: true},
+		{name: "never", mode: ColorNever, expected: false},
+		{name: "always with flag", mode: ColorAlways, flag: true, wantErr: true},
+		{name: "invalid", mode: "sometimes", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tc.noColorEnv)
+			generator := &Generator{config: &config.Config{Theme: &config.ThemeConfig{NoColor: tc.configured}}}
+			got, err := generator.colorEnabled(&Options{NoColor: tc.flag, Color: tc.mode}, !tc.notTerminal)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got color enabled %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected color enabled %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunAppliesConfirmDefault(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/_templates/.yg-config.yaml": `confirm:
+  default: true
+  message: "Generate now?"
+questions:
+  order: ["app", "appName", "env", "cluster"]
   definitions:
-    appType:
-      prompt: "Select application type"
-      choices:
-        - deployment
-        - job
-        - microservice
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
     appName:
-      prompt: "What is the name of your item?"
-      type:
-        interactive: true
-      choices:
-        - sample-app-1
-        - sample-app-2
-        - sample-service-1
+      prompt: "Name?"
+      choices: ["test-app"]
     env:
-      prompt: "Which environment do you want to target?"
+      prompt: "Env?"
       type:
         multiple: true
-      choices:
-        - dev
-        - staging
-        - production
+      choices: ["dev"]
     cluster:
-      prompt: "Which target destinations do you want to deploy to?"
+      prompt: "Cluster?"
       type:
         multiple: true
-        dynamic:
-          dependency_questions: ["env"]
-      choices:
-        dev:
-          - dev-cluster-1
-          - dev-cluster-2
-        staging:
-          - staging-cluster-1
-          - staging-cluster-2`
+      choices: ["dev-cluster-1"]`,
+	})
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
 
-	err = os.WriteFile(configFile, []byte(configContent), 0644)
+	generator, err := New()
 	if err != nil {
-		t.Fatalf("Failed to write config file: %v", err)
+		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	// Create template files
-	deploymentTemplate := filepath.Join(templateDir, "deployment.yaml")
-	deploymentContent := testDeploymentContent
+	mockPrompter := &MockPrompter{}
+	generator.prompter = mockPrompter
 
-	err = os.WriteFile(deploymentTemplate, []byte(deploymentContent), 0644)
-	if err != nil {
-		t.Fatalf("Failed to write deployment template: %v", err)
+	if err := generator.RunWithOptions(&Options{NoPreview: true}); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
 	}
 
-	jobTemplate := filepath.Join(templateDir, "job.yaml")
-	jobContent := testJobContent
+	if !mockPrompter.confirmDefault {
+		t.Error("Expected configured confirmation default to be passed to the prompt")
+	}
 
-	err = os.WriteFile(jobTemplate, []byte(jobContent), 0644)
-	if err != nil {
-		t.Fatalf("Failed to write job template: %v", err)
+	if mockPrompter.confirmMessage != "Generate now?" {
+		t.Errorf("Expected configured confirmation message, got %q", mockPrompter.confirmMessage)
 	}
+}
 
-	microserviceTemplate := filepath.Join(templateDir, "microservice.yaml")
-	microserviceContent := `path: {{.Questions.env}}/{{.Questions.cluster}}/microservice
-filename: {{.Questions.appName}}-microservice.yaml
----
-apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: {{.Questions.appName}}
-  namespace: {{.Questions.env}}
-  labels:
-    type: microservice`
+func TestRunWithNoTemplateConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `templates:
+  deployment:
+    type: folder
+    path: missing
+questions:
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      choices: ["dev"]`,
+		".yg/_templates/deployment.yaml": "path: {{.Questions.env}}\nfilename: deployment.yaml\n---\nenv: {{.Questions.env}}",
+	})
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
 
-	err = os.WriteFile(microserviceTemplate, []byte(microserviceContent), 0644)
+	answers := map[string]interface{}{"app": testAppTypeDeployment, "env": "dev"}
+
+	generator, err := New()
 	if err != nil {
-		t.Fatalf("Failed to write microservice template: %v", err)
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.RunWithOptions(&Options{Answers: answers, SkipPrompt: true, NoPreview: true}); err == nil {
+		t.Fatal("Expected the broken templates entry to fail without --no-template-config")
 	}
 
-	return tempDir
+	generator, err = New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.RunWithOptions(&Options{
+		Answers: answers, SkipPrompt: true, NoPreview: true, NoTemplateConfig: true,
+	}); err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, "dev", "deployment.yaml"))
+	if err != nil {
+		t.Fatalf("Expected the file template to be generated: %v", err)
+	}
+	if string(content) != "env: dev" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+
+	err = generator.RunWithOptions(&Options{
+		Answers: answers, SkipPrompt: true, NoPreview: true, NoTemplateConfig: true, AllTemplates: true,
+	})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected ErrInvalidOptions combining with --all-templates, got %v", err)
+	}
 }
 
-func TestDetermineTemplateAndMultiValuesWithTemplateQuestion(t *testing.T) {
-	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+func TestRunWithTemplateFile(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	templateFile := filepath.Join(t.TempDir(), "draft.yaml")
+	if err := os.WriteFile(templateFile, []byte("path: drafts/{{.Questions.env}}\nfilename: {{.Questions.appName}}.yaml\n---\nname: {{.Questions.appName}}"), 0o644); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
@@ -779,39 +4000,77 @@ func TestDetermineTemplateAndMultiValuesWithTemplateQuestion(t *testing.T) {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	// Set up test answers - note that order is different from typical config
-	generator.answers = map[string]interface{}{
-		"appName": "test-app",                // This would be first non-multi in order
-		"appType": "microservice",            // This is specified as template_question
-		"env":     []string{"dev"},           // Multi-value
-		"cluster": []string{"dev-cluster-1"}, // Multi-value
+	// The template file is rendered instead of the template the app answer selects
+	err = generator.RunWithOptions(&Options{
+		Answers: map[string]interface{}{
+			"app":     testAppTypeDeployment,
+			"appName": "my-app",
+			"env":     []string{"dev"},
+			"cluster": []string{"dev-cluster-1"},
+		},
+		SkipPrompt:   true,
+		NoPreview:    true,
+		TemplateFile: templateFile,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
 	}
 
-	templateType, multiValues, err := generator.determineTemplateAndMultiValues()
+	content, err := os.ReadFile(filepath.Join(tempDir, "drafts", "dev", "my-app.yaml"))
 	if err != nil {
-		t.Fatalf("Failed to determine template and multi-values: %v", err)
+		t.Fatalf("Expected the template file to be rendered: %v", err)
 	}
-
-	// Should use appType (configured template_question) not appName (first non-multi)
-	if templateType != "microservice" {
-		t.Errorf("Expected template type 'microservice', got '%s'", templateType)
+	if string(content) != "name: my-app" {
+		t.Errorf("Unexpected content: %q", content)
 	}
 
-	if len(multiValues) != 2 {
-		t.Errorf("Expected 2 multi-value questions, got %d", len(multiValues))
+	// Namespaced outputs go under the base name of the template file, not its path
+	err = generator.RunWithOptions(&Options{
+		Answers: map[string]interface{}{
+			"app":     testAppTypeDeployment,
+			"appName": "my-app",
+			"env":     []string{"dev"},
+			"cluster": []string{"dev-cluster-1"},
+		},
+		SkipPrompt:          true,
+		NoPreview:           true,
+		TemplateFile:        templateFile,
+		NamespaceByTemplate: true,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions with --namespace-by-template failed: %v", err)
 	}
-
-	if _, exists := multiValues["env"]; !exists {
-		t.Error("Expected 'env' in multi-value questions")
+	if _, err := os.Stat(filepath.Join(tempDir, "draft", "drafts", "dev", "my-app.yaml")); err != nil {
+		t.Errorf("Expected the output under the template file's base name: %v", err)
 	}
 
-	if _, exists := multiValues["cluster"]; !exists {
-		t.Error("Expected 'cluster' in multi-value questions")
+	err = generator.RunWithOptions(&Options{
+		Answers: map[string]interface{}{"appName": "my-app"}, SkipPrompt: true, TemplateFile: templateFile, AllTemplates: true,
+	})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected ErrInvalidOptions combining with --all-templates, got %v", err)
 	}
 }
 
-func TestDetermineTemplateAndMultiValuesWithMissingTemplateQuestion(t *testing.T) {
-	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+func TestRunWithTemplateVariant(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  template_question: app
+  template_variant: env
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "prod"]`,
+		".yg/_templates/deployment.yaml":      "path: {{.Questions.env}}\nfilename: deployment.yaml\n---\nreplicas: 1",
+		".yg/_templates/deployment.prod.yaml": "path: {{.Questions.env}}\nfilename: deployment.yaml\n---\nreplicas: 3",
+	})
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
@@ -820,27 +4079,44 @@ func TestDetermineTemplateAndMultiValuesWithMissingTemplateQuestion(t *testing.T
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
-
-	// Missing the template question answer
-	generator.answers = map[string]interface{}{
-		"appName": "test-app",
-		"env":     []string{"dev"},
-		"cluster": []string{"dev-cluster-1"},
-		// appType is missing
-	}
-
-	_, _, err = generator.determineTemplateAndMultiValues()
-	if err == nil {
-		t.Error("Expected error when template question is not answered")
+	err = generator.RunWithOptions(&Options{
+		Answers:    map[string]interface{}{"app": testAppTypeDeployment, "env": []string{"dev", "prod"}},
+		SkipPrompt: true,
+		NoPreview:  true,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
 	}
 
-	if !strings.Contains(err.Error(), "template question 'appType' not answered") {
-		t.Errorf("Expected error about missing template question, got: %v", err)
+	// The prod variant replaces the template for env=prod only
+	for env, expected := range map[string]string{"dev": "replicas: 1", "prod": "replicas: 3"} {
+		content, err := os.ReadFile(filepath.Join(tempDir, env, "deployment.yaml"))
+		if err != nil {
+			t.Fatalf("Expected %s to be generated: %v", env, err)
+		}
+		if string(content) != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, env, content)
+		}
 	}
 }
 
-func TestDetermineTemplateAndMultiValuesWithInvalidTemplateAnswer(t *testing.T) {
-	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+const testSelectionCountConfig = `questions:
+  order: ["app", "env"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      min_select: 1
+      max_select: 2
+      choices: ["dev", "staging", "production"]`
+
+func TestAskQuestionSelectionCount(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": testSelectionCountConfig})
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
@@ -850,61 +4126,169 @@ func TestDetermineTemplateAndMultiValuesWithInvalidTemplateAnswer(t *testing.T)
 		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	// Template question has non-string answer
-	generator.answers = map[string]interface{}{
-		"appName": "test-app",
-		"appType": []string{"deployment", "job"}, // Array instead of string
-		"env":     []string{"dev"},
-		"cluster": []string{"dev-cluster-1"},
+	// The empty and oversized selections are rejected and the question asked again
+	mock := &MockPrompter{multiSelectResults: [][]string{{}, {"dev", "staging", "production"}, {"staging"}}}
+	generator.prompter = mock
+
+	answer, err := generator.askQuestion("env", generator.config.Questions.GetQuestions()["env"])
+	if err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
+	}
+	if selected, ok := answer.([]string); !ok || len(selected) != 1 || selected[0] != "staging" {
+		t.Errorf("Expected the valid selection, got %v", answer)
+	}
+	if mock.multiSelectIndex != 3 {
+		t.Errorf("Expected the question to be asked 3 times, got %d", mock.multiSelectIndex)
 	}
+}
 
-	_, _, err = generator.determineTemplateAndMultiValues()
-	if err == nil {
-		t.Error("Expected error when template question has non-string answer")
+func TestRunWithSelectionCount(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml":                testSelectionCountConfig,
+		".yg/_templates/deployment.yaml": "path: {{.Questions.env}}\nfilename: app.yaml\n---\nenv: {{.Questions.env}}",
+	})
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	testCases := []struct {
+		name     string
+		env      []string
+		expected string
+	}{
+		{name: "empty", env: []string{}, expected: "expected at least 1 selections, got 0"},
+		{name: "too many", env: []string{"dev", "staging", "production"}, expected: "expected at most 2 selections, got 3"},
 	}
 
-	if !strings.Contains(err.Error(), "template question 'appType' must have a single string answer") {
-		t.Errorf("Expected error about invalid template answer type, got: %v", err)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			err = generator.RunWithOptions(&Options{
+				Answers:    map[string]interface{}{"app": testAppTypeDeployment, "env": tc.env},
+				SkipPrompt: true,
+				NoPreview:  true,
+			})
+			if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected ErrInvalidOptions with %q, got %v", tc.expected, err)
+			}
+		})
 	}
 }
 
-func TestDetermineTemplateAndMultiValuesHeuristicFallback(t *testing.T) {
-	// Test that heuristic fallback still works when template_question is not set
-	tempDir := setupTestEnvironment(t) // This config has no template_question
+func TestAskQuestionAutoSelectSingle(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{".yg/config.yaml": `questions:
+  order: ["env", "cluster"]
+  definitions:
+    env:
+      prompt: "Environment?"
+      choices: ["dev", "staging"]
+    cluster:
+      prompt: "Cluster?"
+      auto_select_single: true
+      type:
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-cluster-1"]
+        staging: ["staging-cluster-1", "staging-cluster-2"]`})
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
 
-	generator, err := New()
-	if err != nil {
-		t.Fatalf("Failed to create generator: %v", err)
+	testCases := []struct {
+		env      string
+		expected string
+		prompted bool
+	}{
+		{env: "dev", expected: "dev-cluster-1", prompted: false},
+		{env: "staging", expected: "staging-cluster-1", prompted: true},
 	}
 
-	// Set up test answers
-	generator.answers = map[string]interface{}{
-		"app":     "deployment",
-		"appName": "test-app",
-		"env":     []string{"dev"},
-		"cluster": []string{"dev-cluster-1"},
+	for _, tc := range testCases {
+		t.Run(tc.env, func(t *testing.T) {
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			recorder := &promptRecorder{}
+			generator.prompter = recorder
+			generator.answers["env"] = tc.env
+			var output bytes.Buffer
+			generator.output = &output
+
+			answer, err := generator.askQuestion("cluster", generator.config.Questions.GetQuestions()["cluster"])
+			if err != nil {
+				t.Fatalf("Failed to ask question: %v", err)
+			}
+			if answer != tc.expected {
+				t.Errorf("Expected answer %q, got %v", tc.expected, answer)
+			}
+			if prompted := len(recorder.prompts) > 0; prompted != tc.prompted {
+				t.Errorf("Expected prompted=%v, got prompts %v", tc.prompted, recorder.prompts)
+			}
+			// The note is only written in verbose mode
+			if output.Len() != 0 {
+				t.Errorf("Expected no output without verbose mode, got %q", output.String())
+			}
+		})
 	}
 
-	templateType, multiValues, err := generator.determineTemplateAndMultiValues()
+	// In verbose mode the auto-selection is noted
+	generator, err := New()
 	if err != nil {
-		t.Fatalf("Failed to determine template and multi-values: %v", err)
+		t.Fatalf("Failed to create generator: %v", err)
 	}
-
-	// Should use first non-multi question (app) via heuristic
-	if templateType != "deployment" {
-		t.Errorf("Expected template type 'deployment', got '%s'", templateType)
+	var verbose bytes.Buffer
+	generator.verboseOutput = &verbose
+	generator.answers["env"] = "dev"
+	if _, err := generator.askQuestion("cluster", generator.config.Questions.GetQuestions()["cluster"]); err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
 	}
-
-	if len(multiValues) != 2 {
-		t.Errorf("Expected 2 multi-value questions, got %d", len(multiValues))
+	if verbose.String() != "Selected dev-cluster-1 for cluster (only choice)\n" {
+		t.Errorf("Expected the auto-selection to be noted in verbose mode, got %q", verbose.String())
 	}
 }
 
-func TestRunWithTemplateQuestion(t *testing.T) {
-	tempDir := setupTestEnvironmentWithTemplateQuestion(t)
+func TestRunWithHierarchicalDirectoryTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `templates:
+  service:
+    type: directory
+    path: service
+questions:
+  order: ["app", "env", "cluster"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["service"]
+    env:
+      prompt: "Environment?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        multiple: true
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-cluster-1", "dev-cluster-2"]
+        staging: ["staging-cluster-1"]`,
+		".yg/_templates/service/.template-config.yaml": `output:
+  base_path: "{{.Questions.env}}/{{.Questions.cluster}}"
+files:
+  app.yaml:
+    filename: "app.yaml"`,
+		".yg/_templates/service/app.yaml": "env: {{.Questions.env}}\ncluster: {{.Questions.cluster}}",
+	})
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
@@ -914,40 +4298,73 @@ func TestRunWithTemplateQuestion(t *testing.T) {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	// Mock the prompter
-	mockPrompter := &MockPrompter{
-		selectResults:      []string{"microservice"},               // appType selection
-		searchResults:      []string{"sample-service-1"},           // appName search
-		multiSelectResults: [][]string{{"dev"}, {"dev-cluster-1"}}, // env, then cluster
-		confirmResults:     []bool{true},                           // final confirmation
+	err = generator.RunWithOptions(&Options{
+		Answers: map[string]interface{}{
+			"app":     "service",
+			"env":     []string{"dev", "staging"},
+			"cluster": []string{"dev: dev-cluster-1", "dev: dev-cluster-2", "staging: staging-cluster-1"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
 	}
-	generator.prompter = mockPrompter
 
-	err = generator.Run()
+	// Each cluster is generated once, under its own environment
+	combinations, _, err := generator.count()
 	if err != nil {
-		t.Fatalf("Failed to run generator: %v", err)
+		t.Fatalf("Failed to count combinations: %v", err)
 	}
-
-	// Check if the microservice template was used
-	expectedFile := filepath.Join(tempDir, "dev", "dev-cluster-1", "microservice", "sample-service-1-microservice.yaml")
-	if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
-		t.Errorf("Expected microservice file %s was not generated", expectedFile)
+	if combinations != 3 {
+		t.Errorf("Expected 3 combinations, got %d", combinations)
 	}
 
-	// Read the generated file to verify it used the microservice template
-	content, err := os.ReadFile(expectedFile)
-	if err != nil {
-		t.Fatalf("Failed to read generated file: %v", err)
+	var generated []string
+	for _, env := range []string{"dev", "staging"} {
+		matches, _ := filepath.Glob(filepath.Join(env, "*", "app.yaml"))
+		generated = append(generated, matches...)
 	}
-
-	contentStr := string(content)
-	if !strings.Contains(contentStr, "type: microservice") {
-		t.Error("Generated file should contain 'type: microservice' from template")
+	expected := []string{
+		filepath.Join("dev", "dev-cluster-1", "app.yaml"),
+		filepath.Join("dev", "dev-cluster-2", "app.yaml"),
+		filepath.Join("staging", "staging-cluster-1", "app.yaml"),
+	}
+	if strings.Join(generated, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected files %v, got %v", expected, generated)
+	}
+	for _, path := range expected {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		parts := strings.Split(path, string(filepath.Separator))
+		want := "env: " + parts[0] + "\ncluster: " + parts[1]
+		if string(content) != want {
+			t.Errorf("Expected %s to contain %q, got %q", path, want, content)
+		}
 	}
 }
 
-func TestShowCLIExample(t *testing.T) {
-	tempDir := setupTestEnvironment(t)
+func TestAskQuestionDefaultFromFile(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  order: ["release", "channel"]
+  definitions:
+    release:
+      prompt: "Release?"
+      default_from_file:
+        path: out/release.yaml
+        yaml_path: .release.id
+    channel:
+      prompt: "Channel?"
+      default_from_file:
+        path: out/release.yaml
+        yaml_path: .release.channel
+      choices: ["stable", "beta"]`,
+		"out/release.yaml": "release:\n  id: r-42\n  channel: beta\n",
+	})
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
@@ -956,94 +4373,90 @@ func TestShowCLIExample(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
+	mock := &MockPrompter{}
+	generator.prompter = mock
+	questions := generator.config.Questions.GetQuestions()
 
-	// Set up test answers
-	generator.answers = map[string]interface{}{
-		"app":     "deployment",
-		"appName": "test-app",
-		"env":     []string{"dev", "staging"},
-		"cluster": []string{"dev-cluster-1", "staging-cluster-1"},
+	// A question without choices takes the value as-is
+	answer, err := generator.askQuestion("release", questions["release"])
+	if err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
+	}
+	if answer != "r-42" {
+		t.Errorf("Expected the release from the file, got %v", answer)
 	}
 
-	// Capture output
-	// Note: Since showCLIExample prints to stdout, we'd need to capture it
-	// For this test, we'll just verify it doesn't panic
-	generator.showCLIExample()
-
-	// The function should execute without error
-	// Visual verification would show:
-	// CLI Example:
-	// yg --yes --answer app=deployment --answer appName=test-app --answer env=dev,staging --answer cluster=dev-cluster-1,staging-cluster-1
-}
-
-func TestShowCLIExampleNoAnswers(t *testing.T) {
-	tempDir := setupTestEnvironment(t)
-	originalWd, _ := os.Getwd()
-	defer func() { _ = os.Chdir(originalWd) }()
-	_ = os.Chdir(tempDir)
-
-	generator, err := New()
+	// A question with choices pre-selects it
+	answer, err = generator.askQuestion("channel", questions["channel"])
 	if err != nil {
-		t.Fatalf("Failed to create generator: %v", err)
+		t.Fatalf("Failed to ask question: %v", err)
 	}
+	if answer != "beta" || mock.selectDefault != "beta" {
+		t.Errorf("Expected beta to be pre-selected, got default %q and answer %v", mock.selectDefault, answer)
+	}
+}
 
-	// Empty answers
-	generator.answers = map[string]interface{}{}
+const testKeyValueConfig = `questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    labels:
+      prompt: "Labels?"
+      type:
+        keyvalue: true`
 
-	// Should not panic with empty answers
-	generator.showCLIExample()
-}
+const testKeyValueTemplate = "path: out\nfilename: app.yaml\n---\nlabels:\n{{- range $key, $value := .Questions.labels }}\n  {{ $key }}: {{ $value }}\n{{- end }}\n"
+
+func TestRunWithKeyValue(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml":                testKeyValueConfig,
+		".yg/_templates/deployment.yaml": testKeyValueTemplate,
+	})
 
-func TestShouldShowPreview(t *testing.T) {
-	tempDir := setupTestEnvironment(t)
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
 	_ = os.Chdir(tempDir)
 
-	// Test with no preview config (should default to enabled)
 	generator, err := New()
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	options := &Options{}
-	if !generator.shouldShowPreview(options) {
-		t.Error("Expected preview to be enabled by default")
+	// Two pairs are added, the empty key is rejected, then no other pair is wanted
+	mockPrompter := &MockPrompter{
+		confirmResults: []bool{true, true, false},
+		inputResults:   []string{"team", "web", "", "tier", "frontend"},
 	}
-
-	// Test with CLI flag disabled
-	options.NoPreview = true
-	if generator.shouldShowPreview(options) {
-		t.Error("Expected preview to be disabled with NoPreview flag")
+	generator.prompter = mockPrompter
+	if err := generator.RunWithOptions(&Options{NoPreview: true}); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
 	}
-}
-
-func TestShouldShowPreviewWithConfig(t *testing.T) {
-	tempDir := t.TempDir()
 
-	// Create config with preview disabled
-	configDir := filepath.Join(tempDir, ".yg")
-	err := os.MkdirAll(configDir, 0755)
-	if err != nil {
-		t.Fatalf("Failed to create config directory: %v", err)
+	expected := map[string]string{"team": "web", "tier": "frontend"}
+	if !reflect.DeepEqual(generator.answers["labels"], expected) {
+		t.Errorf("Expected labels %v, got %v (%T)", expected, generator.answers["labels"], generator.answers["labels"])
+	}
+	if len(mockPrompter.inputErrors) != 1 {
+		t.Errorf("Expected the empty key to be rejected, got %d rejected inputs", len(mockPrompter.inputErrors))
 	}
 
-	configContent := `questions:
-  definitions:
-    app:
-      prompt: "What type of template?"
-      choices:
-        - deployment
-  order:
-    - app
-preview:
-  enabled: false
-`
-	configFile := filepath.Join(configDir, "config.yaml")
-	err = os.WriteFile(configFile, []byte(configContent), 0600)
+	content, err := os.ReadFile(filepath.Join(tempDir, "out", "app.yaml"))
 	if err != nil {
-		t.Fatalf("Failed to write config file: %v", err)
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "labels:\n  team: web\n  tier: frontend" {
+		t.Errorf("Expected the labels to be rendered, got %q", content)
 	}
+}
+
+func TestRunWithKeyValueSkipPrompt(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml":                testKeyValueConfig,
+		".yg/_templates/deployment.yaml": testKeyValueTemplate,
+	})
 
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
@@ -1053,46 +4466,47 @@ preview:
 	if err != nil {
 		t.Fatalf("Failed to create generator: %v", err)
 	}
-
-	// Test with preview disabled in config
-	options := &Options{}
-	if generator.shouldShowPreview(options) {
-		t.Error("Expected preview to be disabled per config")
+	options := &Options{
+		Answers:    map[string]interface{}{"app": "deployment", "labels": "a=1,b=2"},
+		SkipPrompt: true,
+		NoPreview:  true,
 	}
-
-	// Test CLI override - CLI should take precedence
-	options.NoPreview = false // CLI doesn't disable preview
-	if generator.shouldShowPreview(options) {
-		t.Error("Expected preview to be disabled per config even with CLI not set")
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
 	}
-}
 
-func TestShouldShowPreviewConfigEnabled(t *testing.T) {
-	tempDir := t.TempDir()
-
-	// Create config with preview enabled
-	configDir := filepath.Join(tempDir, ".yg")
-	err := os.MkdirAll(configDir, 0755)
+	content, err := os.ReadFile(filepath.Join(tempDir, "out", "app.yaml"))
 	if err != nil {
-		t.Fatalf("Failed to create config directory: %v", err)
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "labels:\n  a: 1\n  b: 2" {
+		t.Errorf("Expected the parsed labels to be rendered, got %q", content)
 	}
 
-	configContent := `questions:
-  definitions:
-    app:
-      prompt: "What type of template?"
-      choices:
-        - deployment
-  order:
-    - app
-preview:
-  enabled: true
-`
-	configFile := filepath.Join(configDir, "config.yaml")
-	err = os.WriteFile(configFile, []byte(configContent), 0600)
-	if err != nil {
-		t.Fatalf("Failed to write config file: %v", err)
+	generator, _ = New()
+	options.Answers["labels"] = "a"
+	if err := generator.RunWithOptions(options); err == nil {
+		t.Error("Expected an answer without = to be rejected")
 	}
+}
+
+func TestRunWithJSONConfigDirectoryTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.json": `{
+  "templates": {"service": {"type": "directory", "path": "service"}},
+  "questions": {
+    "template_question": "app",
+    "order": ["app", "env"],
+    "definitions": {
+      "app": {"prompt": "App?", "choices": ["service"]},
+      "env": {"prompt": "Env?", "choices": ["dev"]}
+    }
+  }
+}`,
+		".yg/_templates/service/.template-config.yaml": "files:\n  app.yaml:\n    filename: app.yaml\noutput:\n  base_path: \"{{ .Questions.env }}\"",
+		".yg/_templates/service/app.yaml":              "env: {{ .Questions.env }}",
+	})
 
 	originalWd, _ := os.Getwd()
 	defer func() { _ = os.Chdir(originalWd) }()
@@ -1103,15 +4517,29 @@ preview:
 		t.Fatalf("Failed to create generator: %v", err)
 	}
 
-	// Test with preview enabled in config
-	options := &Options{}
-	if !generator.shouldShowPreview(options) {
-		t.Error("Expected preview to be enabled per config")
+	// The templates section of the JSON config selects the directory template
+	err = generator.RunWithOptions(&Options{
+		Answers:    map[string]interface{}{"app": "service", "env": "dev"},
+		SkipPrompt: true,
+		NoPreview:  true,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, "dev", "app.yaml"))
+	if err != nil {
+		t.Fatalf("Expected the directory template to be rendered: %v", err)
+	}
+	if string(content) != "env: dev" {
+		t.Errorf("Unexpected content: %q", content)
 	}
 
-	// Test CLI override takes precedence over config
-	options.NoPreview = true
-	if generator.shouldShowPreview(options) {
-		t.Error("Expected CLI NoPreview to override config enabled setting")
+	// Linting loads the templates from the same section
+	warnings, err := generator.Lint()
+	if err != nil {
+		t.Fatalf("Failed to lint: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
 	}
 }
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	gotemplate "text/template"
+
+	"github.com/daylight55/yg/internal/template"
+)
+
+// defaultIndexTemplate lists the generated files when output.index sets no template.
+const defaultIndexTemplate = `files:
+{{- range .Files }}
+  - {{ . }}
+{{- end }}
+`
+
+// writeIndex writes the output.index file listing the files written by this
+// generation, sorted and relative to the index file's directory.
+func (g *Generator) writeIndex() error {
+	if g.config.Output == nil || g.config.Output.Index == nil {
+		return nil
+	}
+	index := g.config.Output.Index
+
+	source := index.Template
+	if source == "" {
+		source = defaultIndexTemplate
+	}
+	tmpl, err := gotemplate.New("index").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return fmt.Errorf("failed to parse output.index template: %w", err)
+	}
+
+	dir := filepath.Dir(index.Path)
+	files := make([]string, 0, len(g.written))
+	for _, path := range g.written {
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to index %s: %w", path, err)
+		}
+		files = append(files, filepath.ToSlash(relative))
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, map[string]interface{}{"Files": files}); err != nil {
+		return fmt.Errorf("failed to render output.index template: %w", err)
+	}
+
+	return writeFile(template.RenderedFile{Path: dir, Filename: filepath.Base(index.Path), Content: buf.String()})
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunWithOutputIndex(t *testing.T) {
+	testCases := map[string]struct {
+		index    string
+		path     string
+		expected string
+	}{
+		"default template": {
+			index:    "  index:\n    path: index.yaml",
+			path:     "index.yaml",
+			expected: "files:\n  - out/dev/app.yaml\n  - out/prod/app.yaml\n  - out/stg/app.yaml\n",
+		},
+		"kustomization": {
+			index: `  index:
+    path: out/kustomization.yaml
+    template: |
+      kind: Kustomization
+      resources:
+      {{- range .Files }}
+        - {{ . }}
+      {{- end }}`,
+			path:     filepath.Join("out", "kustomization.yaml"),
+			expected: "kind: Kustomization\nresources:\n  - dev/app.yaml\n  - prod/app.yaml\n  - stg/app.yaml\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			writeTestFiles(t, tempDir, map[string]string{
+				".yg/config.yaml": `output:
+` + tc.index + `
+questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["stg", "dev", "prod"]`,
+				".yg/_templates/deployment.yaml": "path: out/{{ .Questions.env }}\nfilename: app.yaml\n---\nenv: {{ .Questions.env }}",
+			})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			options := &Options{
+				Answers:    map[string]interface{}{"app": "deployment", "env": []string{"stg", "dev", "prod"}},
+				SkipPrompt: true,
+				NoPreview:  true,
+			}
+			if err := generator.RunWithOptions(options); err != nil {
+				t.Fatalf("Failed to run generator: %v", err)
+			}
+
+			content, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatalf("Failed to read index file: %v", err)
+			}
+			if string(content) != tc.expected {
+				t.Errorf("Expected index %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
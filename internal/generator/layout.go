@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/daylight55/yg/internal/template"
+)
+
+// Output layouts supported by the --output-layout option.
+const (
+	OutputLayoutNested = "nested"
+	OutputLayoutFlat   = "flat"
+)
+
+// outputLayout places rendered files in the output directory.
+// The nested layout keeps the rendered paths, while the flat layout drops every
+// file directly into the output directory and suffixes colliding filenames.
+type outputLayout struct {
+	flat bool
+	used map[string]bool
+}
+
+// newOutputLayout returns the layout with the given name; empty means nested.
+func newOutputLayout(name string) (*outputLayout, error) {
+	switch name {
+	case "", OutputLayoutNested:
+		return &outputLayout{}, nil
+	case OutputLayoutFlat:
+		return &outputLayout{flat: true, used: make(map[string]bool)}, nil
+	default:
+		return nil, fmt.Errorf("invalid output layout %q (expected %s or %s)", name, OutputLayoutNested, OutputLayoutFlat)
+	}
+}
+
+// place returns the file as it should be written under this layout.
+func (l *outputLayout) place(file template.RenderedFile) template.RenderedFile {
+	if !l.flat {
+		return file
+	}
+
+	filename := filepath.Base(file.Filename)
+	ext := filepath.Ext(filename)
+	stem := strings.TrimSuffix(filename, ext)
+	for n := 2; l.used[filename]; n++ {
+		filename = fmt.Sprintf("%s-%d%s", stem, n, ext)
+	}
+	l.used[filename] = true
+
+	file.Path = "."
+	file.Filename = filename
+	return file
+}
+
+// place places a file rendered for target according to layout, under a directory
+// named after the target's template when outputs are namespaced.
+func (g *Generator) place(layout *outputLayout, target renderTarget, file template.RenderedFile) template.RenderedFile {
+	file = layout.place(file)
+	if g.namespace {
+		file.Path = filepath.Join(g.templateNamespace(target.templateType), file.Path)
+	}
+	return file
+}
+
+// templateNamespace returns the directory the outputs of a template are namespaced
+// under. A template file given in the options is named by its path, so its base name
+// without the extension is used instead.
+func (g *Generator) templateNamespace(templateType string) string {
+	if g.templateFile == "" {
+		return templateType
+	}
+	base := filepath.Base(g.templateFile)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
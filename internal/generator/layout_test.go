@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daylight55/yg/internal/template"
+)
+
+func TestOutputLayoutFlatSuffixesCollisions(t *testing.T) {
+	layout, err := newOutputLayout(OutputLayoutFlat)
+	if err != nil {
+		t.Fatalf("Failed to create layout: %v", err)
+	}
+
+	expected := []string{"app.yaml", "app-2.yaml", "app-3.yaml", "db.yaml"}
+	inputs := []template.RenderedFile{
+		{Path: "dev/a", Filename: "app.yaml"},
+		{Path: "dev/b", Filename: "app.yaml"},
+		{Path: "staging", Filename: "nested/app.yaml"},
+		{Path: "dev/a", Filename: "db.yaml"},
+	}
+
+	for i, input := range inputs {
+		placed := layout.place(input)
+		if placed.Path != "." || placed.Filename != expected[i] {
+			t.Errorf("Expected ./%s, got %s/%s", expected[i], placed.Path, placed.Filename)
+		}
+	}
+}
+
+func TestOutputLayoutInvalid(t *testing.T) {
+	if _, err := newOutputLayout("tree"); err == nil {
+		t.Error("Expected error for unknown output layout")
+	}
+}
+
+func TestRunWithOutputLayouts(t *testing.T) {
+	testCases := []struct {
+		layout   string
+		expected []string
+	}{
+		{
+			layout: OutputLayoutNested,
+			expected: []string{
+				"dev/a/app.yaml", "dev/a/app.json", "dev/a/debug.yaml", "dev/a/debug.json",
+				"dev/b/app.yaml", "dev/b/app.json", "dev/b/debug.yaml", "dev/b/debug.json",
+			},
+		},
+		{
+			layout: OutputLayoutFlat,
+			expected: []string{
+				"app.yaml", "app.json", "debug.yaml", "debug.json",
+				"app-2.yaml", "app-2.json", "debug-2.yaml", "debug-2.json",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.layout, func(t *testing.T) {
+			tempDir := setupCountTestEnvironment(t)
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			options := &Options{
+				Answers: map[string]interface{}{
+					"app":    "service",
+					"env":    []string{"dev"},
+					"region": []string{"a", "b"},
+				},
+				SkipPrompt:   true,
+				NoPreview:    true,
+				OutputLayout: tc.layout,
+			}
+
+			if err := generator.RunWithOptions(options); err != nil {
+				t.Fatalf("Failed to run generator: %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if _, err := os.Stat(filepath.Join(tempDir, expected)); err != nil {
+					t.Errorf("Expected file %s was not generated: %v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunWithNamespaceByTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `templates:
+  deployment:
+    path: "{{.Questions.env}}"
+    filename: app.yaml
+    content: "kind: Deployment"
+  job:
+    path: "{{.Questions.env}}"
+    filename: app.yaml
+    content: "kind: Job"
+questions:
+  order: ["env"]
+  definitions:
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "prod"]`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	options := &Options{
+		Answers:             map[string]interface{}{"env": []string{"dev", "prod"}},
+		SkipPrompt:          true,
+		NoPreview:           true,
+		AllTemplates:        true,
+		NamespaceByTemplate: true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	// Both templates write env/app.yaml, each in its own subtree
+	expected := map[string]string{
+		"deployment/dev/app.yaml":  "kind: Deployment",
+		"deployment/prod/app.yaml": "kind: Deployment",
+		"job/dev/app.yaml":         "kind: Job",
+		"job/prod/app.yaml":        "kind: Job",
+	}
+	for path, content := range expected {
+		written, err := os.ReadFile(filepath.Join(tempDir, filepath.FromSlash(path)))
+		if err != nil {
+			t.Errorf("Expected file %s was not generated: %v", path, err)
+			continue
+		}
+		if string(written) != content {
+			t.Errorf("Expected %s to contain %q, got %q", path, content, written)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "dev")); !os.IsNotExist(err) {
+		t.Errorf("Expected no output outside the template subtrees, got err %v", err)
+	}
+}
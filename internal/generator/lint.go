@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daylight55/yg/internal/template"
+)
+
+// LintWarning describes a configuration problem that does not prevent generation.
+type LintWarning struct {
+	Question string
+	Message  string
+}
+
+// String formats the warning for display.
+func (w LintWarning) String() string {
+	return fmt.Sprintf("question %q: %s", w.Question, w.Message)
+}
+
+// Lint validates the config, then cross-references the configured questions against
+// every template and reports questions that are never used. Since the template that is rendered
+// depends on the answers, all templates are scanned.
+func (g *Generator) Lint() ([]LintWarning, error) {
+	if err := g.config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	templates, err := g.lintTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	used := g.questionsUsedByConfig()
+	for _, tmpl := range templates {
+		for key := range tmpl.QuestionReferences() {
+			used[key] = true
+		}
+	}
+
+	var warnings []LintWarning
+	for key := range g.config.Questions.GetQuestions() {
+		if !used[key] {
+			warnings = append(warnings, LintWarning{
+				Question: key,
+				Message:  "defined but not referenced by any template",
+			})
+		}
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Question < warnings[j].Question
+	})
+	return warnings, nil
+}
+
+// answerFieldPattern matches field references such as .kind in templates rendered with the answers as data.
+var answerFieldPattern = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// questionsUsedByConfig returns the questions the configuration itself depends on,
+// such as the template question and the dependencies of dynamic questions.
+func (g *Generator) questionsUsedByConfig() map[string]bool {
+	questions := g.config.Questions
+	used := template.QuestionReferences(g.config.SkipWhen)
+	for _, rule := range questions.TemplateWhen {
+		for key := range template.QuestionReferences(rule.When) {
+			used[key] = true
+		}
+	}
+	for _, validation := range g.config.Validations {
+		for key := range template.QuestionReferences(validation.Rule) {
+			used[key] = true
+		}
+	}
+
+	// template_name is rendered with the answers themselves as data, e.g. {{ .kind }}
+	for _, match := range answerFieldPattern.FindAllStringSubmatch(questions.GetTemplateName(), -1) {
+		used[match[1]] = true
+	}
+
+	if key := questions.GetTemplateQuestion(); key != "" {
+		used[key] = true
+	} else if questions.GetTemplateName() == "" {
+		// The heuristic uses the first single-value question in order
+		definitions := questions.GetQuestions()
+		for _, key := range questions.GetOrder() {
+			if question, exists := definitions[key]; exists && !question.IsMultiple() {
+				used[key] = true
+				break
+			}
+		}
+	}
+
+	for _, question := range questions.GetQuestions() {
+		if question.Type == nil || question.Type.Dynamic == nil {
+			continue
+		}
+		for _, dep := range question.Type.Dynamic.DependencyQuestions {
+			used[dep] = true
+		}
+	}
+
+	return used
+}
+
+// lintTemplates loads the templates to scan: those in the templates config section,
+// or every single file template found in .yg/_templates when the section is absent.
+func (g *Generator) lintTemplates() ([]*template.Template, error) {
+	var templates []*template.Template
+
+	if len(g.config.Templates) > 0 {
+		for name := range g.config.Templates {
+			tmpl, err := template.LoadConfiguredTemplate(g.config.Root, g.templateEntries(), name, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to load template %s: %w", name, err)
+			}
+			templates = append(templates, tmpl)
+		}
+		return templates, nil
+	}
+
+	templateDir := filepath.Join(g.config.Root, ".yg", "_templates")
+	entries, err := os.ReadDir(templateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory %s: %w", templateDir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		tmpl, err := template.LoadConfiguredTemplate(g.config.Root, nil, strings.TrimSuffix(name, ".yaml"), "")
+		if err != nil {
+			// Not every YAML file next to the templates is a template
+			continue
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
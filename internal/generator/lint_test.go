@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLintReportsUnusedQuestion(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `templates:
+  deployment:
+    type: file
+    path: deployment.yaml
+  job:
+    type: file
+    path: job.yaml
+questions:
+  template_question: app
+  order: ["app", "appName", "owner", "env", "cluster"]
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["deployment", "job"]
+    appName:
+      prompt: "Name?"
+      choices: ["sample"]
+    owner:
+      prompt: "Owner?"
+      choices: ["team-a"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev", "staging"]
+    cluster:
+      prompt: "Cluster?"
+      type:
+        multiple: true
+        dynamic:
+          dependency_questions: ["env"]
+      choices:
+        dev: ["dev-cluster"]
+        staging: ["staging-cluster"]`,
+		".yg/_templates/deployment.yaml": `path: {{.Questions.cluster}}
+filename: {{.Questions.appName}}.yaml
+---
+name: {{.Questions.appName}}`,
+		".yg/_templates/job.yaml": `path: jobs
+filename: job.yaml
+---
+cluster: {{ index .Questions "cluster" }}`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	warnings, err := generator.Lint()
+	if err != nil {
+		t.Fatalf("Failed to lint: %v", err)
+	}
+
+	// app is the template question and env is a dependency of cluster, so only owner is unused
+	if len(warnings) != 1 || warnings[0].Question != "owner" {
+		t.Errorf("Expected a single warning for owner, got %v", warnings)
+	}
+}
+
+func TestLintWithoutUnusedQuestions(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `questions:
+  template_name: "{{ .kind }}"
+  definitions:
+    kind:
+      prompt: "Kind?"
+      choices: ["deployment"]
+    env:
+      prompt: "Env?"
+      type:
+        multiple: true
+      choices: ["dev"]`,
+		".yg/_templates/deployment.yaml": `path: {{.Questions.env}}
+filename: deployment.yaml
+---
+env: {{.Questions.env}}`,
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	warnings, err := generator.Lint()
+	if err != nil {
+		t.Fatalf("Failed to lint: %v", err)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
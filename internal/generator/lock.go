@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GeneratedLockFile is the file in the .yg directory recording the combinations
+// generated with --skip-generated or --force.
+const GeneratedLockFile = ".generated.lock"
+
+// generatedLock records generated combinations by the hash of their template and answers.
+type generatedLock struct {
+	path    string
+	entries map[string]string // hash -> combination label
+}
+
+// loadGeneratedLock reads the lockfile at path. A missing lockfile is empty.
+func loadGeneratedLock(path string) (*generatedLock, error) {
+	lock := &generatedLock{path: path, entries: make(map[string]string)}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, label, _ := strings.Cut(line, " ")
+		lock.entries[hash] = label
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lockfile %s: %w", path, err)
+	}
+	return lock, nil
+}
+
+// contains reports whether the combination with the given hash was generated.
+func (l *generatedLock) contains(hash string) bool {
+	_, exists := l.entries[hash]
+	return exists
+}
+
+// add records the combination with the given hash as generated.
+func (l *generatedLock) add(hash, label string) {
+	l.entries[hash] = label
+}
+
+// save writes the lockfile, sorted by hash so that it diffs cleanly.
+func (l *generatedLock) save() error {
+	hashes := make([]string, 0, len(l.entries))
+	for hash := range l.entries {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	var b strings.Builder
+	b.WriteString("# Combinations generated by yg. Delete a line to generate it again.\n")
+	for _, hash := range hashes {
+		fmt.Fprintf(&b, "%s %s\n", hash, l.entries[hash])
+	}
+
+	if err := os.WriteFile(l.path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write lockfile %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// hash identifies the target by its template definition and combination of answers.
+func (t renderTarget) hash() (string, error) {
+	data, err := json.Marshal(struct {
+		TemplateType string
+		Template     interface{}
+		Answers      map[string]interface{}
+	}{t.templateType, t.template, t.combination})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", t.label, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pendingTargets returns the targets to generate and the number skipped because the
+// lockfile records them as generated.
+func (g *Generator) pendingTargets() ([]renderTarget, int, error) {
+	targets, err := g.resolveTargets()
+	if err != nil || !g.skipGenerated {
+		return targets, 0, err
+	}
+
+	pending := make([]renderTarget, 0, len(targets))
+	for _, target := range targets {
+		hash, err := target.hash()
+		if err != nil {
+			return nil, 0, err
+		}
+		if !g.lock.contains(hash) {
+			pending = append(pending, target)
+		}
+	}
+	return pending, len(targets) - len(pending), nil
+}
+
+// recordGenerated adds the target to the lock, if any.
+func (g *Generator) recordGenerated(target renderTarget) error {
+	if g.lock == nil {
+		return nil
+	}
+	hash, err := target.hash()
+	if err != nil {
+		return err
+	}
+	g.lock.add(hash, target.label)
+	return nil
+}
+
+// saveLock writes the lock, if any.
+func (g *Generator) saveLock() error {
+	if g.lock == nil {
+		return nil
+	}
+	return g.lock.save()
+}
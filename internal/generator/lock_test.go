@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunWithSkipGenerated(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	run := func(options *Options) {
+		t.Helper()
+		generator, err := New()
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		options.Answers = map[string]interface{}{
+			"app":    "service",
+			"env":    []string{"dev", "staging"},
+			"region": []string{"a"},
+		}
+		options.SkipPrompt = true
+		options.NoPreview = true
+		if err := generator.RunWithOptions(options); err != nil {
+			t.Fatalf("Failed to run generator: %v", err)
+		}
+	}
+
+	run(&Options{SkipGenerated: true})
+
+	lockContent, err := os.ReadFile(filepath.Join(tempDir, ".yg", GeneratedLockFile))
+	if err != nil {
+		t.Fatalf("Expected lockfile to be written: %v", err)
+	}
+	if entries := strings.Count(string(lockContent), "service: "); entries != 2 {
+		t.Errorf("Expected 2 locked combinations, got %d:\n%s", entries, lockContent)
+	}
+
+	// Deleted output of a locked combination is not generated again
+	devFile := filepath.Join(tempDir, "dev", "a", "app.yaml")
+	if err := os.Remove(devFile); err != nil {
+		t.Fatalf("Failed to remove generated file: %v", err)
+	}
+	run(&Options{SkipGenerated: true})
+	if _, err := os.Stat(devFile); !os.IsNotExist(err) {
+		t.Errorf("Expected locked combination to be skipped, got err %v", err)
+	}
+
+	// --force generates locked combinations anyway
+	run(&Options{SkipGenerated: true, Force: true})
+	if _, err := os.Stat(devFile); err != nil {
+		t.Errorf("Expected --force to regenerate the locked combination: %v", err)
+	}
+}
+
+func TestTargetHashChangesWithTemplate(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{"app": "service", "env": []string{"dev"}, "region": []string{"a"}}
+
+	hashTarget := func() string {
+		t.Helper()
+		targets, err := generator.resolveTargets()
+		if err != nil {
+			t.Fatalf("Failed to resolve targets: %v", err)
+		}
+		hash, err := targets[0].hash()
+		if err != nil {
+			t.Fatalf("Failed to hash target: %v", err)
+		}
+		return hash
+	}
+
+	before := hashTarget()
+	if again := hashTarget(); again != before {
+		t.Errorf("Expected a stable hash, got %s and %s", before, again)
+	}
+
+	templatePath := filepath.Join(tempDir, ".yg", "_templates", "service", "app.yaml")
+	if err := os.WriteFile(templatePath, []byte("environment: {{.Questions.env}}"), 0o600); err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+	if after := hashTarget(); after == before {
+		t.Error("Expected the hash to change with the template content")
+	}
+}
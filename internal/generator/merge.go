@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/daylight55/yg/internal/config"
+	"github.com/daylight55/yg/internal/template"
+	"gopkg.in/yaml.v3"
+)
+
+// mergeExisting merges the content of a YAML file into the file already at its output
+// path when output.merge is set. Other files, and files that do not exist yet, are
+// returned unchanged.
+func (g *Generator) mergeExisting(file template.RenderedFile) (template.RenderedFile, error) {
+	if g.config.Output == nil || g.config.Output.Merge == "" {
+		return file, nil
+	}
+	if g.config.Output.Merge != config.MergeDeep {
+		return file, fmt.Errorf("unsupported output.merge mode %q (expected %s)", g.config.Output.Merge, config.MergeDeep)
+	}
+
+	if !g.mergesInto(file) {
+		return file, nil
+	}
+
+	fullPath := filepath.Join(file.Path, file.Filename)
+	existing, err := os.ReadFile(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return file, fmt.Errorf("failed to read %s for merging: %w", fullPath, err)
+	}
+
+	merged, err := mergeYAML(existing, []byte(file.Content))
+	if err != nil {
+		return file, fmt.Errorf("failed to merge into %s: %w", fullPath, err)
+	}
+	file.Content = merged
+	return file, nil
+}
+
+// mergesInto reports whether the file is merged into an existing file at its output
+// path instead of replacing it.
+func (g *Generator) mergesInto(file template.RenderedFile) bool {
+	if g.config.Output == nil || g.config.Output.Merge == "" {
+		return false
+	}
+	switch filepath.Ext(file.Filename) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// mergeYAML deep-merges the rendered YAML document into the existing one. Mappings are
+// merged key by key; any other collision, including sequences, takes the rendered value.
+// The key order and comments of the existing document are kept.
+func mergeYAML(existing, rendered []byte) (string, error) {
+	base, err := decodeSingleDocument(existing)
+	if err != nil {
+		return "", fmt.Errorf("existing file: %w", err)
+	}
+	overlay, err := decodeSingleDocument(rendered)
+	if err != nil {
+		return "", fmt.Errorf("rendered content: %w", err)
+	}
+
+	switch {
+	case overlay == nil:
+		return string(existing), nil
+	case base == nil:
+		return string(rendered), nil
+	}
+	mergeNodes(base.Content[0], overlay.Content[0])
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(base); err != nil {
+		return "", fmt.Errorf("failed to encode merged YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode merged YAML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// decodeSingleDocument parses data as a single YAML document, returning nil when it is empty.
+func decodeSingleDocument(data []byte) (*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var doc yaml.Node
+	if err := decoder.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	var next yaml.Node
+	if err := decoder.Decode(&next); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("merging multi-document YAML is not supported")
+	}
+	return &doc, nil
+}
+
+// mergeNodes merges overlay into base in place.
+func mergeNodes(base, overlay *yaml.Node) {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		headComment, lineComment := base.HeadComment, base.LineComment
+		*base = *overlay
+		if base.HeadComment == "" {
+			base.HeadComment = headComment
+		}
+		if base.LineComment == "" {
+			base.LineComment = lineComment
+		}
+		return
+	}
+
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		if existing := mappingValue(base, key.Value); existing != nil {
+			mergeNodes(existing, value)
+			continue
+		}
+		base.Content = append(base.Content, key, value)
+	}
+}
+
+// mappingValue returns the value node of key in a mapping node, or nil if it is absent.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
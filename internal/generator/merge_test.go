@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeYAML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		existing string
+		rendered string
+		expected string
+	}{
+		{
+			name: "nested mappings",
+			existing: `apiVersion: v1
+kind: ConfigMap
+data:
+  # kept as is
+  LOG_LEVEL: info
+  TIMEOUT: "30"
+`,
+			rendered: `data:
+  LOG_LEVEL: debug
+  FEATURE_X: "on"
+`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+data:
+  # kept as is
+  LOG_LEVEL: debug
+  TIMEOUT: "30"
+  FEATURE_X: "on"
+`,
+		},
+		{
+			name:     "sequences are replaced",
+			existing: "args: [a, b]\nname: app\n",
+			rendered: "args:\n  - c\n",
+			expected: "args:\n  - c\nname: app\n",
+		},
+		{
+			name:     "empty existing file",
+			existing: "",
+			rendered: "name: app\n",
+			expected: "name: app\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := mergeYAML([]byte(tc.existing), []byte(tc.rendered))
+			if err != nil {
+				t.Fatalf("Failed to merge: %v", err)
+			}
+			if merged != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, merged)
+			}
+		})
+	}
+
+	if _, err := mergeYAML([]byte("a: 1\n---\nb: 2\n"), []byte("a: 2\n")); err == nil || !strings.Contains(err.Error(), "multi-document") {
+		t.Errorf("Expected multi-document error, got %v", err)
+	}
+}
+
+func TestRunWithDeepMerge(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": `output:
+  merge: deep
+questions:
+  definitions:
+    app:
+      prompt: "App?"
+      choices: ["configmap"]`,
+		".yg/_templates/configmap.yaml": "path: out\nfilename: configmap.yaml\n---\nmetadata:\n  labels:\n    team: platform\ndata:\n  FEATURE_X: \"on\"",
+		"out/configmap.yaml":            "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  LOG_LEVEL: info\n",
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.RunWithOptions(&Options{
+		Answers:    map[string]interface{}{"app": "configmap"},
+		SkipPrompt: true,
+		NoPreview:  true,
+	}); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "out", "configmap.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read merged file: %v", err)
+	}
+	expected := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  labels:
+    team: platform
+data:
+  LOG_LEVEL: info
+  FEATURE_X: "on"
+`
+	if string(content) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+	}
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrWouldOverwrite is returned when generating without prompts would change existing
+// files and --force is not given.
+var ErrWouldOverwrite = errors.New("existing files would be overwritten")
+
+// changedFiles returns the paths of the existing files whose content would be replaced.
+// Targets that fail to render are left for the generation to report.
+func (g *Generator) changedFiles() ([]string, error) {
+	targets, _, err := g.renderTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, target := range targets {
+		if target.err != nil {
+			continue
+		}
+		for _, file := range target.files {
+			// Merged files are updated on purpose
+			if g.mergesInto(file) {
+				continue
+			}
+			fullPath := filepath.Join(file.Path, file.Filename)
+			existing, err := os.ReadFile(fullPath)
+			if err == nil && string(existing) != file.Content {
+				changed = append(changed, fullPath)
+			}
+		}
+	}
+	return changed, nil
+}
+
+// confirmOverwrites asks for an extra confirmation, listing the existing files whose
+// content would change, and reports whether to proceed. Without prompts, overwriting
+// requires --force.
+func (g *Generator) confirmOverwrites(options *Options, w io.Writer) (bool, error) {
+	if options.Force {
+		return true, nil
+	}
+
+	changed, err := g.changedFiles()
+	if err != nil || len(changed) == 0 {
+		return err == nil, err
+	}
+
+	if options.SkipPrompt || options.PromptMissing {
+		return false, fmt.Errorf("%w: %s (use --force to overwrite them)", ErrWouldOverwrite, strings.Join(changed, ", "))
+	}
+
+	fmt.Fprintln(w, "These existing files will be overwritten:")
+	for _, path := range changed {
+		fmt.Fprintf(w, "  ~ %s\n", path)
+	}
+	confirmed, err := g.prompter.Confirm(fmt.Sprintf("%d files will be overwritten, continue?", len(changed)), false)
+	if err != nil {
+		return false, fmt.Errorf("failed to get overwrite confirmation: %w", err)
+	}
+	return confirmed, nil
+}
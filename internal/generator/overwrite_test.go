@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testOverwriteAnswers = map[string]interface{}{
+	"app":     testAppTypeDeployment,
+	"appName": "my-app",
+	"env":     []string{"dev"},
+	"cluster": []string{"dev-cluster-1"},
+}
+
+func TestRunWithOverwriteSkipPrompt(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	outputPath := filepath.Join("dev", "dev-cluster-1", "deployment", "my-app-deployment.yaml")
+	writeTestFiles(t, tempDir, map[string]string{outputPath: "edited: by hand"})
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	err = generator.RunWithOptions(&Options{Answers: testOverwriteAnswers, SkipPrompt: true, NoPreview: true})
+	if !errors.Is(err, ErrWouldOverwrite) {
+		t.Fatalf("Expected ErrWouldOverwrite, got %v", err)
+	}
+	if content, _ := os.ReadFile(outputPath); string(content) != "edited: by hand" {
+		t.Errorf("Expected the existing file to be kept, got %q", content)
+	}
+
+	err = generator.RunWithOptions(&Options{Answers: testOverwriteAnswers, SkipPrompt: true, NoPreview: true, Force: true})
+	if err != nil {
+		t.Fatalf("Expected --force to overwrite, got %v", err)
+	}
+	if content, _ := os.ReadFile(outputPath); string(content) == "edited: by hand" {
+		t.Error("Expected the existing file to be overwritten with --force")
+	}
+	// Without --skip-generated, --force does not record combinations in the lockfile
+	if _, err := os.Stat(filepath.Join(tempDir, ".yg", GeneratedLockFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no lockfile after --yes --force, got err %v", err)
+	}
+
+	// Generating the same content again changes nothing, so it needs no --force
+	generator, err = New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.RunWithOptions(&Options{Answers: testOverwriteAnswers, SkipPrompt: true, NoPreview: true}); err != nil {
+		t.Errorf("Expected unchanged files to be generated without --force, got %v", err)
+	}
+}
+
+func TestRunWithOverwriteConfirmation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		confirm     []bool
+		overwritten bool
+	}{
+		{name: "declined", confirm: []bool{true, false}, overwritten: false},
+		{name: "confirmed", confirm: []bool{true, true}, overwritten: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := setupTestEnvironment(t)
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			outputPath := filepath.Join("dev", "dev-cluster-1", "deployment", "my-app-deployment.yaml")
+			writeTestFiles(t, tempDir, map[string]string{outputPath: "edited: by hand"})
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+			mock := &MockPrompter{confirmResults: tc.confirm}
+			generator.prompter = mock
+
+			if err := generator.RunWithOptions(&Options{Answers: testOverwriteAnswers, NoPreview: true}); err != nil {
+				t.Fatalf("RunWithOptions failed: %v", err)
+			}
+
+			// The overwrite is confirmed separately from the generation
+			if mock.confirmIndex != 2 {
+				t.Errorf("Expected 2 confirmations, got %d", mock.confirmIndex)
+			}
+			if mock.confirmMessage != "1 files will be overwritten, continue?" {
+				t.Errorf("Unexpected overwrite confirmation %q", mock.confirmMessage)
+			}
+			content, _ := os.ReadFile(outputPath)
+			if overwritten := string(content) != "edited: by hand"; overwritten != tc.overwritten {
+				t.Errorf("Expected overwritten=%v, got content %q", tc.overwritten, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daylight55/yg/internal/template"
+)
+
+// recoverAnswers infers answers from dir, the output directory of an earlier generation,
+// by matching it and the files in it against the output paths of the candidate templates.
+// It writes the recovered answers and the questions it could not recover to w.
+func (g *Generator) recoverAnswers(dir string, given map[string]interface{}, w io.Writer) (map[string]interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry.Name())
+		}
+	}
+
+	candidates, err := g.recoveryCandidates(given)
+	if err != nil {
+		return nil, err
+	}
+
+	templateQuestion := g.templateQuestionKey()
+	for _, templateType := range candidates {
+		tmpl, err := g.loadTemplate(templateType, "")
+		if err != nil {
+			return nil, err
+		}
+		values, matched := matchTemplateOutput(tmpl, dir, files)
+		if !matched {
+			continue
+		}
+
+		questions := g.config.Questions.GetQuestions()
+		recovered := make(map[string]interface{}, len(values)+1)
+		if _, answered := given[templateQuestion]; templateQuestion != "" && !answered && len(g.templateTypes) == 0 {
+			recovered[templateQuestion] = templateType
+		}
+		for key, value := range values {
+			question, exists := questions[key]
+			if !exists {
+				continue
+			}
+			if question.IsMultiple() {
+				recovered[key] = []string{value}
+			} else {
+				recovered[key] = value
+			}
+		}
+
+		g.reportRecovery(w, dir, templateType, recovered, given)
+		return recovered, nil
+	}
+
+	return nil, fmt.Errorf("%s does not match the output path of template %s", dir, strings.Join(candidates, ", "))
+}
+
+// recoveryCandidates returns the templates that may have generated the output: the
+// selected templates, the answered template question, or every choice of the template question.
+func (g *Generator) recoveryCandidates(given map[string]interface{}) ([]string, error) {
+	if len(g.templateTypes) > 0 {
+		return g.templateTypes, nil
+	}
+
+	templateQuestion := g.templateQuestionKey()
+	if answer, answered := given[templateQuestion].(string); answered {
+		return []string{answer}, nil
+	}
+
+	question, exists := g.config.Questions.GetQuestions()[templateQuestion]
+	if !exists {
+		return nil, fmt.Errorf("cannot determine the template of the output; answer the template question or use --templates")
+	}
+	choices, err := question.GetChoices(given)
+	if err != nil || len(choices) == 0 {
+		return nil, fmt.Errorf("cannot determine the template of the output; answer %s", templateQuestion)
+	}
+	return choices, nil
+}
+
+// templateQuestionKey returns the question whose answer names the template: the
+// configured template question, or the first single-value question in order.
+func (g *Generator) templateQuestionKey() string {
+	if key := g.config.Questions.GetTemplateQuestion(); key != "" {
+		return key
+	}
+
+	questions := g.config.Questions.GetQuestions()
+	for _, questionKey := range g.config.Questions.GetOrder() {
+		if question, exists := questions[questionKey]; exists && !question.IsMultiple() {
+			return questionKey
+		}
+	}
+	return ""
+}
+
+// matchTemplateOutput matches dir against the output path of tmpl, and each file in dir
+// against its output filenames, returning the answers captured by every match. It
+// reports whether anything matched.
+func matchTemplateOutput(tmpl *template.Template, dir string, files []string) (map[string]string, bool) {
+	base := tmpl.Path
+	filenames := []string{tmpl.Filename}
+	if tmpl.Type == template.TypeDirectory {
+		base = tmpl.BasePath
+		filenames = filenames[:0]
+		for _, file := range tmpl.Files {
+			filenames = append(filenames, file.Filename)
+		}
+	}
+
+	answers := make(map[string]string)
+	merge := func(values map[string]string) {
+		for key, value := range values {
+			answers[key] = value
+		}
+	}
+
+	matched := false
+	if values, err := template.MatchOutputPath(base, dir); err == nil {
+		merge(values)
+		matched = true
+	}
+	for _, filename := range filenames {
+		for _, file := range files {
+			if values, err := template.MatchOutputPath(base+"/"+filename, filepath.Join(dir, file)); err == nil {
+				merge(values)
+				matched = true
+				break
+			}
+		}
+	}
+	return answers, matched
+}
+
+// reportRecovery writes the recovered answers to w, and the questions in order that
+// were neither recovered nor given.
+func (g *Generator) reportRecovery(w io.Writer, dir, templateType string, recovered, given map[string]interface{}) {
+	var found, missing []string
+	for _, questionKey := range g.config.Questions.GetOrder() {
+		if value, exists := recovered[questionKey]; exists {
+			if values, ok := value.([]string); ok {
+				value = strings.Join(values, ",")
+			}
+			found = append(found, fmt.Sprintf("%s=%v", questionKey, value))
+			continue
+		}
+		if _, exists := given[questionKey]; !exists && !g.isIgnoredTemplateQuestion(questionKey) {
+			missing = append(missing, questionKey)
+		}
+	}
+
+	fmt.Fprintf(w, "Recovered answers from %s (template %s): %s\n", dir, templateType, strings.Join(found, " "))
+	if len(missing) > 0 {
+		fmt.Fprintf(w, "Could not recover: %s\n", strings.Join(missing, ", "))
+	}
+}
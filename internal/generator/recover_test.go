@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecoverAnswers(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	writeTestFiles(t, tempDir, map[string]string{
+		"previous/dev/dev-cluster-1/deployment/sample-server-1-deployment.yaml": "kind: Deployment\n",
+	})
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	var buf bytes.Buffer
+	dir := filepath.Join("previous", "dev", "dev-cluster-1", "deployment")
+	recovered, err := generator.recoverAnswers(dir, nil, &buf)
+	if err != nil {
+		t.Fatalf("Failed to recover answers: %v", err)
+	}
+
+	expected := "Recovered answers from " + dir + " (template deployment): app=deployment appName=sample-server-1 env=dev cluster=dev-cluster-1\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+	if envs, ok := recovered["env"].([]string); !ok || len(envs) != 1 || envs[0] != "dev" {
+		t.Errorf("Expected env recovered as a multi-value answer, got %#v", recovered["env"])
+	}
+
+	// The recovered answers regenerate the same output
+	if err := generator.RunWithOptions(&Options{
+		SkipPrompt:    true,
+		NoPreview:     true,
+		FromAnswersOf: dir,
+	}); err != nil {
+		t.Fatalf("Failed to generate from recovered answers: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "dev", "dev-cluster-1", "deployment", "sample-server-1-deployment.yaml")); err != nil {
+		t.Errorf("Expected the output to be regenerated: %v", err)
+	}
+}
+
+func TestRecoverAnswersReportsMissing(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	writeTestFiles(t, tempDir, map[string]string{
+		// Without the generated file, only the directory answers are recovered
+		"previous/staging/staging-cluster-2/job/README.md": "notes\n",
+	})
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := generator.recoverAnswers(filepath.Join("previous", "staging", "staging-cluster-2", "job"), nil, &buf); err != nil {
+		t.Fatalf("Failed to recover answers: %v", err)
+	}
+	if !strings.Contains(buf.String(), "app=job env=staging cluster=staging-cluster-2") {
+		t.Errorf("Expected the directory answers to be recovered, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Could not recover: appName\n") {
+		t.Errorf("Expected appName to be reported as not recovered, got %q", buf.String())
+	}
+
+	if _, err := generator.recoverAnswers(filepath.Join("previous", "staging"), nil, &buf); err == nil {
+		t.Error("Expected an error for a directory matching no template")
+	}
+}
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"github.com/daylight55/yg/internal/template"
+)
+
+// renderedTarget is a pending target with the files it renders to, or the error
+// rendering it failed with.
+type renderedTarget struct {
+	renderTarget
+	files []template.RenderedFile
+	err   error
+}
+
+// renderCache holds the targets of a generation rendered once, so that the preview,
+// the tree, the diff, the overwrite check and the writing of the files share them.
+type renderCache struct {
+	targets []renderedTarget
+	skipped int // combinations skipped because the lock records them as generated
+}
+
+// renderTargets renders every pending target, once per generation. Errors are kept
+// per target for the caller to report.
+func (g *Generator) renderTargets() ([]renderedTarget, int, error) {
+	if g.rendered != nil {
+		return g.rendered.targets, g.rendered.skipped, nil
+	}
+
+	targets, skipped, err := g.pendingTargets()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	layout, err := newOutputLayout(g.outputLayout)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rendered := make([]renderedTarget, len(targets))
+	for i, target := range targets {
+		files, err := g.renderFiles(layout, target)
+		rendered[i] = renderedTarget{renderTarget: target, files: files, err: err}
+	}
+	g.rendered = &renderCache{targets: rendered, skipped: skipped}
+	return rendered, skipped, nil
+}
+
+// renderFiles renders target and places its files according to layout, then pipes
+// them through the transforms with their final path and normalizes them.
+func (g *Generator) renderFiles(layout *outputLayout, target renderTarget) ([]template.RenderedFile, error) {
+	g.trace(target)
+	renderResult, err := target.render()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]template.RenderedFile, len(renderResult.Files))
+	for i, file := range renderResult.Files {
+		file, err = g.transform(g.place(layout, target, file))
+		if err != nil {
+			return nil, err
+		}
+		files[i] = g.normalize(file)
+	}
+	return files, nil
+}
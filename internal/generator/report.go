@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/daylight55/yg/internal/template"
+	"gopkg.in/yaml.v3"
+)
+
+// Statuses of a combination in a report.
+const (
+	ReportGenerated = "generated"
+	ReportFailed    = "failed"
+)
+
+// Report records the outcome of each combination of a generation, with the answers
+// needed to generate it again.
+type Report struct {
+	Combinations []ReportEntry `json:"combinations"`
+}
+
+// ReportEntry is the outcome of a single combination.
+type ReportEntry struct {
+	Template string                 `json:"template"`
+	Label    string                 `json:"label"`
+	Answers  map[string]interface{} `json:"answers"`
+	Status   string                 `json:"status"` // ReportGenerated or ReportFailed
+	Error    string                 `json:"error,omitempty"`
+	File     string                 `json:"file,omitempty"`  // template file that failed to render
+	Phase    string                 `json:"phase,omitempty"` // part of the file that failed, see template.RenderError
+}
+
+// loadReport reads the report at path. It is decoded as YAML, a superset of JSON,
+// so that integer answers are read back as int like the answers they were generated from.
+func loadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	var report Report
+	if err := yaml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// save writes the report to path. Like the lockfile, it may contain secret answers.
+func (r *Report) save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	return nil
+}
+
+// recordOutcome adds the outcome of rendering and writing target to the report, if any.
+func (g *Generator) recordOutcome(target renderTarget, err error) {
+	if g.report == nil {
+		return
+	}
+
+	entry := ReportEntry{
+		Template: target.templateType,
+		Label:    target.label,
+		Answers:  target.combination,
+		Status:   ReportGenerated,
+	}
+	if err != nil {
+		entry.Status = ReportFailed
+		entry.Error = err.Error()
+		var renderErr *template.RenderError
+		if errors.As(err, &renderErr) {
+			entry.File = renderErr.File
+			entry.Phase = renderErr.Phase
+		}
+	}
+	g.report.Combinations = append(g.report.Combinations, entry)
+}
+
+// saveReport writes the report, if any.
+func (g *Generator) saveReport() error {
+	if g.report == nil {
+		return nil
+	}
+	return g.report.save(g.reportPath)
+}
+
+// retryTargets returns a target for each failed combination of the retried report.
+func (g *Generator) retryTargets() ([]renderTarget, error) {
+	// Templates are loaded once per template and variant
+	templates := make(map[[2]string]*template.Template)
+	var targets []renderTarget
+	for _, entry := range g.retry.Combinations {
+		if entry.Status != ReportFailed {
+			continue
+		}
+
+		key := [2]string{entry.Template, g.templateVariant(entry.Answers)}
+		tmpl, loaded := templates[key]
+		if !loaded {
+			var err error
+			tmpl, err = g.loadTemplate(key[0], key[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to load template: %w", err)
+			}
+			templates[key] = tmpl
+		}
+
+		targets = append(targets, renderTarget{
+			templateType: entry.Template,
+			template:     tmpl,
+			combination:  entry.Answers,
+			data:         g.data,
+			meta:         g.templateMeta(entry.Template),
+			label:        entry.Label,
+		})
+	}
+	return targets, nil
+}
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daylight55/yg/internal/template"
+)
+
+func TestRetryFromReport(t *testing.T) {
+	tempDir := setupKeepGoingEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	reportPath := filepath.Join(tempDir, "report.json")
+	err = generator.RunWithOptions(&Options{
+		Answers: map[string]interface{}{
+			"app": testAppTypeDeployment,
+			"env": []string{"dev", "staging", "production"},
+		},
+		SkipPrompt: true,
+		NoPreview:  true,
+		KeepGoing:  true,
+		Report:     reportPath,
+	})
+	if err == nil {
+		t.Fatal("Expected the staging combination to fail")
+	}
+
+	report, err := loadReport(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to load report: %v", err)
+	}
+	statuses := make(map[interface{}]string)
+	for _, entry := range report.Combinations {
+		statuses[entry.Answers["env"]] = entry.Status
+		if entry.Status == ReportFailed && (entry.File != "deployment.yaml" || entry.Phase != template.RenderPhaseContent) {
+			t.Errorf("Expected the failing file and phase in the report, got %+v", entry)
+		}
+		if entry.Template != testAppTypeDeployment || entry.Answers["app"] != testAppTypeDeployment {
+			t.Errorf("Expected the template and answers of the combination, got %+v", entry)
+		}
+	}
+	expected := map[interface{}]string{"dev": ReportGenerated, "staging": ReportFailed, "production": ReportGenerated}
+	if len(statuses) != len(expected) {
+		t.Fatalf("Expected %d combinations in the report, got %+v", len(expected), report.Combinations)
+	}
+	for env, status := range expected {
+		if statuses[env] != status {
+			t.Errorf("Expected %s to be %s, got %s", env, status, statuses[env])
+		}
+	}
+
+	// Fix the template and remove the generated files to see what the retry writes
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/_templates/deployment.yaml": "path: {{.Questions.env}}\nfilename: deployment.yaml\n---\nenv: {{.Questions.env}}\n",
+	})
+	for _, env := range []string{"dev", "production"} {
+		if err := os.RemoveAll(filepath.Join(tempDir, env)); err != nil {
+			t.Fatalf("Failed to remove output: %v", err)
+		}
+	}
+
+	generator, err = New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	retryReportPath := filepath.Join(tempDir, "retry.json")
+	if err := generator.RunWithOptions(&Options{
+		SkipPrompt: true,
+		NoPreview:  true,
+		RetryFrom:  reportPath,
+		Report:     retryReportPath,
+	}); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "staging", "deployment.yaml"))
+	if err != nil {
+		t.Fatalf("Expected the failed combination to be generated: %v", err)
+	}
+	if string(content) != "env: staging" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+	for _, env := range []string{"dev", "production"} {
+		if _, err := os.Stat(filepath.Join(tempDir, env)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be generated again", env)
+		}
+	}
+
+	retried, err := loadReport(retryReportPath)
+	if err != nil {
+		t.Fatalf("Failed to load retry report: %v", err)
+	}
+	if len(retried.Combinations) != 1 || retried.Combinations[0].Status != ReportGenerated {
+		t.Errorf("Expected only the retried combination in the report, got %+v", retried.Combinations)
+	}
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/daylight55/yg/internal/config"
+)
+
+// ReviewContinueOption is the option of the review list that accepts the answers.
+const ReviewContinueOption = "[Continue]"
+
+// shouldReview determines if the answers are reviewed before the confirmation based on
+// config and CLI options. It defaults to disabled and is skipped without prompts.
+func (g *Generator) shouldReview(options *Options) bool {
+	if options.SkipPrompt || options.PromptMissing || g.retry != nil {
+		return false
+	}
+
+	var configured *bool
+	if g.config.Review != nil {
+		configured = &g.config.Review.Enabled
+	}
+	return resolveBool(options.Review, configured, false)
+}
+
+// reviewAnswers lists every answer and asks the selected question again, until the
+// answers are accepted. Later answers that are no longer among their choices after an
+// edit are asked again as well.
+func (g *Generator) reviewAnswers() error {
+	questions := g.config.Questions.GetQuestions()
+	for {
+		var keys []string
+		options := []string{ReviewContinueOption}
+		for _, questionKey := range g.config.Questions.GetOrder() {
+			answer, exists := g.answers[questionKey]
+			if !exists || g.isIgnoredTemplateQuestion(questionKey) {
+				continue
+			}
+			keys = append(keys, questionKey)
+			options = append(options, fmt.Sprintf("%s: %s", questionKey, reviewValue(questions[questionKey], answer)))
+		}
+
+		selected, err := g.prompter.Select("Review answers, select one to edit:", options, ReviewContinueOption)
+		if err != nil {
+			return fmt.Errorf("failed to review answers: %w", err)
+		}
+		index := slices.Index(options, selected)
+		if index <= 0 {
+			return nil
+		}
+
+		if err := g.editAnswer(keys[index-1]); err != nil {
+			return err
+		}
+	}
+}
+
+// editAnswer asks questionKey again with its current answer pre-selected, then asks
+// the later questions whose answers are no longer among their choices.
+func (g *Generator) editAnswer(questionKey string) error {
+	questions := g.config.Questions.GetQuestions()
+
+	presets := g.presets
+	g.presets = map[string]interface{}{questionKey: g.answers[questionKey]}
+	for key, value := range presets {
+		if key != questionKey {
+			g.presets[key] = value
+		}
+	}
+	answer, err := g.askQuestion(questionKey, questions[questionKey])
+	g.presets = presets
+	if err != nil {
+		return fmt.Errorf("failed to ask question %s: %w", questionKey, err)
+	}
+	g.answers[questionKey] = answer
+
+	order := g.config.Questions.GetOrder()
+	for _, laterKey := range order[slices.Index(order, questionKey)+1:] {
+		current, exists := g.answers[laterKey]
+		question := questions[laterKey]
+		if !exists || !g.staleAnswer(question, current) {
+			continue
+		}
+		answer, err := g.askQuestion(laterKey, question)
+		if err != nil {
+			return fmt.Errorf("failed to ask question %s: %w", laterKey, err)
+		}
+		g.answers[laterKey] = answer
+	}
+	return nil
+}
+
+// staleAnswer reports whether a choice answer is no longer among the choices of its
+// question, such as a dynamic choice after the answer it depends on was edited.
+func (g *Generator) staleAnswer(question config.Question, answer interface{}) bool {
+	if question.IsSecret() || question.IsNumber() || question.IsBool() {
+		return false
+	}
+	choices, err := question.GetChoices(g.answers)
+	if err != nil || len(choices) == 0 {
+		return false
+	}
+
+	switch value := answer.(type) {
+	case string:
+		return len(config.FilterChoices(value, choices)) == 0
+	case []string:
+		return len(config.FilterChoices(value, choices)) != len(value)
+	}
+	return false
+}
+
+// reviewValue formats an answer for the review list, with secret answers redacted.
+func reviewValue(question config.Question, answer interface{}) string {
+	if question.IsSecret() {
+		return SecretPlaceholder
+	}
+	switch value := answer.(type) {
+	case []string:
+		return strings.Join(value, ",")
+	case map[string]string:
+		return config.FormatKeyValue(value)
+	case int:
+		return strconv.Itoa(value)
+	}
+	return fmt.Sprintf("%v", answer)
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/daylight55/yg/internal/config"
+)
+
+func TestRunWithReviewEdit(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	// Edit env in the review; the dev cluster is no longer a choice and is asked again
+	mockPrompter := &MockPrompter{
+		selectResults:      []string{testAppTypeDeployment, "env: dev", ReviewContinueOption},
+		multiSelectResults: [][]string{{"dev"}, {"dev-cluster-1"}, {"staging"}, {"staging-cluster-2"}},
+	}
+	generator.prompter = mockPrompter
+
+	review := true
+	if err := generator.RunWithOptions(&Options{NoPreview: true, Review: &review}); err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"app":     testAppTypeDeployment,
+		"appName": "sample-server-1",
+		"env":     []string{"staging"},
+		"cluster": []string{"staging-cluster-2"},
+	}
+	if !reflect.DeepEqual(generator.answers, expected) {
+		t.Errorf("Expected answers %v, got %v", expected, generator.answers)
+	}
+	if _, err := os.Stat(filepath.Join("staging", "staging-cluster-2", "deployment", "sample-server-1-deployment.yaml")); err != nil {
+		t.Errorf("Expected the edited combination to be generated: %v", err)
+	}
+	if _, err := os.Stat("dev"); !os.IsNotExist(err) {
+		t.Error("Expected the answers before the edit not to be generated")
+	}
+}
+
+func TestShouldReview(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name     string
+		config   *config.ReviewConfig
+		options  Options
+		expected bool
+	}{
+		{name: "default", expected: false},
+		{name: "config", config: &config.ReviewConfig{Enabled: true}, expected: true},
+		{name: "flag", options: Options{Review: &enabled}, expected: true},
+		{name: "flag overrides config", config: &config.ReviewConfig{Enabled: true}, options: Options{Review: &disabled}, expected: false},
+		{name: "skipped with --yes", config: &config.ReviewConfig{Enabled: true}, options: Options{Review: &enabled, SkipPrompt: true}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := &Generator{config: &config.Config{Review: tt.config}}
+			if got := generator.shouldReview(&tt.options); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/daylight55/yg/internal/template"
+)
+
+// TransformFileEnvVar names the environment variable holding the output path of the
+// file piped through a transform command.
+const TransformFileEnvVar = "YG_FILE"
+
+// transform pipes the content of file through each configured transform command in order.
+// Commands run with sh, reading the content on stdin and writing the result to stdout.
+func (g *Generator) transform(file template.RenderedFile) (template.RenderedFile, error) {
+	path := filepath.Join(file.Path, file.Filename)
+	for _, command := range g.config.Transforms {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdin = strings.NewReader(file.Content)
+		cmd.Env = append(os.Environ(), TransformFileEnvVar+"="+path)
+
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return file, fmt.Errorf("transform %q failed for %s: %w: %s", command, path, err, message)
+			}
+			return file, fmt.Errorf("transform %q failed for %s: %w", command, path, err)
+		}
+		file.Content = stdout.String()
+	}
+	return file, nil
+}
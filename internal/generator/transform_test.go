@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunWithTransforms(t *testing.T) {
+	testCases := []struct {
+		name       string
+		transforms string
+		expected   string
+		wantErr    string
+	}{
+		{
+			name:       "single",
+			transforms: `["tr a-z A-Z"]`,
+			expected:   "ENV: DEV",
+		},
+		{
+			name:       "pipeline in order",
+			transforms: `["tr a-z A-Z", "sed s/ENV/environment/"]`,
+			expected:   "environment: DEV",
+		},
+		{
+			name:       "output path in environment",
+			transforms: `["cat; printf ' # %s' \"$YG_FILE\""]`,
+			expected:   "env: dev # " + filepath.Join("dev", "a", "app.yaml"),
+		},
+		{
+			name:       "failing",
+			transforms: `["echo broken >&2; exit 3"]`,
+			wantErr:    "failed for " + filepath.Join("dev", "a", "app.json") + ": exit status 3: broken",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := setupCountTestEnvironment(t)
+			writeTestFiles(t, tempDir, map[string]string{
+				".yg/config.yaml": testCountConfig + "\ntransforms: " + tc.transforms,
+			})
+
+			originalWd, _ := os.Getwd()
+			defer func() { _ = os.Chdir(originalWd) }()
+			_ = os.Chdir(tempDir)
+
+			generator, err := New()
+			if err != nil {
+				t.Fatalf("Failed to create generator: %v", err)
+			}
+
+			options := &Options{
+				Answers: map[string]interface{}{
+					"app":    "service",
+					"env":    []string{"dev"},
+					"region": []string{"a"},
+				},
+				SkipPrompt: true,
+				NoPreview:  true,
+			}
+
+			err = generator.RunWithOptions(options)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+				}
+				if _, err := os.Stat(filepath.Join(tempDir, "dev", "a", "app.yaml")); !os.IsNotExist(err) {
+					t.Errorf("Expected no file to be written, got err %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to run generator: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(tempDir, "dev", "a", "app.yaml"))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+			if strings.TrimSpace(string(content)) != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestTransformsRunOncePerFile(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	logPath := filepath.Join(tempDir, "transforms.log")
+	writeTestFiles(t, tempDir, map[string]string{
+		".yg/config.yaml": testCountConfig + "\ntransforms: [\"printf '%s\\\\n' \\\"$YG_FILE\\\" >> " + logPath + "; cat\"]",
+	})
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// The preview, tree, diff and overwrite check share the files that are written
+	options := &Options{
+		Answers: map[string]interface{}{
+			"app":    "service",
+			"env":    []string{"dev"},
+			"region": []string{"a"},
+		},
+		SkipPrompt:          true,
+		PrintTree:           true,
+		Diff:                true,
+		NamespaceByTemplate: true,
+	}
+	if err := generator.RunWithOptions(options); err != nil {
+		t.Fatalf("Failed to run generator: %v", err)
+	}
+
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read transform log: %v", err)
+	}
+	expected := []string{
+		filepath.Join("service", "dev", "a", "app.json"),
+		filepath.Join("service", "dev", "a", "app.yaml"),
+		filepath.Join("service", "dev", "a", "debug.json"),
+		filepath.Join("service", "dev", "a", "debug.yaml"),
+	}
+	if got := strings.Split(strings.TrimSpace(string(log)), "\n"); strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected one transform per file with its final path %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// treeNode is a directory or file in the tree of output paths. Files have no children.
+type treeNode struct {
+	children map[string]*treeNode
+}
+
+// printTree writes the directories and files that would be generated as an indented
+// tree, like the tree command, without writing them.
+func (g *Generator) printTree(w io.Writer) error {
+	paths, err := g.outputPaths(w)
+	if err != nil {
+		return err
+	}
+
+	root := &treeNode{}
+	for _, path := range paths {
+		node := root
+		for _, part := range strings.Split(filepath.ToSlash(filepath.Clean(path)), "/") {
+			if part == "" || part == "." {
+				continue
+			}
+			if node.children == nil {
+				node.children = make(map[string]*treeNode)
+			}
+			child, exists := node.children[part]
+			if !exists {
+				child = &treeNode{}
+				node.children[part] = child
+			}
+			node = child
+		}
+	}
+
+	fmt.Fprintln(w, "\nTree:")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, ".")
+	dirs, files := writeTreeChildren(w, root, "")
+	fmt.Fprintf(w, "\n%d directories, %d files\n", dirs, files)
+	return nil
+}
+
+// writeTreeChildren writes the children of node in name order, prefixing each line
+// with the indentation of its parents, and returns the number of directories and files.
+func writeTreeChildren(w io.Writer, node *treeNode, prefix string) (int, int) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dirs, files := 0, 0
+	for i, name := range names {
+		branch, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, name)
+
+		child := node.children[name]
+		if child.children == nil {
+			files++
+			continue
+		}
+		dirs++
+		childDirs, childFiles := writeTreeChildren(w, child, prefix+indent)
+		dirs += childDirs
+		files += childFiles
+	}
+	return dirs, files
+}
+
+// outputPaths returns the paths of the rendered files of every combination, that is
+// the files that would be written. With keep-going, targets that fail to render are reported to w and skipped.
+func (g *Generator) outputPaths(w io.Writer) ([]string, error) {
+	targets, _, err := g.renderTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, target := range targets {
+		if target.err != nil {
+			if !g.keepGoing {
+				return nil, target.err
+			}
+			fmt.Fprintf(w, "! %s: %v\n", target.label, target.err)
+			continue
+		}
+		for _, file := range target.files {
+			paths = append(paths, filepath.Join(file.Path, file.Filename))
+		}
+	}
+	return paths, nil
+}
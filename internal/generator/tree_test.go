@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrintTree(t *testing.T) {
+	tempDir := setupTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{
+		"app":     testAppTypeDeployment,
+		"appName": "my-app",
+		"env":     []string{"dev"},
+		"cluster": []string{"dev-cluster-1", "dev-cluster-2"},
+	}
+
+	var buf bytes.Buffer
+	if err := generator.printTree(&buf); err != nil {
+		t.Fatalf("Failed to print tree: %v", err)
+	}
+
+	expected := `.
+└── dev
+    ├── dev-cluster-1
+    │   └── deployment
+    │       └── my-app-deployment.yaml
+    └── dev-cluster-2
+        └── deployment
+            └── my-app-deployment.yaml
+
+5 directories, 2 files
+`
+	if output := buf.String(); !strings.HasSuffix(output, expected) {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", expected, output)
+	}
+
+	// Nothing is written
+	if _, err := os.Stat("dev"); !os.IsNotExist(err) {
+		t.Errorf("Expected no files to be written, got err %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDebounce is how long Watch waits for rapid saves to settle before regenerating.
+const WatchDebounce = 300 * time.Millisecond
+
+// Watch generates the files with the answers given in options, then regenerates them
+// whenever a template changes until ctx is canceled. The diff of every changed file is
+// written to w. Template errors are reported to w and do not stop the watch.
+func (g *Generator) Watch(ctx context.Context, options *Options, w io.Writer) error {
+	presets, err := g.configure(options)
+	if err != nil {
+		return err
+	}
+	if err := g.useProvidedAnswers(options, presets); err != nil {
+		return err
+	}
+	if err := g.checkSelectionCounts(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	if err := g.checkValidations(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+
+	templateDir := filepath.Join(g.config.Root, ".yg", "_templates")
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch template directory %s: %w", templateDir, err)
+	}
+	defer func() { _ = watcher.Close() }()
+	if err := addWatchTree(watcher, templateDir); err != nil {
+		return fmt.Errorf("failed to watch template directory %s: %w", templateDir, err)
+	}
+
+	g.regenerate(w)
+	fmt.Fprintf(w, "Watching %s for changes...\n", templateDir)
+
+	changes := make(chan struct{})
+	go watchChanges(ctx, watcher, changes, w)
+	watchLoop(ctx, changes, WatchDebounce, func() { g.regenerate(w) })
+	return nil
+}
+
+// watchLoop calls regenerate once no change has been received for the debounce period,
+// so that a burst of saves triggers a single regeneration. It returns when ctx is canceled.
+func watchLoop(ctx context.Context, changes <-chan struct{}, debounce time.Duration, regenerate func()) {
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+			settled = time.After(debounce)
+		case <-settled:
+			settled = nil
+			regenerate()
+		}
+	}
+}
+
+// addWatchTree watches dir and every directory below it, since file system
+// notifications are not recursive.
+func addWatchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchChanges sends on changes whenever a file under the watched directories is
+// created, written, removed or renamed, until ctx is canceled. Directories created
+// while watching are watched too. Watcher errors are reported to w.
+func watchChanges(ctx context.Context, watcher *fsnotify.Watcher, changes chan<- struct{}, w io.Writer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "error: %v\n", err)
+			continue
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			// Permission and timestamp changes leave the templates as they are
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) &&
+				!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchTree(watcher, event.Name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+						fmt.Fprintf(w, "error: failed to watch %s: %v\n", event.Name, err)
+					}
+				}
+			}
+		}
+
+		select {
+		case changes <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// regenerate renders every combination and rewrites the files whose content changed,
+// writing their diff to w. Targets that fail to render are reported and left as they are.
+func (g *Generator) regenerate(w io.Writer) {
+	targets, err := g.resolveTargets()
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+		return
+	}
+
+	layout, err := newOutputLayout(g.outputLayout)
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+		return
+	}
+
+	changed := 0
+	for _, target := range targets {
+		files, err := g.renderFiles(layout, target)
+		if err != nil {
+			fmt.Fprintf(w, "error: %s: %v\n", target.label, err)
+			continue
+		}
+
+		for _, file := range files {
+			file, err = g.mergeExisting(file)
+			if err != nil {
+				fmt.Fprintf(w, "error: %s: %v\n", target.label, err)
+				continue
+			}
+			fullPath := filepath.Join(file.Path, file.Filename)
+
+			previous, err := os.ReadFile(fullPath)
+			if err == nil && string(previous) == file.Content {
+				continue
+			}
+			if err := writeFile(file); err != nil {
+				fmt.Fprintf(w, "error: %v\n", err)
+				continue
+			}
+
+			changed++
+			fmt.Fprintf(w, "--- %s\n+++ %s\n", fullPath, fullPath)
+			for _, line := range lineDiff(string(previous), file.Content) {
+				fmt.Fprintln(w, line)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%s: %d files updated\n", time.Now().Format("15:04:05"), changed)
+}
+
+// lineDiff returns the lines removed from a ("-" prefix) and added in b ("+" prefix),
+// in order, based on their longest common subsequence.
+func lineDiff(a, b string) []string {
+	splitLines := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	oldLines, newLines := splitLines(a), splitLines(b)
+
+	// common[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			i++
+			j++
+		case i < len(oldLines) && (j == len(newLines) || common[i+1][j] >= common[i][j+1]):
+			diff = append(diff, "-"+oldLines[i])
+			i++
+		default:
+			diff = append(diff, "+"+newLines[j])
+			j++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestWatchLoopDebouncesChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{})
+	regenerated := make(chan struct{}, 10)
+	var calls atomic.Int32
+	go watchLoop(ctx, changes, 50*time.Millisecond, func() {
+		calls.Add(1)
+		regenerated <- struct{}{}
+	})
+
+	// A burst of saves triggers a single regeneration
+	for i := 0; i < 3; i++ {
+		changes <- struct{}{}
+	}
+	select {
+	case <-regenerated:
+	case <-time.After(time.Second):
+		t.Fatal("Expected regeneration after the changes settled")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 regeneration for a burst of changes, got %d", n)
+	}
+
+	changes <- struct{}{}
+	select {
+	case <-regenerated:
+	case <-time.After(time.Second):
+		t.Fatal("Expected regeneration after another change")
+	}
+}
+
+func TestWatchChangesDetectsModification(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(path, []byte("a: 1"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer func() { _ = watcher.Close() }()
+	if err := addWatchTree(watcher, dir); err != nil {
+		t.Fatalf("Failed to watch directory: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{})
+	var buf bytes.Buffer
+	go watchChanges(ctx, watcher, changes, &buf)
+
+	// Each change is awaited, then the further events a save may cause are drained
+	expectChange := func(what string) {
+		t.Helper()
+		select {
+		case <-changes:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a change event after %s", what)
+		}
+		for {
+			select {
+			case <-changes:
+			case <-time.After(100 * time.Millisecond):
+				return
+			}
+		}
+	}
+
+	// Simulate saving a template
+	if err := os.WriteFile(path, []byte("a: 2"), 0o600); err != nil {
+		t.Fatalf("Failed to update file: %v", err)
+	}
+	expectChange("modifying a template")
+
+	// Files in a directory created while watching are watched too
+	subdir := filepath.Join(dir, "service")
+	if err := os.Mkdir(subdir, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	expectChange("creating a template directory")
+	if err := os.WriteFile(filepath.Join(subdir, "app.yaml"), []byte("b: 1"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	expectChange("adding a template to a new directory")
+}
+
+func TestRegenerateRewritesChangedFiles(t *testing.T) {
+	tempDir := setupCountTestEnvironment(t)
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	generator, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generator.answers = map[string]interface{}{
+		"app":    "service",
+		"env":    []string{"staging"},
+		"region": []string{"a"},
+	}
+
+	var buf bytes.Buffer
+	generator.regenerate(&buf)
+	output := filepath.Join(tempDir, "staging", "a", "app.yaml")
+	if content, err := os.ReadFile(output); err != nil || string(content) != "env: staging" {
+		t.Fatalf("Expected initial generation, got %q (err: %v)", content, err)
+	}
+
+	// Simulate saving a template
+	templatePath := filepath.Join(tempDir, ".yg", "_templates", "service", "app.yaml")
+	if err := os.WriteFile(templatePath, []byte("environment: {{.Questions.env}}"), 0o600); err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+
+	buf.Reset()
+	generator.regenerate(&buf)
+	if content, _ := os.ReadFile(output); string(content) != "environment: staging" {
+		t.Errorf("Expected regenerated content, got %q", content)
+	}
+	for _, fragment := range []string{"+++ staging/a/app.yaml", "-env: staging", "+environment: staging", "2 files updated"} {
+		if !strings.Contains(buf.String(), fragment) {
+			t.Errorf("Expected output to contain %q, got:\n%s", fragment, buf.String())
+		}
+	}
+
+	// A broken template is reported without stopping and leaves the output as it was
+	if err := os.WriteFile(templatePath, []byte("environment: {{.Questions.env"), 0o600); err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+	buf.Reset()
+	generator.regenerate(&buf)
+	if !strings.Contains(buf.String(), "error:") {
+		t.Errorf("Expected template error to be reported, got:\n%s", buf.String())
+	}
+	if content, _ := os.ReadFile(output); string(content) != "environment: staging" {
+		t.Errorf("Expected output to be kept on error, got %q", content)
+	}
+}
@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Questioner is implemented by prompters that can be told which question the following
+// prompts ask, such as to label the echoed answers with the question's key.
+type Questioner interface {
+	// SetQuestion sets the key of the question asked by the following prompts, or
+	// clears it when key is empty.
+	SetQuestion(key string)
+}
+
+// WithEcho writes each answer resolved at a prompt to w as a plain "key: value" line, so
+// that the selections appear in logs of interactive sessions. The key is the one set
+// with SetQuestion, or the prompt message when none is set. Multiple selections are
+// comma-separated, confirmations echoed as yes or no and passwords as <secret>.
+func WithEcho(w io.Writer) Option {
+	return func(o *options) {
+		o.echo = w
+	}
+}
+
+// echoer writes resolved answers to a writer, when it has one.
+type echoer struct {
+	w   io.Writer
+	key string
+}
+
+// SetQuestion sets the key the following answers are echoed with.
+func (e *echoer) SetQuestion(key string) {
+	e.key = key
+}
+
+// echo writes the answer to a prompt, labeled with the question key or else with the
+// message without its trailing colon or spaces.
+func (e *echoer) echo(message, answer string) {
+	if e.w == nil {
+		return
+	}
+	label := e.key
+	if label == "" {
+		label = strings.TrimRight(message, ": ")
+	}
+	fmt.Fprintf(e.w, "%s: %s\n", label, answer)
+}
+
+// echoConfirm writes a confirmation answer as yes or no.
+func (e *echoer) echoConfirm(message string, confirmed bool) {
+	answer := "no"
+	if confirmed {
+		answer = "yes"
+	}
+	e.echo(message, answer)
+}
@@ -0,0 +1,74 @@
+package prompt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWithEcho(t *testing.T) {
+	var buf bytes.Buffer
+	prompter := NewScriptedPrompter([]Response{
+		SelectResponse("deployment"),
+		SearchResponse("sample-server-1"),
+		MultiSelectResponse("dev", "staging"),
+		InputResponse("3"),
+		PasswordResponse("hunter2"),
+		ConfirmResponse(false),
+	}, WithEcho(&buf))
+
+	// Verify that both prompters can be told the question they ask
+	var _ Questioner = prompter
+	var _ Questioner = NewPrompter()
+
+	for _, ask := range []struct {
+		key    string
+		prompt func() error
+	}{
+		{"app", func() error { _, err := prompter.Select("App?", nil, ""); return err }},
+		{"appName", func() error { _, err := prompter.Search("Name:", nil, ""); return err }},
+		{"env", func() error { _, err := prompter.MultiSelect("Env?", nil, nil); return err }},
+		{"replicas", func() error { _, err := prompter.Input("Replicas?", nil); return err }},
+		{"token", func() error { _, err := prompter.Password("Token?"); return err }},
+		{"", func() error { _, err := prompter.Confirm("Proceed?", true); return err }},
+	} {
+		prompter.SetQuestion(ask.key)
+		if err := ask.prompt(); err != nil {
+			t.Fatalf("Failed to ask %s: %v", ask.key, err)
+		}
+	}
+
+	// Prompts asked without a question are labeled with their message
+	expected := `app: deployment
+appName: sample-server-1
+env: dev, staging
+replicas: 3
+token: <secret>
+Proceed?: no
+`
+	if buf.String() != expected {
+		t.Errorf("Expected echoed selections:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	// Failed prompts are not echoed
+	buf.Reset()
+	if _, err := prompter.Select("Again?", nil, ""); err == nil {
+		t.Fatal("Expected the exhausted script to fail")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing echoed for a failed prompt, got %q", buf.String())
+	}
+}
+
+func TestNewPrompterWithEcho(t *testing.T) {
+	var buf bytes.Buffer
+	prompter := NewPrompter(WithEcho(&buf))
+
+	prompter.SetQuestion("env")
+	prompter.echo("Env?", "prod")
+	if buf.String() != "env: prod\n" {
+		t.Errorf("Expected the answer echoed with its key, got %q", buf.String())
+	}
+
+	// Without WithEcho nothing is written
+	NewPrompter().echo("Env?", "prod")
+}
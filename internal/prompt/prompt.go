@@ -2,68 +2,182 @@
 package prompt
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/AlecAivazis/survey/v2/terminal"
 )
 
+// ErrInterrupted is returned when the user interrupts a prompt (e.g. Ctrl+C).
+var ErrInterrupted = errors.New("prompt interrupted")
+
 // PrompterInterface defines the interface for prompting users.
 type PrompterInterface interface {
-	Select(message string, options []string) (string, error)
-	MultiSelect(message string, options []string) ([]string, error)
-	Search(message string, options []string) (string, error)
-	Confirm(message string) (bool, error)
+	Select(message string, options []string, defaultValue string) (string, error)
+	MultiSelect(message string, options []string, defaults []string) ([]string, error)
+	Search(message string, options []string, defaultValue string) (string, error)
+	Confirm(message string, defaultValue bool) (bool, error)
+	Input(message string, validate func(string) error) (string, error)
+	Password(message string) (string, error)
+}
+
+// Describer is implemented by prompters that can show a description of each option.
+type Describer interface {
+	// SetDescriptions sets the descriptions, keyed by option, shown by the following
+	// select, multi-select and search prompts. Options without one show none.
+	SetDescriptions(descriptions map[string]string)
+}
+
+// Theme customizes the appearance of prompts.
+type Theme struct {
+	NoColor       bool   // Disable colored output
+	QuestionIcon  string // Icon shown before each question
+	QuestionColor string // Color format of the question icon (e.g. "cyan+b")
+	SelectIcon    string // Icon shown next to the focused option
+	SelectColor   string // Color format of the focused option
 }
 
 // Prompter implements PrompterInterface using survey.
-type Prompter struct{}
+type Prompter struct {
+	echoer
+	askOpts      []survey.AskOpt
+	descriptions map[string]string
+}
+
+// Option configures a prompter.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	output *os.File
+	echo   io.Writer
+}
+
+// WithOutput draws the prompts on out instead of stdout, such as to keep stdout for
+// machine-readable output.
+func WithOutput(out *os.File) Option {
+	return func(o *options) {
+		o.output = out
+	}
+}
 
 // NewPrompter creates a new Prompter instance.
-func NewPrompter() *Prompter {
-	// Disable color for consistent output
-	core.DisableColor = false
-	return &Prompter{}
+func NewPrompter(opts ...Option) *Prompter {
+	return NewPrompterWithTheme(nil, opts...)
 }
 
-// Select prompts the user to select a single option.
-func (p *Prompter) Select(message string, options []string) (string, error) {
+// NewPrompterWithTheme creates a new Prompter instance using the given theme.
+// Color is disabled when the theme requests it or the NO_COLOR environment variable is set.
+func NewPrompterWithTheme(theme *Theme, opts ...Option) *Prompter {
+	if theme == nil {
+		theme = &Theme{}
+	}
+	var settings options
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	core.DisableColor = theme.NoColor || os.Getenv("NO_COLOR") != ""
+
+	askOpts := []survey.AskOpt{survey.WithIcons(func(icons *survey.IconSet) {
+		applyIcon(&icons.Question, theme.QuestionIcon, theme.QuestionColor)
+		applyIcon(&icons.SelectFocus, theme.SelectIcon, theme.SelectColor)
+	})}
+	if settings.output != nil {
+		askOpts = append(askOpts, survey.WithStdio(os.Stdin, settings.output, os.Stderr))
+	}
+	return &Prompter{echoer: echoer{w: settings.echo}, askOpts: askOpts}
+}
+
+// SetColor turns colored prompt output on or off.
+func SetColor(enabled bool) {
+	core.DisableColor = !enabled
+}
+
+// ColorDisabled reports whether colored prompt output is disabled.
+func ColorDisabled() bool {
+	return core.DisableColor
+}
+
+// applyIcon overrides the icon text and format when they are set.
+func applyIcon(icon *survey.Icon, text, format string) {
+	if text != "" {
+		icon.Text = text
+	}
+	if format != "" {
+		icon.Format = format
+	}
+}
+
+// SetDescriptions sets the option descriptions of the following prompts.
+func (p *Prompter) SetDescriptions(descriptions map[string]string) {
+	p.descriptions = descriptions
+}
+
+// description returns the description of an option for survey, or nil when there are none.
+func (p *Prompter) description() func(value string, index int) string {
+	if len(p.descriptions) == 0 {
+		return nil
+	}
+	return func(value string, _ int) string {
+		return p.descriptions[value]
+	}
+}
+
+// Select prompts the user to select a single option, with defaultValue pre-selected when set.
+func (p *Prompter) Select(message string, options []string, defaultValue string) (string, error) {
 	var result string
 	prompt := &survey.Select{
-		Message: message,
-		Options: options,
+		Message:     message,
+		Options:     options,
+		Description: p.description(),
+	}
+	if defaultValue != "" {
+		prompt.Default = defaultValue
 	}
 
-	if err := survey.AskOne(prompt, &result); err != nil {
-		return "", fmt.Errorf("failed to get selection: %w", err)
+	if err := survey.AskOne(prompt, &result, p.askOpts...); err != nil {
+		return "", wrapError("failed to get selection", err)
 	}
 
+	p.echo(message, result)
 	return result, nil
 }
 
-// MultiSelect prompts the user to select multiple options.
-func (p *Prompter) MultiSelect(message string, options []string) ([]string, error) {
+// MultiSelect prompts the user to select multiple options, with defaults pre-selected.
+func (p *Prompter) MultiSelect(message string, options []string, defaults []string) ([]string, error) {
 	var result []string
 	prompt := &survey.MultiSelect{
-		Message: message,
-		Options: options,
+		Message:     message,
+		Options:     options,
+		Description: p.description(),
+	}
+	if len(defaults) > 0 {
+		prompt.Default = defaults
 	}
 
-	if err := survey.AskOne(prompt, &result); err != nil {
-		return nil, fmt.Errorf("failed to get multi-selection: %w", err)
+	if err := survey.AskOne(prompt, &result, p.askOpts...); err != nil {
+		return nil, wrapError("failed to get multi-selection", err)
 	}
 
+	p.echo(message, strings.Join(result, ", "))
 	return result, nil
 }
 
 // Search prompts the user with a searchable interface supporting text input and filtering.
-func (p *Prompter) Search(message string, options []string) (string, error) {
+// The defaultValue is pre-selected when set.
+func (p *Prompter) Search(message string, options []string, defaultValue string) (string, error) {
 	var result string
 
 	prompt := &survey.Select{
-		Message: message + " (type to search, ↓↑ to select):",
-		Options: options,
+		Message:     message + " (type to search, ↓↑ to select):",
+		Options:     options,
+		Description: p.description(),
 		Filter: func(filterValue string, optionValue string, _ int) bool {
 			// If no filter input, show all options
 			if filterValue == "" {
@@ -82,25 +196,75 @@ func (p *Prompter) Search(message string, options []string) (string, error) {
 			)
 		},
 	}
+	if defaultValue != "" {
+		prompt.Default = defaultValue
+	}
 
-	if err := survey.AskOne(prompt, &result); err != nil {
-		return "", fmt.Errorf("failed to get search result: %w", err)
+	if err := survey.AskOne(prompt, &result, p.askOpts...); err != nil {
+		return "", wrapError("failed to get search result", err)
 	}
 
+	p.echo(message, result)
 	return result, nil
 }
 
-// Confirm prompts the user for confirmation.
-func (p *Prompter) Confirm(message string) (bool, error) {
+// Confirm prompts the user for confirmation, using defaultValue when Enter is pressed.
+func (p *Prompter) Confirm(message string, defaultValue bool) (bool, error) {
 	var result bool
 	prompt := &survey.Confirm{
 		Message: message,
-		Default: false,
+		Default: defaultValue,
+	}
+
+	if err := survey.AskOne(prompt, &result, p.askOpts...); err != nil {
+		return false, wrapError("failed to get confirmation", err)
+	}
+
+	p.echoConfirm(message, result)
+	return result, nil
+}
+
+// Input prompts the user for free text, re-asking until validate accepts it.
+func (p *Prompter) Input(message string, validate func(string) error) (string, error) {
+	var result string
+	prompt := &survey.Input{
+		Message: message,
+	}
+
+	opts := p.askOpts
+	if validate != nil {
+		opts = append(append([]survey.AskOpt{}, p.askOpts...), survey.WithValidator(func(answer interface{}) error {
+			return validate(fmt.Sprintf("%v", answer))
+		}))
 	}
 
-	if err := survey.AskOne(prompt, &result); err != nil {
-		return false, fmt.Errorf("failed to get confirmation: %w", err)
+	if err := survey.AskOne(prompt, &result, opts...); err != nil {
+		return "", wrapError("failed to get input", err)
 	}
 
+	p.echo(message, result)
 	return result, nil
 }
+
+// Password prompts the user for a secret without echoing it.
+func (p *Prompter) Password(message string) (string, error) {
+	var result string
+	prompt := &survey.Password{
+		Message: message,
+	}
+
+	if err := survey.AskOne(prompt, &result, p.askOpts...); err != nil {
+		return "", wrapError("failed to get password", err)
+	}
+
+	p.echo(message, "<secret>")
+	return result, nil
+}
+
+// wrapError wraps a survey error, translating interrupts into ErrInterrupted.
+func wrapError(message string, err error) error {
+	if errors.Is(err, terminal.InterruptErr) {
+		return fmt.Errorf("%s: %w", message, ErrInterrupted)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -205,7 +205,7 @@ func TestCoreConfiguration(t *testing.T) {
 	// Test that NewPrompter configures the core correctly
 	NewPrompter()
 
-	// Verify color setting (should be false for consistent output)
+	// Verify color setting (enabled unless NO_COLOR is set)
 	if core.DisableColor {
 		t.Error("Expected core.DisableColor to be false after NewPrompter()")
 	}
@@ -309,3 +309,49 @@ func TestSearchScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestNoColorEnvironment(t *testing.T) {
+	defer func() { core.DisableColor = false }()
+
+	t.Setenv("NO_COLOR", "1")
+	NewPrompter()
+
+	if !ColorDisabled() {
+		t.Error("Expected color to be disabled when NO_COLOR is set")
+	}
+}
+
+func TestThemeNoColor(t *testing.T) {
+	defer func() { core.DisableColor = false }()
+
+	t.Setenv("NO_COLOR", "")
+	prompter := NewPrompterWithTheme(&Theme{NoColor: true, QuestionIcon: ">>"})
+
+	if !ColorDisabled() {
+		t.Error("Expected color to be disabled by theme")
+	}
+
+	if len(prompter.askOpts) == 0 {
+		t.Error("Expected theme icons to be applied as ask options")
+	}
+}
+
+func TestSetColor(t *testing.T) {
+	defer func() { core.DisableColor = false }()
+
+	t.Setenv("NO_COLOR", "")
+	NewPrompter()
+	if ColorDisabled() {
+		t.Fatal("Expected color to be enabled by default")
+	}
+
+	SetColor(false)
+	if !ColorDisabled() {
+		t.Error("Expected SetColor(false) to disable color")
+	}
+
+	SetColor(true)
+	if ColorDisabled() {
+		t.Error("Expected SetColor(true) to enable color")
+	}
+}
@@ -0,0 +1,162 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResponseType identifies which kind of prompt a scripted Response answers.
+type ResponseType string
+
+const (
+	ResponseSelect      ResponseType = "select"
+	ResponseMultiSelect ResponseType = "multiselect"
+	ResponseSearch      ResponseType = "search"
+	ResponseConfirm     ResponseType = "confirm"
+	ResponseInput       ResponseType = "input"
+	ResponsePassword    ResponseType = "password"
+)
+
+// Response represents a single scripted answer to a prompt.
+type Response struct {
+	Type      ResponseType
+	Value     string   // For select, search, input and password prompts
+	Values    []string // For multi-select prompts
+	Confirmed bool     // For confirm prompts
+}
+
+// SelectResponse returns a Response answering a select prompt.
+func SelectResponse(value string) Response {
+	return Response{Type: ResponseSelect, Value: value}
+}
+
+// MultiSelectResponse returns a Response answering a multi-select prompt.
+func MultiSelectResponse(values ...string) Response {
+	return Response{Type: ResponseMultiSelect, Values: values}
+}
+
+// SearchResponse returns a Response answering a search prompt.
+func SearchResponse(value string) Response {
+	return Response{Type: ResponseSearch, Value: value}
+}
+
+// ConfirmResponse returns a Response answering a confirm prompt.
+func ConfirmResponse(confirmed bool) Response {
+	return Response{Type: ResponseConfirm, Confirmed: confirmed}
+}
+
+// InputResponse returns a Response answering an input prompt.
+func InputResponse(value string) Response {
+	return Response{Type: ResponseInput, Value: value}
+}
+
+// PasswordResponse returns a Response answering a password prompt.
+func PasswordResponse(value string) Response {
+	return Response{Type: ResponsePassword, Value: value}
+}
+
+// ScriptedPrompter implements PrompterInterface by replaying a fixed script of responses.
+// It is intended for tests and embedders that need to drive prompts without a terminal.
+type ScriptedPrompter struct {
+	echoer
+	responses []Response
+	index     int
+}
+
+// NewScriptedPrompter creates a new ScriptedPrompter that answers prompts in order.
+// Options other than WithEcho have no effect on it.
+func NewScriptedPrompter(responses []Response, opts ...Option) *ScriptedPrompter {
+	var settings options
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return &ScriptedPrompter{echoer: echoer{w: settings.echo}, responses: responses}
+}
+
+// Remaining returns the number of responses that have not been consumed yet.
+func (p *ScriptedPrompter) Remaining() int {
+	return len(p.responses) - p.index
+}
+
+// Select returns the next scripted select response, which replaces any default.
+func (p *ScriptedPrompter) Select(message string, _ []string, _ string) (string, error) {
+	response, err := p.next(ResponseSelect, message)
+	if err != nil {
+		return "", err
+	}
+	p.echo(message, response.Value)
+	return response.Value, nil
+}
+
+// MultiSelect returns the next scripted multi-select response, which replaces any defaults.
+func (p *ScriptedPrompter) MultiSelect(message string, _ []string, _ []string) ([]string, error) {
+	response, err := p.next(ResponseMultiSelect, message)
+	if err != nil {
+		return nil, err
+	}
+	p.echo(message, strings.Join(response.Values, ", "))
+	return response.Values, nil
+}
+
+// Search returns the next scripted search response, which replaces any default.
+func (p *ScriptedPrompter) Search(message string, _ []string, _ string) (string, error) {
+	response, err := p.next(ResponseSearch, message)
+	if err != nil {
+		return "", err
+	}
+	p.echo(message, response.Value)
+	return response.Value, nil
+}
+
+// Confirm returns the next scripted confirm response.
+func (p *ScriptedPrompter) Confirm(message string, _ bool) (bool, error) {
+	response, err := p.next(ResponseConfirm, message)
+	if err != nil {
+		return false, err
+	}
+	p.echoConfirm(message, response.Confirmed)
+	return response.Confirmed, nil
+}
+
+// Input returns the next scripted input response, failing if validate rejects it.
+func (p *ScriptedPrompter) Input(message string, validate func(string) error) (string, error) {
+	response, err := p.next(ResponseInput, message)
+	if err != nil {
+		return "", err
+	}
+	if validate != nil {
+		if err := validate(response.Value); err != nil {
+			return "", fmt.Errorf("scripted input %q for prompt %q is invalid: %w", response.Value, message, err)
+		}
+	}
+	p.echo(message, response.Value)
+	return response.Value, nil
+}
+
+// Password returns the next scripted password response.
+func (p *ScriptedPrompter) Password(message string) (string, error) {
+	response, err := p.next(ResponsePassword, message)
+	if err != nil {
+		return "", err
+	}
+	p.echo(message, "<secret>")
+	return response.Value, nil
+}
+
+// next consumes the next response, checking that it matches the expected type.
+func (p *ScriptedPrompter) next(expected ResponseType, message string) (Response, error) {
+	if p.index >= len(p.responses) {
+		return Response{}, fmt.Errorf("script exhausted: no response for %s prompt %q", expected, message)
+	}
+
+	response := p.responses[p.index]
+	if response.Type != expected {
+		return Response{}, fmt.Errorf(
+			"scripted response %d is %s, but %s prompt %q was asked",
+			p.index, response.Type, expected, message,
+		)
+	}
+
+	p.index++
+	return response, nil
+}
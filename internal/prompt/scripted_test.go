@@ -0,0 +1,102 @@
+package prompt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScriptedPrompter(t *testing.T) {
+	prompter := NewScriptedPrompter([]Response{
+		SelectResponse("deployment"),
+		SearchResponse("sample-server-1"),
+		MultiSelectResponse("dev", "staging"),
+		ConfirmResponse(true),
+	})
+
+	// Verify that ScriptedPrompter implements PrompterInterface
+	var _ PrompterInterface = prompter
+
+	selected, err := prompter.Select("App?", nil, "")
+	if err != nil || selected != "deployment" {
+		t.Errorf("Expected select 'deployment', got %q (err: %v)", selected, err)
+	}
+
+	searched, err := prompter.Search("Name?", nil, "")
+	if err != nil || searched != "sample-server-1" {
+		t.Errorf("Expected search 'sample-server-1', got %q (err: %v)", searched, err)
+	}
+
+	multi, err := prompter.MultiSelect("Env?", nil, nil)
+	if err != nil || len(multi) != 2 || multi[1] != "staging" {
+		t.Errorf("Expected multi-select [dev staging], got %v (err: %v)", multi, err)
+	}
+
+	confirmed, err := prompter.Confirm("Proceed?", false)
+	if err != nil || !confirmed {
+		t.Errorf("Expected confirm true, got %v (err: %v)", confirmed, err)
+	}
+
+	if prompter.Remaining() != 0 {
+		t.Errorf("Expected script to be consumed, %d responses remaining", prompter.Remaining())
+	}
+}
+
+func TestScriptedPrompterExhausted(t *testing.T) {
+	prompter := NewScriptedPrompter(nil)
+
+	_, err := prompter.Select("App?", []string{"deployment"}, "")
+	if err == nil {
+		t.Fatal("Expected error when script is exhausted")
+	}
+
+	if !strings.Contains(err.Error(), "script exhausted") || !strings.Contains(err.Error(), "App?") {
+		t.Errorf("Expected exhausted error naming the prompt, got: %v", err)
+	}
+}
+
+func TestScriptedPrompterTypeMismatch(t *testing.T) {
+	prompter := NewScriptedPrompter([]Response{ConfirmResponse(true)})
+
+	_, err := prompter.MultiSelect("Env?", []string{"dev"}, nil)
+	if err == nil {
+		t.Fatal("Expected error on response type mismatch")
+	}
+
+	if !strings.Contains(err.Error(), "confirm") || !strings.Contains(err.Error(), "multiselect") {
+		t.Errorf("Expected mismatch error naming both types, got: %v", err)
+	}
+
+	// A mismatched response is not consumed
+	if prompter.Remaining() != 1 {
+		t.Errorf("Expected 1 remaining response, got %d", prompter.Remaining())
+	}
+}
+
+func TestScriptedPrompterInput(t *testing.T) {
+	prompter := NewScriptedPrompter([]Response{InputResponse("3"), InputResponse("x")})
+	digitsOnly := func(value string) error {
+		if strings.Trim(value, "0123456789") != "" {
+			return errors.New("not a number")
+		}
+		return nil
+	}
+
+	value, err := prompter.Input("Replicas?", digitsOnly)
+	if err != nil || value != "3" {
+		t.Errorf("Expected input '3', got %q (err: %v)", value, err)
+	}
+
+	if _, err := prompter.Input("Replicas?", digitsOnly); err == nil {
+		t.Error("Expected error for scripted input rejected by the validator")
+	}
+}
+
+func TestScriptedPrompterPassword(t *testing.T) {
+	prompter := NewScriptedPrompter([]Response{PasswordResponse("s3cr3t")})
+
+	value, err := prompter.Password("Token?")
+	if err != nil || value != "s3cr3t" {
+		t.Errorf("Expected password 's3cr3t', got %q (err: %v)", value, err)
+	}
+}
@@ -0,0 +1,78 @@
+package template
+
+import (
+	"fmt"
+	"testing"
+)
+
+// benchmarkDirectoryTemplate returns a directory template of several files using
+// conditions, functions and answers, like a typical service template.
+func benchmarkDirectoryTemplate() *Template {
+	files := make(map[string]*FileTemplate)
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("resource-%d.yaml", i)
+		files[name] = &FileTemplate{
+			Filename: fmt.Sprintf("{{.Questions.appName}}-%d.yaml", i),
+			Enabled:  `{{ne .Questions.env "none"}}`,
+			Content: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{.Questions.appName | quote}}
+  checksum: {{sha256sum .Questions.appName}}
+  namespace: {{.Questions.env}}
+  labels:
+    app: {{.Questions.appName}}
+    cluster: {{.Questions.cluster}}
+data:
+{{- range $i, $port := .Questions.ports }}
+  port-{{$i}}: "{{$port}}"
+{{- end }}
+{{- if eq .Questions.env "production" }}
+  replicas: "3"
+{{- else }}
+  replicas: "1"
+{{- end }}`,
+		}
+	}
+
+	return &Template{
+		Type:     TypeDirectory,
+		BasePath: "{{.Questions.env}}/{{.Questions.cluster}}/{{.Questions.appName}}",
+		Files:    files,
+	}
+}
+
+// benchmarkData returns the answers rendered by benchmarkDirectoryTemplate.
+func benchmarkData() *Data {
+	return &Data{Questions: map[string]interface{}{
+		"appName": "Checkout",
+		"env":     "production",
+		"cluster": "prod-cluster-1",
+		"ports":   []string{"80", "443", "8080", "9090"},
+	}}
+}
+
+func TestBenchmarkDirectoryTemplateRenders(t *testing.T) {
+	result, err := benchmarkDirectoryTemplate().Render(benchmarkData())
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+	if len(result.Files) != 10 {
+		t.Fatalf("Expected 10 files, got %d", len(result.Files))
+	}
+	if file := result.Files[0]; file.Path != "production/prod-cluster-1/Checkout" || file.Filename != "Checkout-0.yaml" {
+		t.Errorf("Unexpected output %s/%s", file.Path, file.Filename)
+	}
+}
+
+func BenchmarkRenderDirectory(b *testing.B) {
+	tmpl := benchmarkDirectoryTemplate()
+	data := benchmarkData()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tmpl.Render(data); err != nil {
+			b.Fatalf("Failed to render template: %v", err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// actionPattern matches a template action and captures its body.
+var actionPattern = regexp.MustCompile(`\{\{-?(.*?)-?\}\}`)
+
+// answerActionPatterns match actions that output a question answer as-is.
+var answerActionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*\.Questions\.([A-Za-z_][A-Za-z0-9_]*)\s*$`),
+	regexp.MustCompile(`^\s*index\s+\.Questions\s+"([^"]+)"\s*$`),
+}
+
+// MatchOutputPath recovers the answers that rendered the output path template pattern,
+// such as a file template path or a directory template base_path, into path. Actions
+// that output an answer as-is, like {{.Questions.env}}, capture it from the matching
+// part of path; other actions match anything. Only the last segments of path are
+// matched, so it can include the directories the output was generated into.
+func MatchOutputPath(pattern, path string) (map[string]string, error) {
+	var expr strings.Builder
+	var keys []string
+	literals := actionPattern.Split(pattern, -1)
+	actions := actionPattern.FindAllStringSubmatch(pattern, -1)
+
+	for i, literal := range literals {
+		expr.WriteString(regexp.QuoteMeta(literal))
+		if i >= len(actions) {
+			break
+		}
+
+		key := ""
+		for _, answerAction := range answerActionPatterns {
+			if match := answerAction.FindStringSubmatch(actions[i][1]); match != nil {
+				key = match[1]
+				break
+			}
+		}
+		if key == "" {
+			expr.WriteString(`[^/]*`)
+			continue
+		}
+		keys = append(keys, key)
+		expr.WriteString(`([^/]+)`)
+	}
+
+	matcher, err := regexp.Compile("^" + expr.String() + "$")
+	if err != nil {
+		return nil, fmt.Errorf("failed to match path template %q: %w", pattern, err)
+	}
+
+	// Match as many trailing segments of path as the pattern has
+	segments := strings.Count(strings.Join(literals, ""), "/") + 1
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	if len(parts) < segments {
+		return nil, fmt.Errorf("path %s has fewer segments than %s", path, pattern)
+	}
+	match := matcher.FindStringSubmatch(strings.Join(parts[len(parts)-segments:], "/"))
+	if match == nil {
+		return nil, fmt.Errorf("path %s does not match %s", path, pattern)
+	}
+
+	answers := make(map[string]string, len(keys))
+	for i, key := range keys {
+		value := match[i+1]
+		if previous, exists := answers[key]; exists && previous != value {
+			return nil, fmt.Errorf("path %s has different values for %s: %s and %s", path, key, previous, value)
+		}
+		answers[key] = value
+	}
+	return answers, nil
+}
@@ -0,0 +1,94 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrTemplateExists is returned by Scaffold when the template files already exist.
+var ErrTemplateExists = errors.New("template already exists")
+
+// Scaffold creates a new template named name in the .yg/_templates directory of root
+// and returns its path relative to that directory. The output path and body reference
+// the given question keys as placeholders. Existing files are only overwritten with force.
+func Scaffold(root, name string, templateType Type, questions []string, force bool) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+
+	templatesDir := filepath.Join(root, ".yg", "_templates")
+	files := make(map[string]string)
+	var templatePath string
+
+	filename := name + ".yaml"
+	switch templateType {
+	case TypeFile:
+		templatePath = filename
+		files[filename] = fmt.Sprintf("path: %s\nfilename: %s\n---\n%s", scaffoldPath(questions), filename, scaffoldBody(questions))
+	case TypeDirectory:
+		templatePath = name
+		files[filepath.Join(name, ".template-config.yaml")] = fmt.Sprintf(
+			"output:\n  base_path: %q\n\nfiles:\n  %s:\n    filename: %q\n", scaffoldPath(questions), filename, filename)
+		files[filepath.Join(name, filename)] = scaffoldBody(questions)
+	default:
+		return "", fmt.Errorf("invalid template type %q (expected %s or %s)", templateType, TypeFile, TypeDirectory)
+	}
+
+	if !force {
+		if _, err := os.Stat(filepath.Join(templatesDir, templatePath)); err == nil {
+			return "", fmt.Errorf("%w: %s (use --force to overwrite it)", ErrTemplateExists, filepath.Join(templatesDir, templatePath))
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to check template %s: %w", templatePath, err)
+		}
+	}
+
+	for file, content := range files {
+		fullPath := filepath.Join(templatesDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return "", fmt.Errorf("failed to create directory %s: %w", filepath.Dir(fullPath), err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			return "", fmt.Errorf("failed to write template file %s: %w", fullPath, err)
+		}
+	}
+
+	return templatePath, nil
+}
+
+// scaffoldPath returns an output path template built from the question keys.
+func scaffoldPath(questions []string) string {
+	if len(questions) == 0 {
+		return "."
+	}
+	parts := make([]string, len(questions))
+	for i, key := range questions {
+		parts[i] = fmt.Sprintf("{{%s}}", questionRef(key))
+	}
+	return strings.Join(parts, "/")
+}
+
+// scaffoldBody returns a template body listing the answer to each question.
+func scaffoldBody(questions []string) string {
+	var b strings.Builder
+	b.WriteString("# Replace with the content to generate\n")
+	for _, key := range questions {
+		fmt.Fprintf(&b, "%s: {{%s}}\n", key, questionRef(key))
+	}
+	return b.String()
+}
+
+// identifierPattern matches question keys usable as template fields.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// questionRef returns the template expression referring to the answer to key.
+func questionRef(key string) string {
+	if identifierPattern.MatchString(key) {
+		return ".Questions." + key
+	}
+	return fmt.Sprintf("index .Questions %q", key)
+}
@@ -0,0 +1,102 @@
+package template
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScaffoldLoadsAndRenders(t *testing.T) {
+	testCases := []struct {
+		templateType Type
+		path         string
+	}{
+		{templateType: TypeFile, path: "web.yaml"},
+		{templateType: TypeDirectory, path: "web"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.templateType), func(t *testing.T) {
+			root := t.TempDir()
+			configContent := "templates:\n  web:\n    type: " + string(tc.templateType) + "\n    path: " + tc.path + "\n"
+			if err := os.MkdirAll(filepath.Join(root, ".yg"), 0o755); err != nil {
+				t.Fatalf("Failed to create config directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(root, ".yg", "config.yaml"), []byte(configContent), 0o600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			path, err := Scaffold(root, "web", tc.templateType, []string{"env", "app-name"}, false)
+			if err != nil {
+				t.Fatalf("Failed to scaffold template: %v", err)
+			}
+			if path != tc.path {
+				t.Errorf("Expected template path %s, got %s", tc.path, path)
+			}
+
+			tmpl, err := LoadTemplateFrom(root, "web")
+			if err != nil {
+				t.Fatalf("Failed to load scaffolded template: %v", err)
+			}
+			if tmpl.Type != tc.templateType {
+				t.Errorf("Expected %s template, got %s", tc.templateType, tmpl.Type)
+			}
+
+			result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"env": "dev", "app-name": "api"}})
+			if err != nil {
+				t.Fatalf("Failed to render scaffolded template: %v", err)
+			}
+			if len(result.Files) != 1 {
+				t.Fatalf("Expected 1 file, got %d", len(result.Files))
+			}
+
+			file := result.Files[0]
+			if file.Path != "dev/api" || file.Filename != "web.yaml" {
+				t.Errorf("Expected dev/api/web.yaml, got %s/%s", file.Path, file.Filename)
+			}
+			expected := "# Replace with the content to generate\nenv: dev\napp-name: api"
+			if file.Content != expected && file.Content != expected+"\n" {
+				t.Errorf("Expected placeholders rendered with the answers, got:\n%s", file.Content)
+			}
+		})
+	}
+}
+
+func TestScaffoldRefusesToOverwrite(t *testing.T) {
+	root := t.TempDir()
+
+	if _, err := Scaffold(root, "web", TypeFile, []string{"env"}, false); err != nil {
+		t.Fatalf("Failed to scaffold template: %v", err)
+	}
+
+	templatePath := filepath.Join(root, ".yg", "_templates", "web.yaml")
+	if err := os.WriteFile(templatePath, []byte("path: .\nfilename: web.yaml\n---\nedited"), 0o600); err != nil {
+		t.Fatalf("Failed to edit template: %v", err)
+	}
+
+	if _, err := Scaffold(root, "web", TypeFile, []string{"env"}, false); !errors.Is(err, ErrTemplateExists) {
+		t.Errorf("Expected ErrTemplateExists, got %v", err)
+	}
+	if content, _ := os.ReadFile(templatePath); string(content) != "path: .\nfilename: web.yaml\n---\nedited" {
+		t.Errorf("Expected the edited template to be kept, got:\n%s", content)
+	}
+
+	if _, err := Scaffold(root, "web", TypeFile, []string{"env"}, true); err != nil {
+		t.Fatalf("Failed to overwrite template with force: %v", err)
+	}
+	if content, _ := os.ReadFile(templatePath); string(content) == "path: .\nfilename: web.yaml\n---\nedited" {
+		t.Error("Expected force to overwrite the template")
+	}
+}
+
+func TestScaffoldInvalid(t *testing.T) {
+	root := t.TempDir()
+
+	if _, err := Scaffold(root, "../web", TypeFile, nil, false); err == nil {
+		t.Error("Expected error for a name with a path separator")
+	}
+	if _, err := Scaffold(root, "web", Type("inline"), nil, false); err == nil {
+		t.Error("Expected error for an unknown template type")
+	}
+}
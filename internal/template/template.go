@@ -2,9 +2,17 @@
 package template
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -25,10 +33,15 @@ type Template struct {
 	Path     string // For file: template file path, For directory: base path template
 	Filename string // For file: filename template
 	Content  string // For file: content template
+	Source   string // For file: template file name, used in error messages
+	// TrimBlocks removes the lines of content that hold only control-flow actions, such
+	// as {{ if }} and {{ end }}, instead of leaving them as empty lines.
+	TrimBlocks bool
 
 	// For directory templates
 	Files    map[string]*FileTemplate // filename -> FileTemplate
 	BasePath string                   // base path template for all files
+	Formats  []string                 // output formats for each file (e.g. yaml, json), rendered as templates
 }
 
 // FileTemplate represents a single file within a directory template.
@@ -46,7 +59,8 @@ type DirectoryTemplateConfig struct {
 
 // OutputConfig represents output configuration for directory templates.
 type OutputConfig struct {
-	BasePath string `yaml:"base_path"`
+	BasePath string   `yaml:"base_path"`
+	Formats  []string `yaml:"formats,omitempty"`
 }
 
 // FileTemplateConfig represents configuration for individual files.
@@ -58,36 +72,112 @@ type FileTemplateConfig struct {
 // Data holds the data for template rendering.
 type Data struct {
 	Questions map[string]interface{}
+	// Siblings lists the other filenames generated by the same directory template render.
+	Siblings []string
+	// Data holds the content of the configured data files, keyed by name.
+	Data map[string]interface{}
+	// Values holds the same content as Data, for templates written against Helm-style .Values.
+	Values map[string]interface{}
+	// Env holds the environment variables of the yg process.
+	Env map[string]string
+	// Meta describes the generation the template is rendered for.
+	Meta Meta
 }
 
-// LoadTemplate loads either a single file or directory template.
+// TimestampFormat is the layout of Meta.Timestamp. It has no separators that are
+// invalid in file names, so the timestamp can be used in output paths.
+const TimestampFormat = "20060102-150405"
+
+// Meta describes a generation for template rendering.
+type Meta struct {
+	// Template is the name of the template being rendered.
+	Template string
+	// Timestamp is the UTC start time of the generation, formatted with TimestampFormat.
+	Timestamp string
+}
+
+// LoadTemplate loads either a single file or directory template from the .yg
+// directory of the working directory.
 func LoadTemplate(templateType string) (*Template, error) {
+	return LoadTemplateFrom("", templateType)
+}
+
+// LoadTemplateFrom loads a template like LoadTemplate from the .yg directory in root.
+func LoadTemplateFrom(root, templateType string) (*Template, error) {
+	return LoadTemplateVariantFrom(root, templateType, "")
+}
+
+// LoadTemplateVariantFrom loads a template like LoadTemplateFrom, preferring the variant
+// of its file or directory named after variant when it exists, such as deployment.prod.yaml
+// for deployment.yaml. An empty variant loads the template itself. The templates
+// section is read from .yg/config.yaml; use LoadConfiguredTemplate for a config that
+// was already loaded from another file.
+func LoadTemplateVariantFrom(root, templateType, variant string) (*Template, error) {
 	// First, check template type from config
-	config, err := loadTemplateConfig()
+	config, err := loadTemplateConfig(root)
 	if err != nil {
 		// Fall back to single file loading if config doesn't exist
-		return loadFileTemplate(templateType)
+		return loadFileTemplate(root, templateType, variant)
 	}
+	return LoadConfiguredTemplate(root, config.Templates, templateType, variant)
+}
 
-	templateConfig, exists := config.Templates[templateType]
+// LoadConfiguredTemplate loads a template from the .yg directory in root like
+// LoadTemplateVariantFrom, looking it up in the given templates config section.
+// Templates without an entry are loaded as single file templates.
+func LoadConfiguredTemplate(root string, templates map[string]ConfigEntry, templateType, variant string) (*Template, error) {
+	templateConfig, exists := templates[templateType]
 	if !exists {
 		// Fallback: traditional single file loading
-		return loadFileTemplate(templateType)
+		return loadFileTemplate(root, templateType, variant)
+	}
+
+	// Inline templates are built directly from config
+	if templateConfig.Content != "" {
+		return &Template{
+			Type:     TypeFile,
+			Path:     templateConfig.Path,
+			Filename: templateConfig.Filename,
+			Content:  templateConfig.Content,
+		}, nil
 	}
 
 	switch templateConfig.Type {
 	case "file":
-		return loadFileTemplate(templateConfig.Path)
+		tmpl, err := loadFileTemplate(root, templateConfig.Path, variant)
+		if err != nil {
+			return nil, err
+		}
+		// Config overrides let projects reuse a template body with their own layout
+		if templateConfig.OutputPath != "" {
+			tmpl.Path = templateConfig.OutputPath
+		}
+		if templateConfig.Filename != "" {
+			tmpl.Filename = templateConfig.Filename
+		}
+		return tmpl, nil
 	case "directory":
-		return loadDirectoryTemplate(templateConfig.Path)
+		return loadDirectoryTemplate(root, templateConfig.Path, variant)
 	default:
 		return nil, fmt.Errorf("unsupported template type: %s", templateConfig.Type)
 	}
 }
 
+// LoadFileTemplateFrom loads the single file template named templateType from the .yg
+// directory in root, without looking up the templates section of the config.
+func LoadFileTemplateFrom(root, templateType string) (*Template, error) {
+	return loadFileTemplate(root, templateType, "")
+}
+
+// LoadFileTemplateVariantFrom loads a single file template like LoadFileTemplateFrom,
+// preferring its variant like LoadTemplateVariantFrom.
+func LoadFileTemplateVariantFrom(root, templateType, variant string) (*Template, error) {
+	return loadFileTemplate(root, templateType, variant)
+}
+
 // loadTemplateConfig loads the template configuration from config file.
-func loadTemplateConfig() (*ConfigFile, error) {
-	configPath := filepath.Join(".yg", "config.yaml")
+func loadTemplateConfig(root string) (*ConfigFile, error) {
+	configPath := filepath.Join(root, ".yg", "config.yaml")
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -109,25 +199,46 @@ type ConfigFile struct {
 
 // ConfigEntry represents template configuration entry.
 type ConfigEntry struct {
-	Type string `yaml:"type"` // "file" or "directory"
-	Path string `yaml:"path"` // path to template file or directory
+	Type       string `yaml:"type"`                  // "file" or "directory"
+	Path       string `yaml:"path"`                  // path to template file or directory, or output path for inline content
+	OutputPath string `yaml:"output_path,omitempty"` // output path template overriding the file template metadata
+	Filename   string `yaml:"filename,omitempty"`    // output filename template for inline content or file templates
+	Content    string `yaml:"content,omitempty"`     // inline template content
 }
 
-// loadFileTemplate loads a single file template.
-func loadFileTemplate(templatePath string) (*Template, error) {
+// loadFileTemplate loads a single file template, or its variant when one exists.
+func loadFileTemplate(root, templatePath, variant string) (*Template, error) {
 	// If templatePath doesn't have an extension, add .yaml for backward compatibility
 	if !strings.Contains(templatePath, ".") {
 		templatePath = templatePath + ".yaml"
 	}
 
-	fullPath := filepath.Join(".yg", "_templates", templatePath)
+	templateDir := filepath.Join(root, ".yg", "_templates")
+	templatePath = variantFile(templateDir, templatePath, variant)
+
+	return parseFileTemplate(filepath.Join(templateDir, templatePath), templatePath)
+}
+
+// LoadFileTemplateAt loads the single file template at path, which does not need to be
+// in a .yg directory.
+func LoadFileTemplateAt(path string) (*Template, error) {
+	return parseFileTemplate(path, path)
+}
 
+// parseFileTemplate reads the single file template at fullPath. Render errors name source.
+func parseFileTemplate(fullPath, source string) (*Template, error) {
 	data, err := os.ReadFile(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read template file %s: %w", fullPath, err)
 	}
 
-	content := string(data)
+	content := normalizeLineEndings(data)
+
+	// Front matter, as used by static site generators, opens the metadata with a ---
+	// line too. Otherwise the leading one is the usual separator.
+	if rest, found := frontMatter(content); found {
+		content = rest
+	}
 
 	// Split the content into metadata and template content
 	parts := strings.SplitN(content, "---", 2)
@@ -142,6 +253,7 @@ func loadFileTemplate(templatePath string) (*Template, error) {
 	tmpl := &Template{
 		Type:    TypeFile,
 		Content: templateContent,
+		Source:  source,
 	}
 
 	// Extract path and filename from metadata
@@ -158,9 +270,56 @@ func loadFileTemplate(templatePath string) (*Template, error) {
 	return tmpl, nil
 }
 
-// loadDirectoryTemplate loads a directory template.
-func loadDirectoryTemplate(dirName string) (*Template, error) {
-	templateDir := filepath.Join(".yg", "_templates", dirName)
+// frontMatter returns content after its leading --- line when that line opens front
+// matter: a block of top-level "key: value" lines, with a path or filename key, closed
+// by another --- line. The block is checked line by line rather than parsed as YAML,
+// since values usually hold template actions that are not valid YAML. A multi-document
+// body without metadata is not front matter.
+func frontMatter(content string) (string, bool) {
+	rest, found := strings.CutPrefix(content, "---\n")
+	if !found {
+		return content, false
+	}
+	lines := strings.Split(rest, "\n")
+	end := slices.Index(lines, "---")
+	if end < 0 {
+		return content, false
+	}
+
+	hasMetadata := false
+	for _, line := range lines[:end] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, _, isPair := strings.Cut(line, ":")
+		if !isPair || key == "" || strings.ContainsAny(key, " \t") {
+			return content, false
+		}
+		hasMetadata = hasMetadata || key == "path" || key == "filename"
+	}
+	if !hasMetadata {
+		return content, false
+	}
+	return rest, true
+}
+
+// normalizeLineEndings converts CRLF line endings, as in templates authored on Windows,
+// to LF so that metadata is parsed cleanly and generated files are consistent.
+func normalizeLineEndings(data []byte) string {
+	return strings.ReplaceAll(string(data), "\r\n", "\n")
+}
+
+// loadDirectoryTemplate loads a directory template, or its variant directory, such as
+// microservice.prod for microservice, when one exists. Within the directory, the variant
+// of each file, such as deployment.prod.yaml for deployment.yaml, is preferred when it
+// exists.
+func loadDirectoryTemplate(root, dirName, variant string) (*Template, error) {
+	templateDir := filepath.Join(root, ".yg", "_templates", dirName)
+	if variant != "" {
+		if info, err := os.Stat(templateDir + "." + variant); err == nil && info.IsDir() {
+			templateDir += "." + variant
+		}
+	}
 
 	// Load .template-config.yaml
 	configPath := filepath.Join(templateDir, ".template-config.yaml")
@@ -177,7 +336,7 @@ func loadDirectoryTemplate(dirName string) (*Template, error) {
 	// Load template files in directory
 	files := make(map[string]*FileTemplate)
 	for filename, fileConfig := range config.Files {
-		contentPath := filepath.Join(templateDir, filename)
+		contentPath := filepath.Join(templateDir, variantFile(templateDir, filename, variant))
 		content, err := os.ReadFile(contentPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read template file %s: %w", filename, err)
@@ -185,7 +344,7 @@ func loadDirectoryTemplate(dirName string) (*Template, error) {
 
 		files[filename] = &FileTemplate{
 			Filename: fileConfig.Filename,
-			Content:  string(content),
+			Content:  normalizeLineEndings(content),
 			Enabled:  fileConfig.Enabled,
 		}
 	}
@@ -194,9 +353,24 @@ func loadDirectoryTemplate(dirName string) (*Template, error) {
 		Type:     TypeDirectory,
 		BasePath: config.Output.BasePath,
 		Files:    files,
+		Formats:  config.Output.Formats,
 	}, nil
 }
 
+// variantFile returns the name of the variant of the file in dir, such as
+// deployment.prod.yaml for deployment.yaml, when it exists, and filename otherwise.
+func variantFile(dir, filename, variant string) string {
+	if variant == "" {
+		return filename
+	}
+	extension := filepath.Ext(filename)
+	variantName := strings.TrimSuffix(filename, extension) + "." + variant + extension
+	if _, err := os.Stat(filepath.Join(dir, variantName)); err == nil {
+		return variantName
+	}
+	return filename
+}
+
 // RenderResult holds the result of template rendering.
 type RenderResult struct {
 	Files []RenderedFile
@@ -209,7 +383,35 @@ type RenderedFile struct {
 	Content  string
 }
 
-// Render renders the template and returns all generated files.
+// Phases of rendering reported by RenderError.
+const (
+	RenderPhasePath     = "path"
+	RenderPhaseFilename = "filename"
+	RenderPhaseContent  = "content"
+	RenderPhaseEnabled  = "enabled"
+	RenderPhaseFormat   = "format"
+)
+
+// RenderError reports the template file and the part of it that failed to render.
+type RenderError struct {
+	File  string // template file name, empty for the base path of a directory template or inline content
+	Phase string // RenderPhasePath, RenderPhaseFilename, RenderPhaseContent, RenderPhaseEnabled or RenderPhaseFormat
+	Err   error
+}
+
+func (e *RenderError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("failed to render %s: %v", e.Phase, e.Err)
+	}
+	return fmt.Sprintf("failed to render %s for %s: %v", e.Phase, e.File, e.Err)
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// Render renders the template and returns all generated files. Rendering failures
+// are returned as a *RenderError.
 func (t *Template) Render(data *Data) (*RenderResult, error) {
 	switch t.Type {
 	case TypeFile:
@@ -221,50 +423,59 @@ func (t *Template) Render(data *Data) (*RenderResult, error) {
 	}
 }
 
-// renderSingleFile renders a single file template (backward compatibility).
-func (t *Template) renderSingleFile(data *Data) (*RenderResult, error) {
-	funcMap := template.FuncMap{
-		"questions": func() map[string]interface{} {
-			return data.Questions
-		},
+// FileCount returns the number of files the template produces for data without
+// rendering their content. Only the enabled conditions of directory templates are evaluated.
+func (t *Template) FileCount(data *Data) (int, error) {
+	switch t.Type {
+	case TypeFile:
+		return 1, nil
+	case TypeDirectory:
+		count := 0
+		for originalName, fileTemplate := range t.Files {
+			if fileTemplate.Enabled != "" {
+				enabled, err := fileEnabled(fileTemplate.Enabled, data)
+				if err != nil {
+					return 0, &RenderError{File: originalName, Phase: RenderPhaseEnabled, Err: err}
+				}
+				if !enabled {
+					continue
+				}
+			}
+			count++
+		}
+		if len(t.Formats) > 0 {
+			count *= len(t.Formats)
+		}
+		return count, nil
+	default:
+		return 0, fmt.Errorf("unsupported template type: %s", t.Type)
 	}
+}
 
+// renderSingleFile renders a single file template (backward compatibility).
+func (t *Template) renderSingleFile(data *Data) (*RenderResult, error) {
 	// Render path
-	pathTmpl, err := template.New("path").Funcs(funcMap).Parse(t.Path)
+	renderedPath, err := renderTemplate("path", t.Path, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse path template: %w", err)
+		return nil, &RenderError{File: t.Source, Phase: RenderPhasePath, Err: err}
 	}
 
-	var pathBuf strings.Builder
-	if err := pathTmpl.Execute(&pathBuf, data); err != nil {
-		return nil, fmt.Errorf("failed to render path: %w", err)
-	}
-	renderedPath := pathBuf.String()
-
 	// Render filename
-	filenameTmpl, err := template.New("filename").Funcs(funcMap).Parse(t.Filename)
+	renderedFilename, err := renderTemplate("filename", t.Filename, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse filename template: %w", err)
+		return nil, &RenderError{File: t.Source, Phase: RenderPhaseFilename, Err: err}
 	}
 
-	var filenameBuf strings.Builder
-	if err := filenameTmpl.Execute(&filenameBuf, data); err != nil {
-		return nil, fmt.Errorf("failed to render filename: %w", err)
+	// Render content, named after the template file so errors point to it
+	contentName := "content"
+	if t.Source != "" {
+		contentName = t.Source
 	}
-	renderedFilename := filenameBuf.String()
-
-	// Render content
-	contentTmpl, err := template.New("content").Funcs(funcMap).Parse(t.Content)
+	renderedContent, err := renderTemplate(contentName, t.contentSource(t.Content), data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse content template: %w", err)
+		return nil, &RenderError{File: t.Source, Phase: RenderPhaseContent, Err: err}
 	}
 
-	var contentBuf strings.Builder
-	if err := contentTmpl.Execute(&contentBuf, data); err != nil {
-		return nil, fmt.Errorf("failed to render content: %w", err)
-	}
-	renderedContent := contentBuf.String()
-
 	return &RenderResult{
 		Files: []RenderedFile{
 			{
@@ -283,18 +494,37 @@ func (t *Template) renderDirectory(data *Data) (*RenderResult, error) {
 	// Render base path
 	basePath, err := renderTemplate("base_path", t.BasePath, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to render base path: %w", err)
+		return nil, &RenderError{Phase: RenderPhasePath, Err: err}
 	}
 
-	// Render each file
-	for originalName, fileTemplate := range t.Files {
+	formats, err := t.renderFormats(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the enabled files and their filenames first so each file can see its siblings
+	type plannedFile struct {
+		originalName string
+		filename     string
+		outputs      []string
+	}
+	originalNames := make([]string, 0, len(t.Files))
+	for originalName := range t.Files {
+		originalNames = append(originalNames, originalName)
+	}
+	sort.Strings(originalNames)
+
+	var planned []plannedFile
+	for _, originalName := range originalNames {
+		fileTemplate := t.Files[originalName]
+
 		// Check if enabled
 		if fileTemplate.Enabled != "" {
-			enabled, err := renderTemplate("enabled", fileTemplate.Enabled, data)
+			enabled, err := fileEnabled(fileTemplate.Enabled, data)
 			if err != nil {
-				return nil, fmt.Errorf("failed to render enabled condition for %s: %w", originalName, err)
+				return nil, &RenderError{File: originalName, Phase: RenderPhaseEnabled, Err: err}
 			}
-			if enabled != "true" {
+			if !enabled {
 				continue // Skip
 			}
 		}
@@ -302,42 +532,314 @@ func (t *Template) renderDirectory(data *Data) (*RenderResult, error) {
 		// Render filename
 		filename, err := renderTemplate("filename", fileTemplate.Filename, data)
 		if err != nil {
-			return nil, fmt.Errorf("failed to render filename for %s: %w", originalName, err)
+			return nil, &RenderError{File: originalName, Phase: RenderPhaseFilename, Err: err}
+		}
+
+		outputs := []string{filename}
+		if len(formats) > 0 {
+			outputs = outputs[:0]
+			for _, format := range formats {
+				output := withFormatExtension(filename, format)
+				if slices.Contains(outputs, output) {
+					return nil, fmt.Errorf("formats %v write %s more than once: use a .yaml filename for %s to get one file per format",
+						formats, output, originalName)
+				}
+				outputs = append(outputs, output)
+			}
+		}
+		planned = append(planned, plannedFile{originalName: originalName, filename: filename, outputs: outputs})
+	}
+
+	// Render each file
+	for i, file := range planned {
+		siblings := []string{}
+		for j, other := range planned {
+			if j != i {
+				siblings = append(siblings, other.outputs...)
+			}
 		}
+		sort.Strings(siblings)
+		fileData := *data
+		fileData.Siblings = siblings
 
-		// Render content
-		content, err := renderTemplate("content", fileTemplate.Content, data)
+		// Render content, named after the template file so errors point to it
+		content, err := renderTemplate(file.originalName, t.contentSource(t.Files[file.originalName].Content), &fileData)
 		if err != nil {
-			return nil, fmt.Errorf("failed to render content for %s: %w", originalName, err)
+			return nil, &RenderError{File: file.originalName, Phase: RenderPhaseContent, Err: err}
 		}
 
-		result.Files = append(result.Files, RenderedFile{
-			Path:     basePath,
-			Filename: filename,
-			Content:  content,
-		})
+		if len(formats) == 0 {
+			result.Files = append(result.Files, RenderedFile{
+				Path:     basePath,
+				Filename: file.filename,
+				Content:  content,
+			})
+			continue
+		}
+
+		// Emit the file once per configured format
+		for k, format := range formats {
+			converted, err := convertFormat(content, format)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s to %s: %w", file.originalName, format, err)
+			}
+
+			result.Files = append(result.Files, RenderedFile{
+				Path:     basePath,
+				Filename: file.outputs[k],
+				Content:  converted,
+			})
+		}
 	}
 
+	// Order the files by output location so that output and reports are stable
+	sort.SliceStable(result.Files, func(i, j int) bool {
+		a, b := result.Files[i], result.Files[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Filename < b.Filename
+	})
+
 	return result, nil
 }
 
+// renderFormats renders each output format, such as
+// `{{ if eq .Questions.target "api" }}json{{ else }}yaml{{ end }}`, with the answers.
+func (t *Template) renderFormats(data *Data) ([]string, error) {
+	formats := make([]string, 0, len(t.Formats))
+	for _, format := range t.Formats {
+		rendered, err := renderTemplate("format", format, data)
+		if err != nil {
+			return nil, &RenderError{Phase: RenderPhaseFormat, Err: err}
+		}
+		rendered = strings.TrimSpace(rendered)
+		if rendered == "" {
+			return nil, &RenderError{Phase: RenderPhaseFormat, Err: fmt.Errorf("format %q rendered empty", format)}
+		}
+		formats = append(formats, rendered)
+	}
+	return formats, nil
+}
+
+// Output formats supported by directory templates.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// convertFormat converts rendered YAML content into the given output format.
+func convertFormat(content, format string) (string, error) {
+	switch format {
+	case FormatYAML:
+		return content, nil
+	case FormatJSON:
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(content), &value); err != nil {
+			return "", fmt.Errorf("failed to parse rendered YAML: %w", err)
+		}
+
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// withFormatExtension replaces a YAML or JSON extension of filename with the one for
+// format, or adds it when filename has none. Other extensions are explicit and kept.
+func withFormatExtension(filename, format string) string {
+	ext := filepath.Ext(filename)
+	switch ext {
+	case ".yaml", ".yml":
+		if format == FormatYAML {
+			return filename
+		}
+	case "", ".json":
+	default:
+		return filename
+	}
+	return strings.TrimSuffix(filename, ext) + "." + format
+}
+
 // renderTemplate renders a template string with the given data.
 func renderTemplate(name, templateStr string, data *Data) (string, error) {
-	funcMap := template.FuncMap{
-		"questions": func() map[string]interface{} {
-			return data.Questions
-		},
-	}
+	funcMap := newFuncMap(data)
 
 	tmpl, err := template.New(name).Funcs(funcMap).Parse(templateStr)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return "", fmt.Errorf("failed to parse template: %w", withSourceContext(name, templateStr, err))
 	}
 
 	var buf strings.Builder
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+		return "", fmt.Errorf("failed to execute template: %w", withSourceContext(name, templateStr, err))
 	}
 
 	return buf.String(), nil
 }
+
+// withSourceContext appends the template source line that err points to, with a
+// caret under the column when known. Errors without a position are returned as is.
+func withSourceContext(name, templateStr string, err error) error {
+	pattern := regexp.MustCompile(`template: ` + regexp.QuoteMeta(name) + `:(\d+)(?::(\d+))?:`)
+	match := pattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+
+	lineNumber, _ := strconv.Atoi(match[1])
+	lines := strings.Split(templateStr, "\n")
+	if lineNumber < 1 || lineNumber > len(lines) {
+		return err
+	}
+
+	gutter := fmt.Sprintf("%5d | ", lineNumber)
+	snippet := gutter + lines[lineNumber-1]
+	if match[2] != "" {
+		column, _ := strconv.Atoi(match[2])
+		snippet += "\n" + strings.Repeat(" ", len(gutter)-2) + "| " + strings.Repeat(" ", column) + "^"
+	}
+
+	return fmt.Errorf("%w\n%s", err, snippet)
+}
+
+// fileEnabled renders the enabled condition of a directory template file and reports
+// whether it renders exactly "true", surrounding whitespace included.
+func fileEnabled(condition string, data *Data) (bool, error) {
+	result, err := renderTemplate("enabled", condition, data)
+	if err != nil {
+		return false, err
+	}
+	return result == "true", nil
+}
+
+// EvaluateCondition renders a condition template and reports whether it evaluates to "true".
+func EvaluateCondition(name, condition string, data *Data) (bool, error) {
+	result, err := renderTemplate(name, condition, data)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(result) == "true", nil
+}
+
+// newFuncMap returns the functions available to templates.
+func newFuncMap(data *Data) template.FuncMap {
+	return template.FuncMap{
+		"questions": func() map[string]interface{} {
+			return data.Questions
+		},
+		// anchor and ref emit YAML anchors (&name) and aliases (*name) so that
+		// templates can share blocks without hand-writing the sigils.
+		"anchor": func(name string) string {
+			return "&" + name
+		},
+		"ref": func(name string) string {
+			return "*" + name
+		},
+		"b64enc": func(value interface{}) string {
+			return base64.StdEncoding.EncodeToString(toBytes(value))
+		},
+		"sha256sum": func(value interface{}) string {
+			sum := sha256.Sum256(toBytes(value))
+			return hex.EncodeToString(sum[:])
+		},
+		// quote, indent, nindent and yamlBlock inject values, including multiline
+		// strings, without breaking the surrounding YAML.
+		"quote": func(value interface{}) string {
+			return strconv.Quote(string(toBytes(value)))
+		},
+		"indent": func(spaces int, value interface{}) string {
+			return indentLines(spaces, string(toBytes(value)))
+		},
+		"nindent": func(spaces int, value interface{}) string {
+			return "\n" + indentLines(spaces, string(toBytes(value)))
+		},
+		"yamlBlock": yamlBlock,
+	}
+}
+
+// indentLines prefixes every non-empty line of text with the given number of spaces.
+func indentLines(spaces int, text string) string {
+	prefix := strings.Repeat(" ", spaces)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// yamlBlock returns value as a YAML literal block scalar whose lines are indented by
+// the given number of spaces, e.g. `key: {{ .Questions.cert | yamlBlock 2 }}`.
+// The chomping indicator preserves trailing newlines exactly. Values starting with
+// whitespace are emitted as double-quoted scalars.
+func yamlBlock(spaces int, value interface{}) string {
+	text := string(toBytes(value))
+	body := strings.TrimRight(text, "\n")
+
+	// A leading space would need an indentation indicator relative to the parent
+	// node, which is not known here, so such values are double-quoted instead
+	if strings.HasPrefix(body, " ") || strings.HasPrefix(body, "\t") {
+		return strconv.Quote(text)
+	}
+
+	header := "|"
+	trailing := len(text) - len(body)
+	switch {
+	case trailing == 0:
+		header += "-" // strip: no final newline
+	case trailing > 1:
+		header += "+" // keep: the trailing blank lines are part of the value
+	}
+
+	// The line break after the last line comes from the template itself
+	return header + "\n" + indentLines(spaces, text[:len(text)-min(trailing, 1)])
+}
+
+// toBytes converts a template value to bytes for encoding and hashing functions.
+func toBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	default:
+		return []byte(fmt.Sprintf("%v", v))
+	}
+}
+
+// questionReferencePatterns match the ways a template can refer to a question answer:
+// .Questions.name, index .Questions "name", (questions).name and index (questions) "name".
+var questionReferencePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\.Questions\.([A-Za-z_][A-Za-z0-9_]*)`),
+	regexp.MustCompile(`\(questions\)\.([A-Za-z_][A-Za-z0-9_]*)`),
+	regexp.MustCompile(`index\s+(?:\.Questions|\(questions\)|questions)\s+"([^"]+)"`),
+}
+
+// QuestionReferences returns the question keys referenced by the given template strings.
+func QuestionReferences(texts ...string) map[string]bool {
+	references := make(map[string]bool)
+	for _, text := range texts {
+		for _, pattern := range questionReferencePatterns {
+			for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+				references[match[1]] = true
+			}
+		}
+	}
+	return references
+}
+
+// QuestionReferences returns the question keys referenced anywhere in the template,
+// including paths, filenames and enabled conditions.
+func (t *Template) QuestionReferences() map[string]bool {
+	texts := []string{t.Path, t.Filename, t.Content, t.BasePath}
+	for _, file := range t.Files {
+		texts = append(texts, file.Filename, file.Content, file.Enabled)
+	}
+	return QuestionReferences(texts...)
+}
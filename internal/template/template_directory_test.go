@@ -1,10 +1,14 @@
 package template
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 // TestLoadTemplateWithConfig tests template loading with config file
@@ -492,3 +496,306 @@ files:
 		}
 	})
 }
+
+// TestDirectoryTemplateWithFormats tests emitting each file once per output format
+func TestDirectoryTemplateWithFormats(t *testing.T) {
+	testDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalDir) }()
+	if err := os.Chdir(testDir); err != nil {
+		t.Fatalf("Failed to change to test directory: %v", err)
+	}
+
+	templateDir := filepath.Join(testDir, ".yg", "_templates", "docs")
+	if err := os.MkdirAll(templateDir, 0o755); err != nil {
+		t.Fatalf("Failed to create template directory: %v", err)
+	}
+
+	dirConfigContent := `output:
+  base_path: "{{.Questions.env}}"
+  formats: [yaml, json]
+files:
+  deployment.yaml:
+    filename: "{{.Questions.appName}}-deployment.yaml"`
+
+	if err := os.WriteFile(filepath.Join(templateDir, ".template-config.yaml"), []byte(dirConfigContent), 0o600); err != nil {
+		t.Fatalf("Failed to write template config: %v", err)
+	}
+
+	deploymentContent := `name: {{.Questions.appName}}
+replicas: 3`
+	if err := os.WriteFile(filepath.Join(templateDir, "deployment.yaml"), []byte(deploymentContent), 0o600); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+
+	tmpl, err := loadDirectoryTemplate("", "docs", "")
+	if err != nil {
+		t.Fatalf("Failed to load directory template: %v", err)
+	}
+
+	result, err := tmpl.Render(&Data{
+		Questions: map[string]interface{}{
+			"appName": "my-app",
+			"env":     "dev",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	if len(result.Files) != 2 {
+		t.Fatalf("Expected 2 files (yaml and json), got %d", len(result.Files))
+	}
+
+	contents := make(map[string]string)
+	for _, file := range result.Files {
+		contents[file.Filename] = file.Content
+	}
+
+	yamlContent, ok := contents["my-app-deployment.yaml"]
+	if !ok {
+		t.Fatalf("Expected my-app-deployment.yaml, got %v", contents)
+	}
+	jsonContent, ok := contents["my-app-deployment.json"]
+	if !ok {
+		t.Fatalf("Expected my-app-deployment.json, got %v", contents)
+	}
+
+	var fromYAML, fromJSON map[string]interface{}
+	if err := yaml.Unmarshal([]byte(yamlContent), &fromYAML); err != nil {
+		t.Fatalf("Failed to parse YAML output: %v", err)
+	}
+	if err := json.Unmarshal([]byte(jsonContent), &fromJSON); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	if fromJSON["name"] != fromYAML["name"] || fromJSON["replicas"] != float64(3) {
+		t.Errorf("Expected equivalent content, got YAML %v and JSON %v", fromYAML, fromJSON)
+	}
+}
+
+// TestDirectoryTemplateUnsupportedFormat tests that unknown formats are rejected
+func TestDirectoryTemplateUnsupportedFormat(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Formats:  []string{"toml"},
+		Files: map[string]*FileTemplate{
+			"config.yaml": {Filename: "config.yaml", Content: "key: value"},
+		},
+	}
+
+	_, err := tmpl.Render(&Data{Questions: map[string]interface{}{}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Errorf("Expected unsupported output format error, got %v", err)
+	}
+}
+
+func TestRenderDirectoryErrorNamesFileAndLine(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Files: map[string]*FileTemplate{
+			"service.yaml": {
+				Filename: "service.yaml",
+				Content:  "kind: Service\nports: {{ index .Questions.ports 5 }}\n",
+			},
+		},
+	}
+
+	_, err := tmpl.Render(&Data{Questions: map[string]interface{}{"ports": []string{"80"}}})
+	if err == nil {
+		t.Fatal("Expected render error for out of range index")
+	}
+
+	message := err.Error()
+	expected := []string{
+		"service.yaml:2:10",
+		"    2 | ports: {{ index .Questions.ports 5 }}",
+		"      |           ^",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(message, fragment) {
+			t.Errorf("Expected error to contain %q, got:\n%s", fragment, message)
+		}
+	}
+}
+
+func TestRenderDirectoryParseErrorNamesFileAndLine(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Files: map[string]*FileTemplate{
+			"config.yaml": {
+				Filename: "config.yaml",
+				Content:  "a: 1\nb: 2\nc: {{ .Questions.c }\n",
+			},
+		},
+	}
+
+	_, err := tmpl.Render(&Data{Questions: map[string]interface{}{}})
+	if err == nil {
+		t.Fatal("Expected render error for template syntax error")
+	}
+
+	message := err.Error()
+	if !strings.Contains(message, "config.yaml:3") || !strings.Contains(message, "    3 | c: {{ .Questions.c }") {
+		t.Errorf("Expected error to name the file and show line 3, got:\n%s", message)
+	}
+}
+
+func TestRenderDirectoryExposesSiblings(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Files: map[string]*FileTemplate{
+			"kustomization.yaml": {
+				Filename: "kustomization.yaml",
+				Content:  "resources:\n{{- range .Siblings }}\n  - {{ . }}\n{{- end }}\n",
+			},
+			"deployment.yaml": {Filename: "{{ .Questions.app }}-deployment.yaml", Content: "kind: Deployment"},
+			"service.yaml":    {Filename: "{{ .Questions.app }}-service.yaml", Content: "kind: Service"},
+			"ingress.yaml":    {Filename: "ingress.yaml", Enabled: "false", Content: "kind: Ingress"},
+		},
+	}
+
+	result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"app": "web"}})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	expected := "resources:\n  - web-deployment.yaml\n  - web-service.yaml\n"
+	for _, file := range result.Files {
+		if file.Filename == "kustomization.yaml" {
+			if file.Content != expected {
+				t.Errorf("Expected content %q, got %q", expected, file.Content)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected kustomization.yaml, got %v", result.Files)
+}
+
+// TestDirectoryTemplateFormatExtensions tests that YAML extensions follow the format and other extensions are kept
+func TestDirectoryTemplateFormatExtensions(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Formats:  []string{FormatJSON},
+		Files: map[string]*FileTemplate{
+			"config.yaml": {Filename: "{{.Questions.appName}}.yaml", Content: "key: value"},
+			"legacy.yml":  {Filename: "legacy.yml", Content: "key: value"},
+			"notes.txt":   {Filename: "notes.txt", Content: "key: value"},
+			"plain":       {Filename: "plain", Content: "key: value"},
+		},
+	}
+
+	result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"appName": "my-app"}})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	var filenames []string
+	for _, file := range result.Files {
+		filenames = append(filenames, file.Filename)
+	}
+	expected := []string{"legacy.json", "my-app.json", "notes.txt", "plain.json"}
+	if strings.Join(filenames, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected filenames %v, got %v", expected, filenames)
+	}
+
+	// An explicit extension cannot tell the formats apart
+	tmpl.Formats = []string{FormatYAML, FormatJSON}
+	_, err = tmpl.Render(&Data{Questions: map[string]interface{}{"appName": "my-app"}})
+	if err == nil || !strings.Contains(err.Error(), "write notes.txt more than once") {
+		t.Errorf("Expected error for colliding filenames, got %v", err)
+	}
+}
+
+func TestRenderDirectorySortsFiles(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Formats:  []string{FormatYAML, FormatJSON},
+		Files: map[string]*FileTemplate{
+			"a.yaml": {Filename: "{{.Questions.name}}.yaml", Content: "key: value"},
+			"b.yaml": {Filename: "config.yaml", Content: "key: value"},
+		},
+	}
+
+	result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"name": "zeta"}})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	var filenames []string
+	for _, file := range result.Files {
+		filenames = append(filenames, file.Filename)
+	}
+	expected := []string{"config.json", "config.yaml", "zeta.json", "zeta.yaml"}
+	if strings.Join(filenames, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected files sorted as %v, got %v", expected, filenames)
+	}
+}
+
+func TestRenderDirectoryEnabledIsExact(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Files: map[string]*FileTemplate{
+			"a.yaml": {Filename: "a.yaml", Content: "a", Enabled: "true"},
+			// Only exactly "true" enables a file, unlike skip_when and validations
+			"b.yaml": {Filename: "b.yaml", Content: "b", Enabled: "{{ true }}\n"},
+		},
+	}
+
+	result, err := tmpl.Render(&Data{Questions: map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Filename != "a.yaml" {
+		t.Errorf("Expected only a.yaml to be enabled, got %v", result.Files)
+	}
+}
+
+func TestRenderDirectoryFormatTemplate(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "out",
+		Formats:  []string{`{{ if eq .Questions.target "api" }}json{{ else }}yaml{{ end }}`},
+		Files: map[string]*FileTemplate{
+			"config.yaml": {Filename: "config.yaml", Content: "key: value"},
+		},
+	}
+
+	testCases := []struct {
+		target   string
+		filename string
+		content  string
+	}{
+		{"api", "config.json", "{\n  \"key\": \"value\"\n}\n"},
+		{"k8s", "config.yaml", "key: value"},
+	}
+
+	for _, tc := range testCases {
+		result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"target": tc.target}})
+		if err != nil {
+			t.Fatalf("Failed to render template for %s: %v", tc.target, err)
+		}
+		if len(result.Files) != 1 {
+			t.Fatalf("Expected 1 file for %s, got %d", tc.target, len(result.Files))
+		}
+		file := result.Files[0]
+		if file.Filename != tc.filename || file.Content != tc.content {
+			t.Errorf("Expected %s with %q for %s, got %s with %q", tc.filename, tc.content, tc.target, file.Filename, file.Content)
+		}
+	}
+
+	// A format that renders nothing is reported
+	tmpl.Formats = []string{`{{ if eq .Questions.target "api" }}json{{ end }}`}
+	_, err := tmpl.Render(&Data{Questions: map[string]interface{}{"target": "k8s"}})
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) || renderErr.Phase != RenderPhaseFormat {
+		t.Errorf("Expected a format render error, got %v", err)
+	}
+}
@@ -1,10 +1,13 @@
 package template
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadTemplate(t *testing.T) {
@@ -99,6 +102,89 @@ content without separator`
 	}
 }
 
+func TestLoadTemplateCRLF(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "windows.yaml")
+	templateContent := "path: {{.Questions.env}}\r\nfilename: app.yaml\r\n---\r\nname: web\r\nenv: {{.Questions.env}}\r\n"
+	if err := os.WriteFile(templatePath, []byte(templateContent), 0600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	tmpl, err := LoadFileTemplateAt(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to load template: %v", err)
+	}
+	if tmpl.Path != "{{.Questions.env}}" || tmpl.Filename != "app.yaml" {
+		t.Errorf("Expected clean metadata, got path %q and filename %q", tmpl.Path, tmpl.Filename)
+	}
+
+	result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"env": "dev"}})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+	file := result.Files[0]
+	if file.Path != "dev" || file.Filename != "app.yaml" {
+		t.Errorf("Expected dev/app.yaml, got %s/%s", file.Path, file.Filename)
+	}
+	if file.Content != "name: web\nenv: dev" {
+		t.Errorf("Expected LF content, got %q", file.Content)
+	}
+}
+
+func TestLoadTemplateFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	testCases := map[string]struct {
+		content  string
+		path     string
+		filename string
+		body     string
+	}{
+		"front matter": {
+			content:  "---\npath: {{.Questions.env}}\nfilename: app.yaml\n---\nname: web\n",
+			path:     "{{.Questions.env}}",
+			filename: "app.yaml",
+			body:     "name: web",
+		},
+		"front matter with a document separator in the body": {
+			content:  "---\npath: out\nfilename: app.yaml\n---\nkind: Service\n---\nkind: Deployment\n",
+			path:     "out",
+			filename: "app.yaml",
+			body:     "kind: Service\n---\nkind: Deployment",
+		},
+		"leading separator without metadata": {
+			content: "---\nkind: Service\n",
+			body:    "kind: Service",
+		},
+		"multi-document body without metadata": {
+			content: "---\nkind: Service\nmetadata:\n  name: web\n---\nkind: Deployment\n",
+			body:    "kind: Service\nmetadata:\n  name: web\n---\nkind: Deployment",
+		},
+		"multi-document body of flat documents": {
+			content: "---\nkind: ConfigMap\n---\nkind: Secret\n",
+			body:    "kind: ConfigMap\n---\nkind: Secret",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			templatePath := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".yaml")
+			if err := os.WriteFile(templatePath, []byte(tc.content), 0600); err != nil {
+				t.Fatalf("Failed to write template: %v", err)
+			}
+
+			tmpl, err := LoadFileTemplateAt(templatePath)
+			if err != nil {
+				t.Fatalf("Failed to load template: %v", err)
+			}
+			if tmpl.Path != tc.path || tmpl.Filename != tc.filename {
+				t.Errorf("Expected path %q and filename %q, got %q and %q", tc.path, tc.filename, tmpl.Path, tmpl.Filename)
+			}
+			if tmpl.Content != tc.body {
+				t.Errorf("Expected content %q, got %q", tc.body, tmpl.Content)
+			}
+		})
+	}
+}
+
 func TestTemplateRender(t *testing.T) {
 	tmpl := &Template{
 		Type:     TypeFile,
@@ -170,6 +256,121 @@ func TestTemplateRenderInvalidTemplate(t *testing.T) {
 	}
 }
 
+func TestRenderErrorContext(t *testing.T) {
+	tests := []struct {
+		name  string
+		tmpl  *Template
+		file  string
+		phase string
+	}{
+		{
+			name:  "file path",
+			tmpl:  &Template{Type: TypeFile, Source: "app.yaml", Path: "{{.Questions.env", Filename: "app.yaml", Content: "app"},
+			file:  "app.yaml",
+			phase: RenderPhasePath,
+		},
+		{
+			name:  "file filename",
+			tmpl:  &Template{Type: TypeFile, Source: "app.yaml", Path: "out", Filename: "{{end}}", Content: "app"},
+			file:  "app.yaml",
+			phase: RenderPhaseFilename,
+		},
+		{
+			name:  "file content",
+			tmpl:  &Template{Type: TypeFile, Source: "app.yaml", Path: "out", Filename: "app.yaml", Content: "{{.Questions.app"},
+			file:  "app.yaml",
+			phase: RenderPhaseContent,
+		},
+		{
+			name:  "directory base path",
+			tmpl:  &Template{Type: TypeDirectory, BasePath: "{{end}}", Files: map[string]*FileTemplate{"a.yaml": {Filename: "a.yaml", Content: "a"}}},
+			phase: RenderPhasePath,
+		},
+		{
+			name:  "directory filename",
+			tmpl:  &Template{Type: TypeDirectory, BasePath: "out", Files: map[string]*FileTemplate{"a.yaml": {Filename: "{{end}}", Content: "a"}}},
+			file:  "a.yaml",
+			phase: RenderPhaseFilename,
+		},
+		{
+			name:  "directory content",
+			tmpl:  &Template{Type: TypeDirectory, BasePath: "out", Files: map[string]*FileTemplate{"a.yaml": {Filename: "a.yaml", Content: "{{end}}"}}},
+			file:  "a.yaml",
+			phase: RenderPhaseContent,
+		},
+		{
+			name:  "directory enabled",
+			tmpl:  &Template{Type: TypeDirectory, BasePath: "out", Files: map[string]*FileTemplate{"a.yaml": {Filename: "a.yaml", Content: "a", Enabled: "{{end}}"}}},
+			file:  "a.yaml",
+			phase: RenderPhaseEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.tmpl.Render(&Data{Questions: map[string]interface{}{"app": "web"}})
+
+			var renderErr *RenderError
+			if !errors.As(err, &renderErr) {
+				t.Fatalf("Expected a *RenderError, got %v", err)
+			}
+			if renderErr.File != tt.file || renderErr.Phase != tt.phase {
+				t.Errorf("Expected file %q and phase %q, got %q and %q", tt.file, tt.phase, renderErr.File, renderErr.Phase)
+			}
+			if renderErr.Err == nil || !strings.Contains(err.Error(), tt.phase) {
+				t.Errorf("Expected the error to wrap the cause and name the phase, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderTrimBlocks(t *testing.T) {
+	content := `metadata:
+  name: {{ .Questions.app }}
+  {{ if .Questions.tls }}
+  tls: true
+  {{ end }}
+  labels:
+    {{- /* one label per env */}}
+    {{ range .Questions.envs }}
+    env-{{ . }}: "true"
+    {{ end }}
+spec: {}`
+	data := &Data{Questions: map[string]interface{}{"app": "web", "tls": false, "envs": []string{"dev", "prod"}}}
+
+	tmpl := &Template{Type: TypeFile, Path: ".", Filename: "app.yaml", Content: content, TrimBlocks: true}
+	result, err := tmpl.Render(data)
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+	expected := `metadata:
+  name: web
+  labels:
+    env-dev: "true"
+    env-prod: "true"
+spec: {}`
+	if result.Files[0].Content != expected {
+		t.Errorf("Expected block lines to be removed:\n%s\ngot:\n%s", expected, result.Files[0].Content)
+	}
+
+	// Without the option, the false conditional leaves a blank line
+	tmpl.TrimBlocks = false
+	result, err = tmpl.Render(data)
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+	if !strings.Contains(result.Files[0].Content, "name: web\n  \n") {
+		t.Errorf("Expected a blank line without trim_blocks, got:\n%s", result.Files[0].Content)
+	}
+
+	// Errors keep pointing to the authored line
+	broken := &Template{Type: TypeFile, Source: "app.yaml", Path: ".", Filename: "app.yaml", TrimBlocks: true,
+		Content: "a: 1\n{{ if true }}\nb: {{ .Questions.app | nosuchfunc }}\n{{ end }}"}
+	if _, err := broken.Render(data); err == nil || !strings.Contains(err.Error(), "app.yaml:3:") {
+		t.Errorf("Expected the error on line 3, got %v", err)
+	}
+}
+
 func TestLoadDirectoryTemplate(t *testing.T) {
 	// Create test directory
 	testDir := t.TempDir()
@@ -226,7 +427,7 @@ metadata:
 	}
 
 	// Test loading directory template
-	tmpl, err := loadDirectoryTemplate("microservice")
+	tmpl, err := loadDirectoryTemplate("", "microservice", "")
 	if err != nil {
 		t.Fatalf("Failed to load directory template: %v", err)
 	}
@@ -315,3 +516,457 @@ func TestRenderDirectory(t *testing.T) {
 		}
 	})
 }
+
+func TestRenderYAMLAnchors(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeDirectory,
+		BasePath: "{{.Questions.env}}",
+		Files: map[string]*FileTemplate{
+			"config.yaml": {
+				Filename: "config.yaml",
+				Content: `defaults: {{ anchor "defaults" }}
+  replicas: 3
+  env: {{.Questions.env}}
+authored: &authored
+  image: nginx
+web:
+  <<: {{ ref "defaults" }}
+  name: web
+worker:
+  <<: *authored
+  name: worker`,
+			},
+		},
+	}
+
+	data := &Data{
+		Questions: map[string]interface{}{
+			"env": "dev",
+		},
+	}
+
+	result, err := tmpl.Render(data)
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	content := result.Files[0].Content
+	for _, expected := range []string{"defaults: &defaults", "<<: *defaults", "authored: &authored", "<<: *authored"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Rendered content should contain %q, got:\n%s", expected, content)
+		}
+	}
+
+	// Anchors must survive as valid YAML and resolve through merge keys
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
+		t.Fatalf("Rendered content should be valid YAML: %v", err)
+	}
+	if anchor := node.Content[0].Content[1].Anchor; anchor != "defaults" {
+		t.Errorf("Expected anchor 'defaults' to be preserved, got %q", anchor)
+	}
+
+	var parsed map[string]map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &parsed); err != nil {
+		t.Fatalf("Failed to decode rendered YAML: %v", err)
+	}
+	if parsed["web"]["replicas"] != 3 || parsed["web"]["env"] != "dev" {
+		t.Errorf("Expected merge key to resolve defaults, got %v", parsed["web"])
+	}
+	if parsed["worker"]["image"] != "nginx" {
+		t.Errorf("Expected merge key to resolve authored anchor, got %v", parsed["worker"])
+	}
+}
+
+func TestLoadTemplateInlineContent(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".yg")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	configContent := `templates:
+  namespace:
+    path: "{{.Questions.env}}/namespaces"
+    filename: "{{.Questions.appName}}.yaml"
+    content: |
+      apiVersion: v1
+      kind: Namespace
+      metadata:
+        name: {{.Questions.appName}}`
+
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	// No template file exists under _templates; the template comes from config
+	tmpl, err := LoadTemplate("namespace")
+	if err != nil {
+		t.Fatalf("Failed to load inline template: %v", err)
+	}
+
+	result, err := tmpl.Render(&Data{
+		Questions: map[string]interface{}{
+			"appName": "my-app",
+			"env":     "dev",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to render inline template: %v", err)
+	}
+
+	if len(result.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(result.Files))
+	}
+
+	file := result.Files[0]
+	if file.Path != "dev/namespaces" || file.Filename != "my-app.yaml" {
+		t.Errorf("Expected dev/namespaces/my-app.yaml, got %s/%s", file.Path, file.Filename)
+	}
+
+	if !strings.Contains(file.Content, "kind: Namespace") || !strings.Contains(file.Content, "name: my-app") {
+		t.Errorf("Unexpected rendered content:\n%s", file.Content)
+	}
+}
+
+func TestLoadTemplateOutputOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	templateDir := filepath.Join(tempDir, ".yg", "_templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatalf("Failed to create template directory: %v", err)
+	}
+
+	configContent := `templates:
+  deployment:
+    type: file
+    path: shared/deployment.yaml
+  flat-deployment:
+    type: file
+    path: shared/deployment.yaml
+    output_path: "manifests"
+    filename: "{{.Questions.env}}-{{.Questions.appName}}.yaml"`
+
+	templateContent := `path: {{.Questions.env}}/{{.Questions.appName}}
+filename: deployment.yaml
+---
+name: {{.Questions.appName}}`
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".yg", "config.yaml"), []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(templateDir, "shared"), 0755); err != nil {
+		t.Fatalf("Failed to create shared template directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "shared", "deployment.yaml"), []byte(templateContent), 0600); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	_ = os.Chdir(tempDir)
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "deployment", expected: "dev/my-app/deployment.yaml"},
+		{name: "flat-deployment", expected: "manifests/dev-my-app.yaml"},
+	}
+
+	for _, tc := range testCases {
+		tmpl, err := LoadTemplate(tc.name)
+		if err != nil {
+			t.Fatalf("Failed to load template %s: %v", tc.name, err)
+		}
+
+		result, err := tmpl.Render(&Data{
+			Questions: map[string]interface{}{"appName": "my-app", "env": "dev"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to render template %s: %v", tc.name, err)
+		}
+
+		file := result.Files[0]
+		if path := filepath.Join(file.Path, file.Filename); path != tc.expected {
+			t.Errorf("Expected %s to be written to %s, got %s", tc.name, tc.expected, path)
+		}
+		if file.Content != "name: my-app" {
+			t.Errorf("Expected shared body for %s, got %q", tc.name, file.Content)
+		}
+	}
+}
+
+func TestLoadFileTemplateIgnoresTemplatesConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	templateDir := filepath.Join(tempDir, ".yg", "_templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatalf("Failed to create template directory: %v", err)
+	}
+
+	// The templates entry for deployment is broken and would fail LoadTemplateFrom
+	configContent := `templates:
+  deployment:
+    type: folder
+    path: missing`
+	templateContent := `path: {{.Questions.env}}
+filename: deployment.yaml
+---
+env: {{.Questions.env}}`
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".yg", "config.yaml"), []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "deployment.yaml"), []byte(templateContent), 0600); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+
+	if _, err := LoadTemplateFrom(tempDir, "deployment"); err == nil {
+		t.Fatal("Expected the broken templates entry to fail LoadTemplateFrom")
+	}
+
+	tmpl, err := LoadFileTemplateFrom(tempDir, "deployment")
+	if err != nil {
+		t.Fatalf("Failed to load file template: %v", err)
+	}
+	if tmpl.Type != TypeFile || tmpl.Path != "{{.Questions.env}}" || tmpl.Content != "env: {{.Questions.env}}" {
+		t.Errorf("Unexpected template: %+v", tmpl)
+	}
+}
+
+func TestLoadTemplateVariant(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		".yg/config.yaml": `templates:
+  microservice:
+    type: directory
+    path: microservice
+  worker:
+    type: directory
+    path: worker`,
+		".yg/_templates/deployment.yaml":                         "path: .\nfilename: deployment.yaml\n---\nreplicas: 1",
+		".yg/_templates/deployment.prod.yaml":                    "path: .\nfilename: deployment.yaml\n---\nreplicas: 3",
+		".yg/_templates/microservice/.template-config.yaml":      "files:\n  app.yaml:\n    filename: app.yaml\noutput:\n  base_path: .",
+		".yg/_templates/microservice/app.yaml":                   "replicas: 1",
+		".yg/_templates/microservice.prod/.template-config.yaml": "files:\n  app.yaml:\n    filename: app.yaml\noutput:\n  base_path: .",
+		".yg/_templates/microservice.prod/app.yaml":              "replicas: 3",
+		".yg/_templates/worker/.template-config.yaml":            "files:\n  app.yaml:\n    filename: app.yaml\noutput:\n  base_path: .",
+		".yg/_templates/worker/app.yaml":                         "replicas: 1",
+		".yg/_templates/worker/app.prod.yaml":                    "replicas: 3",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	testCases := []struct {
+		templateType string
+		variant      string
+		expected     string
+	}{
+		{"deployment", "prod", "replicas: 3"},
+		{"deployment", "dev", "replicas: 1"},
+		{"deployment", "", "replicas: 1"},
+		{"microservice", "prod", "replicas: 3"},
+		{"microservice", "dev", "replicas: 1"},
+		{"worker", "prod", "replicas: 3"},
+		{"worker", "dev", "replicas: 1"},
+	}
+
+	for _, tc := range testCases {
+		tmpl, err := LoadTemplateVariantFrom(tempDir, tc.templateType, tc.variant)
+		if err != nil {
+			t.Fatalf("Failed to load %s for %q: %v", tc.templateType, tc.variant, err)
+		}
+		result, err := tmpl.Render(&Data{Questions: map[string]interface{}{}})
+		if err != nil {
+			t.Fatalf("Failed to render %s for %q: %v", tc.templateType, tc.variant, err)
+		}
+		if content := result.Files[0].Content; content != tc.expected {
+			t.Errorf("Expected %s for %q to render %q, got %q", tc.templateType, tc.variant, tc.expected, content)
+		}
+	}
+}
+
+func TestQuestionReferences(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeFile,
+		Path:     "{{.Questions.env}}/{{ (questions).cluster }}",
+		Filename: `{{ index .Questions "app-name" }}.yaml`,
+		Content:  "name: {{.Questions.appName}}\nowner: {{ .Owner }}",
+	}
+
+	references := tmpl.QuestionReferences()
+	for _, key := range []string{"env", "cluster", "app-name", "appName"} {
+		if !references[key] {
+			t.Errorf("Expected %s to be referenced, got %v", key, references)
+		}
+	}
+
+	if len(references) != 4 {
+		t.Errorf("Expected 4 references, got %v", references)
+	}
+}
+
+func TestRenderEncodingFunctions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		value    interface{}
+		expected string
+	}{
+		{"b64enc string", "{{ .Questions.value | b64enc }}", "s3cr3t", "czNjcjN0"},
+		{"b64enc bytes", "{{ .Questions.value | b64enc }}", []byte("s3cr3t"), "czNjcjN0"},
+		{"b64enc number", "{{ .Questions.value | b64enc }}", 3, "Mw=="},
+		{
+			"sha256sum string", "{{ .Questions.value | sha256sum }}", "hello",
+			"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		{
+			"sha256sum bytes", "{{ sha256sum .Questions.value }}", []byte("hello"),
+			"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := &Template{Type: TypeFile, Path: "out", Filename: "secret.yaml", Content: tc.content}
+
+			result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"value": tc.value}})
+			if err != nil {
+				t.Fatalf("Failed to render template: %v", err)
+			}
+
+			if content := result.Files[0].Content; content != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestRenderYAMLBlock(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+	}{
+		{"trailing newline", "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"},
+		{"no trailing newline", "line one\nline two"},
+		{"trailing blank lines", "line one\n\n"},
+		{"blank line inside", "first\n\nsecond\n"},
+		{"leading spaces", "  indented\nflush\n"},
+		{"single line", "value: with colon"},
+	}
+
+	content := `spec:
+  cert: {{ .Questions.value | yamlBlock 4 }}
+  next: {{ .Questions.value | quote }}`
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := &Template{Type: TypeFile, Path: "out", Filename: "cert.yaml", Content: content}
+
+			result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"value": tc.value}})
+			if err != nil {
+				t.Fatalf("Failed to render template: %v", err)
+			}
+
+			var parsed struct {
+				Spec struct {
+					Cert string `yaml:"cert"`
+					Next string `yaml:"next"`
+				} `yaml:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(result.Files[0].Content), &parsed); err != nil {
+				t.Fatalf("Rendered content is not valid YAML: %v\n%s", err, result.Files[0].Content)
+			}
+			if parsed.Spec.Cert != tc.value {
+				t.Errorf("Expected block scalar %q, got %q in:\n%s", tc.value, parsed.Spec.Cert, result.Files[0].Content)
+			}
+			if parsed.Spec.Next != tc.value {
+				t.Errorf("Expected quoted value %q, got %q", tc.value, parsed.Spec.Next)
+			}
+		})
+	}
+}
+
+func TestRenderIndent(t *testing.T) {
+	tmpl := &Template{
+		Type:     TypeFile,
+		Path:     "out",
+		Filename: "config.yaml",
+		Content:  "data:{{ .Questions.value | nindent 2 }}\nother:\n{{ .Questions.value | indent 2 }}",
+	}
+
+	result, err := tmpl.Render(&Data{Questions: map[string]interface{}{"value": "a: 1\n\nb: 2"}})
+	if err != nil {
+		t.Fatalf("Failed to render template: %v", err)
+	}
+
+	expected := "data:\n  a: 1\n\n  b: 2\nother:\n  a: 1\n\n  b: 2"
+	if content := result.Files[0].Content; content != expected {
+		t.Errorf("Expected %q, got %q", expected, content)
+	}
+}
+
+func TestMatchOutputPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pattern  string
+		path     string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "segments",
+			pattern:  "{{.Questions.env}}/{{ .Questions.cluster }}/deployment",
+			path:     "out/dev/dev-cluster-1/deployment",
+			expected: map[string]string{"env": "dev", "cluster": "dev-cluster-1"},
+		},
+		{
+			name:     "literal around answers",
+			pattern:  `{{.Questions.env}}/{{index .Questions "app-name"}}-deployment.yaml`,
+			path:     "dev/my-app-deployment.yaml",
+			expected: map[string]string{"env": "dev", "app-name": "my-app"},
+		},
+		{
+			name:     "other actions match anything",
+			pattern:  "{{ upper .Questions.env }}/{{.Questions.cluster}}",
+			path:     "DEV/dev-1",
+			expected: map[string]string{"cluster": "dev-1"},
+		},
+		{name: "literal mismatch", pattern: "{{.Questions.env}}/job", path: "dev/deployment", wantErr: true},
+		{name: "too short", pattern: "{{.Questions.env}}/{{.Questions.cluster}}", path: "dev", wantErr: true},
+		{name: "conflicting values", pattern: "{{.Questions.env}}/{{.Questions.env}}", path: "dev/prod", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			answers, err := MatchOutputPath(tc.pattern, tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got answers %v", answers)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to match: %v", err)
+			}
+			if len(answers) != len(tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, answers)
+			}
+			for key, value := range tc.expected {
+				if answers[key] != value {
+					t.Errorf("Expected %s=%s, got %v", key, value, answers)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package template
+
+import (
+	"regexp"
+	"strings"
+)
+
+// blockLine matches a line holding nothing but control-flow actions and comments, such
+// as "  {{ if .Questions.tls }}" or "{{- end }}".
+var blockLine = regexp.MustCompile(
+	`^[ \t]*(?:\{\{-?\s*(?:(?:if|else|end|range|with|define|block|break|continue)\b|/\*)(?:[^}]|\}[^}])*\}\}[ \t]*)+$`,
+)
+
+// contentSource returns the source of content to render, with block lines trimmed when
+// TrimBlocks is set.
+func (t *Template) contentSource(content string) string {
+	if !t.TrimBlocks {
+		return content
+	}
+	return trimBlockLines(content)
+}
+
+// trimBlockLines makes every block line render to nothing, instead of leaving an empty
+// line behind: its indentation is removed and its line break is moved into a comment
+// that ends on the next line, so that template errors keep their line numbers. A
+// leading "{{-" is dropped, as the line it would trim into is already removed.
+func trimBlockLines(content string) string {
+	original := strings.Split(content, "\n")
+	lines := make([]string, len(original))
+	copy(lines, original)
+
+	for i, line := range original {
+		if !blockLine.MatchString(line) {
+			continue
+		}
+		prefix := strings.TrimSuffix(lines[i], line)
+		trimmed := strings.TrimSpace(line)
+		if rest, found := strings.CutPrefix(trimmed, "{{- "); found {
+			// Comments must start right after the delimiter
+			if strings.HasPrefix(rest, "/*") {
+				trimmed = "{{" + rest
+			} else {
+				trimmed = "{{ " + rest
+			}
+		}
+		lines[i] = prefix + trimmed
+		if i < len(lines)-1 {
+			lines[i] += "{{/*"
+			lines[i+1] = "*/}}" + lines[i+1]
+		}
+	}
+	return strings.Join(lines, "\n")
+}